@@ -0,0 +1,103 @@
+package charts
+
+import (
+	"context"
+	"goozinshe/config"
+	"goozinshe/jobs"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"sort"
+	"time"
+)
+
+const (
+	defaultInterval = 15 * time.Minute
+	defaultWindow   = 7 * 24 * time.Hour
+)
+
+// defaultTrendingWeights apply to the weights missing from config.
+var defaultTrendingWeights = models.TrendingWeights{Watchlist: 2, Rating: 3, View: 1}
+
+// Materializer periodically recomputes the trending and top-rated charts so
+// that requests only read precomputed rows.
+type Materializer struct {
+	repo *repositories.ChartsRepository
+}
+
+func NewMaterializer(repo *repositories.ChartsRepository) *Materializer {
+	return &Materializer{repo: repo}
+}
+
+// Refresh recomputes all charts using the window and weights from config.
+func (m *Materializer) Refresh(c context.Context) error {
+	window := config.Config.ChartsWindow
+	if window <= 0 {
+		window = defaultWindow
+	}
+	minRatings := config.Config.ChartsMinRatings
+	if minRatings < 1 {
+		minRatings = 1
+	}
+	weights := trendingWeights(models.TrendingWeights{
+		Watchlist: config.Config.ChartsWatchlistWeight,
+		Rating:    config.Config.ChartsRatingWeight,
+		View:      config.Config.ChartsViewWeight,
+	})
+
+	activity, err := m.repo.FindTrendingActivity(c, time.Now().Add(-window))
+	if err != nil {
+		return err
+	}
+
+	return m.repo.Refresh(c, rankTrending(activity, weights), minRatings)
+}
+
+// rankTrending scores the movies, best first and ties broken by movie id.
+// Movies without a positive score are left out.
+func rankTrending(activity []models.TrendingActivity, weights models.TrendingWeights) []models.TrendingScore {
+	ranked := make([]models.TrendingScore, 0, len(activity))
+	for _, a := range activity {
+		score := weights.Score(a)
+		if score > 0 {
+			ranked = append(ranked, models.TrendingScore{MovieId: a.MovieId, Score: score})
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].MovieId < ranked[j].MovieId
+	})
+	return ranked
+}
+
+// trendingWeights replaces the weights that are not positive, i.e. not set,
+// by their defaults, so that no signal silently stops counting.
+func trendingWeights(configured models.TrendingWeights) models.TrendingWeights {
+	weights := defaultTrendingWeights
+	if configured.Watchlist > 0 {
+		weights.Watchlist = configured.Watchlist
+	}
+	if configured.Rating > 0 {
+		weights.Rating = configured.Rating
+	}
+	if configured.View > 0 {
+		weights.View = configured.View
+	}
+	return weights
+}
+
+// RefreshJob runs Refresh in the background.
+var RefreshJob = jobs.Type[jobs.NoPayload]{Name: "charts.refresh", Queue: jobs.MaintenanceQueue}
+
+// Register makes the runner refresh the charts every CHARTS_INTERVAL.
+func (m *Materializer) Register(runner *jobs.Runner) {
+	interval := config.Config.ChartsInterval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	jobs.Handle(runner, RefreshJob, func(ctx context.Context, _ jobs.NoPayload) error {
+		return m.Refresh(ctx)
+	})
+	jobs.Recur(runner, RefreshJob, jobs.Every(interval), jobs.NoPayload{})
+}
@@ -0,0 +1,47 @@
+package charts
+
+import (
+	"goozinshe/models"
+	"reflect"
+	"testing"
+)
+
+func TestRankTrending(t *testing.T) {
+	weights := models.TrendingWeights{Watchlist: 2, Rating: 3, View: 1}
+	activity := []models.TrendingActivity{
+		{MovieId: 1, Views: 4},                               // 4
+		{MovieId: 2, WatchlistAdds: 1, Ratings: 1},           // 5
+		{MovieId: 3},                                         // 0
+		{MovieId: 4, WatchlistAdds: 2},                       // 4
+		{MovieId: 5, WatchlistAdds: 1, Ratings: 2, Views: 3}, // 11
+	}
+
+	got := rankTrending(activity, weights)
+	want := []models.TrendingScore{
+		{MovieId: 5, Score: 11},
+		{MovieId: 2, Score: 5},
+		{MovieId: 1, Score: 4},
+		{MovieId: 4, Score: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rankTrending() = %v, want %v", got, want)
+	}
+}
+
+func TestTrendingWeights(t *testing.T) {
+	tests := []struct {
+		configured models.TrendingWeights
+		want       models.TrendingWeights
+	}{
+		{models.TrendingWeights{}, defaultTrendingWeights},
+		{models.TrendingWeights{Watchlist: 5, Rating: 0.5, View: 2}, models.TrendingWeights{Watchlist: 5, Rating: 0.5, View: 2}},
+		{models.TrendingWeights{Rating: 10}, models.TrendingWeights{Watchlist: defaultTrendingWeights.Watchlist, Rating: 10, View: defaultTrendingWeights.View}},
+		{models.TrendingWeights{Watchlist: -1, View: -1}, models.TrendingWeights{Watchlist: defaultTrendingWeights.Watchlist, Rating: defaultTrendingWeights.Rating, View: defaultTrendingWeights.View}},
+	}
+	for _, tt := range tests {
+		got := trendingWeights(tt.configured)
+		if got != tt.want {
+			t.Errorf("trendingWeights(%+v) = %+v, want %+v", tt.configured, got, tt.want)
+		}
+	}
+}
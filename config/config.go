@@ -5,8 +5,46 @@ import "time"
 var Config *MapConfig
 
 type MapConfig struct {
-	AppHost            string        `mapstructure:"APP_HOST"`
-	DbConnectionString string        `mapstructure:"DB_CONNECTION_STRING"`
-	JwtSecretKey       string        `mapstructure:"JWT_SECRET"`
-	JwtExpiresIn       time.Duration `mapstructure:"JWT_EXPIRES_IN"`
+	AppHost                 string        `mapstructure:"APP_HOST"`
+	DbConnectionString      string        `mapstructure:"DB_CONNECTION_STRING"`
+	JwtSecretKey            string        `mapstructure:"JWT_SECRET"`
+	JwtExpiresIn            time.Duration `mapstructure:"JWT_EXPIRES_IN"`
+	VideosDir               string        `mapstructure:"VIDEOS_DIR"`
+	FfmpegPath              string        `mapstructure:"FFMPEG_PATH"`
+	FfprobePath             string        `mapstructure:"FFPROBE_PATH"`
+	WatchedThreshold        float64       `mapstructure:"WATCHED_THRESHOLD"`
+	RecommendationsInterval time.Duration `mapstructure:"RECOMMENDATIONS_INTERVAL"`
+	ChartsInterval          time.Duration `mapstructure:"CHARTS_INTERVAL"`
+	ChartsWindow            time.Duration `mapstructure:"CHARTS_WINDOW"`
+	ChartsWatchlistWeight   float64       `mapstructure:"CHARTS_WATCHLIST_WEIGHT"`
+	ChartsRatingWeight      float64       `mapstructure:"CHARTS_RATING_WEIGHT"`
+	ChartsViewWeight        float64       `mapstructure:"CHARTS_VIEW_WEIGHT"`
+	ChartsMinRatings        int           `mapstructure:"CHARTS_MIN_RATINGS"`
+	PublicUrl               string        `mapstructure:"PUBLIC_URL"`
+	EventsHistorySize       int           `mapstructure:"EVENTS_HISTORY_SIZE"`
+	EventsHeartbeatInterval time.Duration `mapstructure:"EVENTS_HEARTBEAT_INTERVAL"`
+	WatchPartyIdleTimeout   time.Duration `mapstructure:"WATCH_PARTY_IDLE_TIMEOUT"`
+	WebhookMaxAttempts      int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookPollInterval     time.Duration `mapstructure:"WEBHOOK_POLL_INTERVAL"`
+	WebhookTimeout          time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	OutboxPollInterval      time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"`
+	OutboxRetention         time.Duration `mapstructure:"OUTBOX_RETENTION"`
+	OutboxMaxAttempts       int           `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	JobsConcurrency         string        `mapstructure:"JOBS_CONCURRENCY"`
+	JobsPollInterval        time.Duration `mapstructure:"JOBS_POLL_INTERVAL"`
+	JobsLease               time.Duration `mapstructure:"JOBS_LEASE"`
+	JobsRetention           time.Duration `mapstructure:"JOBS_RETENTION"`
+	JobsCleanupSchedule     string        `mapstructure:"JOBS_CLEANUP_SCHEDULE"`
+	EmailTransport          string        `mapstructure:"EMAIL_TRANSPORT"`
+	EmailDropDir            string        `mapstructure:"EMAIL_DROP_DIR"`
+	EmailFrom               string        `mapstructure:"EMAIL_FROM"`
+	SmtpHost                string        `mapstructure:"SMTP_HOST"`
+	SmtpPort                int           `mapstructure:"SMTP_PORT"`
+	SmtpUsername            string        `mapstructure:"SMTP_USERNAME"`
+	SmtpPassword            string        `mapstructure:"SMTP_PASSWORD"`
+	EmailDigestSchedule     string        `mapstructure:"EMAIL_DIGEST_SCHEDULE"`
+	EmailNewReleaseDelay    time.Duration `mapstructure:"EMAIL_NEW_RELEASE_DELAY"`
+	EmailUnsubscribeSecret  string        `mapstructure:"EMAIL_UNSUBSCRIBE_SECRET"`
+	EmailLinkSecret         string        `mapstructure:"EMAIL_LINK_SECRET"`
+	NotificationsTtl        time.Duration `mapstructure:"NOTIFICATIONS_TTL"`
 }
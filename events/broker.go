@@ -0,0 +1,147 @@
+package events
+
+import (
+	"goozinshe/models"
+	"sync"
+	"time"
+)
+
+const (
+	MovieCreated     = "movie.created"
+	MovieUpdated     = "movie.updated"
+	MovieDeleted     = "movie.deleted"
+	TrailerAdded     = "movie.trailer_added"
+	GenreCreated     = "genre.created"
+	GenreUpdated     = "genre.updated"
+	GenreDeleted     = "genre.deleted"
+	UserCreated      = "user.created"
+	UserUpdated      = "user.updated"
+	UserDeleted      = "user.deleted"
+	ReviewApproved   = "review.approved"
+	WatchlistChanged = "watchlist.changed"
+)
+
+const (
+	defaultHistorySize = 1000
+	subscriberBuffer   = 64
+)
+
+type Event struct {
+	Id   int64
+	Type string
+	Data any
+	// ProfileId limits the event to a single profile; zero means everyone.
+	ProfileId int
+	// AgeRating hides the event from profiles that may not see movies with
+	// this age rating.
+	AgeRating int
+}
+
+func (e Event) visibleTo(viewer models.Viewer) bool {
+	if e.ProfileId != 0 && e.ProfileId != viewer.ProfileId {
+		return false
+	}
+	return e.AgeRating <= viewer.MaxAgeRating
+}
+
+type Subscription struct {
+	// Events is closed when the subscriber falls too far behind; the client
+	// is expected to reconnect and resume from the last event it got.
+	Events <-chan Event
+	events chan Event
+	viewer models.Viewer
+}
+
+// Broker is an in-process pub/sub for events pushed to connected clients. It
+// keeps the most recent events so that a reconnecting client can resume
+// from the id of the last event it received.
+type Broker struct {
+	mu          sync.Mutex
+	nextId      int64
+	history     []Event
+	historySize int
+	subscribers map[*Subscription]struct{}
+}
+
+func NewBroker(historySize int) *Broker {
+	if historySize <= 0 {
+		historySize = defaultHistorySize
+	}
+	return &Broker{
+		// Ids start from the current time so that they keep growing across
+		// restarts and an id from a previous run is never mistaken for a
+		// recent one.
+		nextId:      time.Now().UnixMicro(),
+		historySize: historySize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns the event an id and delivers it to every subscriber that
+// may see it. Publish never blocks: a subscriber whose buffer is full is
+// disconnected.
+func (b *Broker) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event.Id = b.nextId
+	b.nextId++
+	b.history = append(b.history, event)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for sub := range b.subscribers {
+		if !event.visibleTo(sub.viewer) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for the viewer. With a non-zero
+// lastEventId it also returns the retained events published after it; ok is
+// false when some of those events are no longer retained and the client has
+// to reload its state instead.
+func (b *Broker) Subscribe(viewer models.Viewer, lastEventId int64) (sub *Subscription, missed []Event, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := make(chan Event, subscriberBuffer)
+	sub = &Subscription{Events: events, events: events, viewer: viewer}
+	b.subscribers[sub] = struct{}{}
+
+	missed = make([]Event, 0)
+	if lastEventId == 0 {
+		return sub, missed, true
+	}
+
+	firstId := b.nextId
+	if len(b.history) > 0 {
+		firstId = b.history[0].Id
+	}
+	if lastEventId < firstId-1 || lastEventId >= b.nextId {
+		return sub, missed, false
+	}
+	for _, event := range b.history {
+		if event.Id > lastEventId && event.visibleTo(viewer) {
+			missed = append(missed, event)
+		}
+	}
+	return sub, missed, true
+}
+
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
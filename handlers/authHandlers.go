@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"goozinshe/config"
 	"goozinshe/logger"
 	"goozinshe/models"
+	"goozinshe/notifier"
 	"goozinshe/repositories"
 	"net/http"
 	"strconv"
@@ -16,10 +19,29 @@ import (
 
 type AuthHandlers struct {
 	userRepo *repositories.UsersRepository
+	notifier *notifier.Notifier
 }
 
-func NewAuthHandlers(userRepo *repositories.UsersRepository) *AuthHandlers {
-	return &AuthHandlers{userRepo: userRepo}
+func NewAuthHandlers(userRepo *repositories.UsersRepository, notifier *notifier.Notifier) *AuthHandlers {
+	return &AuthHandlers{userRepo: userRepo, notifier: notifier}
+}
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	ProfileId int `json:"profileId,omitempty"`
+}
+
+// issueToken signs a token for the user, scoped to the profile when profileId is not 0.
+func issueToken(userId int, profileId int) (string, error) {
+	claims := tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(userId),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.Config.JwtExpiresIn)),
+		},
+		ProfileId: profileId,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(config.Config.JwtSecretKey))
 }
 
 type signInRequest struct {
@@ -56,12 +78,7 @@ func (h *AuthHandlers) SignIn(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, models.NewApiError("Invalid credials"))
 		return
 	}
-	claims := jwt.RegisteredClaims{
-		Subject:   strconv.Itoa(user.Id),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.Config.JwtExpiresIn)),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(config.Config.JwtSecretKey))
+	tokenString, err := issueToken(user.Id, 0)
 	if err != nil {
 		logger.Error(err.Error())
 		c.JSON(http.StatusInternalServerError, models.NewApiError("Couldn't sign JWT"))
@@ -106,3 +123,163 @@ func (h *AuthHandlers) GetUserInfo(c *gin.Context) {
 		Name:  user.Name,
 	})
 }
+
+type passwordResetRequest struct {
+	Email string `json:"email"`
+}
+
+type newPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// HandleVerifyEmail godoc
+// @Summary      Verify email
+// @Description  Opened from the link of the verification email. The link expires after 72 hours and stops working when the user changes the address.
+// @Tags         authorization
+// @Accept       json
+// @Produce      json
+// @Param        token query string true "Token from the verification link"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid or expired link"
+// @Failure      500 {object} models.ApiError
+// @Router       /auth/verify-email [get]
+func (h *AuthHandlers) HandleVerifyEmail(c *gin.Context) {
+	user, ok := h.findLinkUser(c, models.EmailVerification)
+	if !ok {
+		return
+	}
+
+	verified, err := h.userRepo.MarkEmailVerified(c, user.Id, user.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !verified {
+		c.JSON(http.StatusBadRequest, models.NewApiError(notifier.ErrInvalidLinkToken.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleRequestPasswordReset godoc
+// @Summary      Request password reset
+// @Description  Emails the user a link to choose a new password, valid for an hour. Answers the same whether or not the address belongs to a user.
+// @Tags         authorization
+// @Accept       json
+// @Produce      json
+// @Param        request body passwordResetRequest true "Email"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      500 {object} models.ApiError
+// @Router       /auth/password-reset/request [post]
+func (h *AuthHandlers) HandleRequestPasswordReset(c *gin.Context) {
+	var request passwordResetRequest
+	if err := c.BindJSON(&request); err != nil || request.Email == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+
+	user, err := h.userRepo.FindByEmail(c, request.Email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.Status(http.StatusOK)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	err = h.notifier.EnqueuePasswordReset(c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleCheckPasswordReset godoc
+// @Summary      Check password reset link
+// @Description  Tells whether the link of the password reset email still works, so that the page can ask for a new password. Does not change anything.
+// @Tags         authorization
+// @Accept       json
+// @Produce      json
+// @Param        token query string true "Token from the password reset link"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid or expired link"
+// @Failure      500 {object} models.ApiError
+// @Router       /auth/password-reset [get]
+func (h *AuthHandlers) HandleCheckPasswordReset(c *gin.Context) {
+	if _, ok := h.findLinkUser(c, models.EmailPasswordReset); !ok {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleResetPassword godoc
+// @Summary      Reset password
+// @Description  Sets a new password with the link of the password reset email. The link works only once.
+// @Tags         authorization
+// @Accept       json
+// @Produce      json
+// @Param        token query string true "Token from the password reset link"
+// @Param        request body newPasswordRequest true "New password"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data or invalid or expired link"
+// @Failure      500 {object} models.ApiError
+// @Router       /auth/password-reset [post]
+func (h *AuthHandlers) HandleResetPassword(c *gin.Context) {
+	var request newPasswordRequest
+	if err := c.BindJSON(&request); err != nil || request.Password == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+
+	user, ok := h.findLinkUser(c, models.EmailPasswordReset)
+	if !ok {
+		return
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError("Failed to hash password"))
+		return
+	}
+	err = h.userRepo.ChangePassword(c, user.Id, string(passwordHash))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// findLinkUser loads the user the token query param was issued for and
+// verifies the token against it.
+func (h *AuthHandlers) findLinkUser(c *gin.Context, kind string) (models.User, bool) {
+	token := c.Query("token")
+	userId, err := notifier.LinkTokenUserId(token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
+		return models.User{}, false
+	}
+
+	user, err := h.userRepo.FindById(c, userId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, models.NewApiError(notifier.ErrInvalidLinkToken.Error()))
+		return models.User{}, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return models.User{}, false
+	}
+
+	err = notifier.VerifyLinkToken(config.Config.EmailLinkSecret, kind, token, user)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
+		return models.User{}, false
+	}
+	return user, true
+}
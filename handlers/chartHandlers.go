@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultChartLimit = 20
+
+type ChartsHandler struct {
+	chartsRepo *repositories.ChartsRepository
+	moviesRepo *repositories.MoviesRepository
+}
+
+func NewChartsHandler(
+	chartsRepo *repositories.ChartsRepository,
+	moviesRepo *repositories.MoviesRepository) *ChartsHandler {
+	return &ChartsHandler{
+		chartsRepo: chartsRepo,
+		moviesRepo: moviesRepo,
+	}
+}
+
+// HandleGetTrending godoc
+// @Summary      Get trending movies
+// @Description  Movies ranked by recent watchlist adds, ratings and views. The chart is recomputed periodically.
+// @Tags         charts
+// @Accept       json
+// @Produce      json
+// @Param        limit query int false "Maximum number of movies" default(20)
+// @Param        lang query string false "Language (kk, ru, en)"
+// @Success      200 {object} models.Chart "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /charts/trending [get]
+// @Security     Bearer
+func (h *ChartsHandler) HandleGetTrending(c *gin.Context) {
+	chart, err := h.chartsRepo.FindTrending(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	h.respond(c, chart)
+}
+
+// HandleGetTopRated godoc
+// @Summary      Get top rated movies
+// @Description  Movies with the highest average rating, overall, in a genre or in a decade. The chart is recomputed periodically.
+// @Tags         charts
+// @Accept       json
+// @Produce      json
+// @Param        genreId query int false "Genre id"
+// @Param        decade query int false "Decade, e.g. 1990"
+// @Param        limit query int false "Maximum number of movies" default(20)
+// @Param        lang query string false "Language (kk, ru, en)"
+// @Success      200 {object} models.Chart "OK"
+// @Failure      400 {object} models.ApiError "Invalid genre or decade"
+// @Failure      500 {object} models.ApiError
+// @Router       /charts/top-rated [get]
+// @Security     Bearer
+func (h *ChartsHandler) HandleGetTopRated(c *gin.Context) {
+	genreIdStr, decadeStr := c.Query("genreId"), c.Query("decade")
+	if genreIdStr != "" && decadeStr != "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Use either genreId or decade"))
+		return
+	}
+
+	scope, scopeId := models.ChartScopeAll, 0
+	if genreIdStr != "" {
+		genreId, err := strconv.Atoi(genreIdStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewApiError("Invalid genre id"))
+			return
+		}
+		scope, scopeId = models.ChartScopeGenre, genreId
+	}
+	if decadeStr != "" {
+		decade, err := strconv.Atoi(decadeStr)
+		if err != nil || decade%10 != 0 {
+			c.JSON(http.StatusBadRequest, models.NewApiError("Invalid decade"))
+			return
+		}
+		scope, scopeId = models.ChartScopeDecade, decade
+	}
+
+	chart, err := h.chartsRepo.FindTopRated(c, scope, scopeId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	h.respond(c, chart)
+}
+
+// respond loads the movies of the chart the viewer may see and renumbers
+// the remaining entries.
+func (h *ChartsHandler) respond(c *gin.Context, chart models.Chart) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultChartLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultChartLimit
+	}
+
+	ids := make([]int, 0, len(chart.Entries))
+	for _, entry := range chart.Entries {
+		ids = append(ids, entry.MovieId)
+	}
+	movies, err := h.moviesRepo.FindAllByIds(c, ids, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	byId := make(map[int]models.Movie, len(movies))
+	for _, movie := range movies {
+		byId[movie.Id] = movie
+	}
+
+	entries := make([]models.ChartEntry, 0, limit)
+	for _, entry := range chart.Entries {
+		if len(entries) == limit {
+			break
+		}
+		movie, ok := byId[entry.MovieId]
+		if !ok {
+			continue
+		}
+		entry.Movie = movie
+		entry.Position = len(entries) + 1
+		entries = append(entries, entry)
+	}
+	chart.Entries = entries
+
+	c.JSON(http.StatusOK, chart)
+}
@@ -0,0 +1,412 @@
+package handlers
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CollectionsHandler struct {
+	collectionsRepo *repositories.CollectionsRepository
+	moviesRepo      *repositories.MoviesRepository
+}
+
+func NewCollectionsHandler(
+	collectionsRepo *repositories.CollectionsRepository,
+	moviesRepo *repositories.MoviesRepository) *CollectionsHandler {
+	return &CollectionsHandler{
+		collectionsRepo: collectionsRepo,
+		moviesRepo:      moviesRepo,
+	}
+}
+
+type collectionRequest struct {
+	Title       string                `form:"title"`
+	Description string                `form:"description"`
+	Cover       *multipart.FileHeader `form:"cover"`
+}
+
+type collectionMoviesRequest struct {
+	MovieIds []int `json:"movieIds"`
+}
+
+// FindAll godoc
+// @Summary      Get all collections
+// @Tags         collections
+// @Accept       json
+// @Produce      json
+// @Success      200 {array} models.Collection "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /collections [get]
+// @Security     Bearer
+func (h *CollectionsHandler) FindAll(c *gin.Context) {
+	collections, err := h.collectionsRepo.FindAll(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, collections)
+}
+
+// FindById godoc
+// @Summary      Get collection with its movies in order
+// @Tags         collections
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Collection id"
+// @Success      200 {object} models.CollectionWithMovies "OK"
+// @Failure      400 {object} models.ApiError "Invalid collection id"
+// @Failure      404 {object} models.ApiError "Collection not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /collections/{id} [get]
+// @Security     Bearer
+func (h *CollectionsHandler) FindById(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Collection Id"))
+		return
+	}
+
+	collection, err := h.collectionsRepo.FindById(c, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Collection not found"))
+		return
+	}
+
+	movieIds, err := h.collectionsRepo.FindMovieIds(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	movies, err := h.moviesRepo.FindAllByIds(c, movieIds, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CollectionWithMovies{
+		Collection: collection,
+		Movies:     orderMoviesByIds(movies, movieIds),
+	})
+}
+
+// Create godoc
+// @Summary      Create collection
+// @Tags         collections
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        title formData string true "Title"
+// @Param        description formData string false "Description"
+// @Param        cover formData file false "Cover image"
+// @Success      200 {object} object{id=int} "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      500 {object} models.ApiError
+// @Router       /collections [post]
+// @Security     Bearer
+func (h *CollectionsHandler) Create(c *gin.Context) {
+	var request collectionRequest
+	err := c.Bind(&request)
+	if err != nil || request.Title == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Could not bind payload"))
+		return
+	}
+
+	collection := models.Collection{
+		Title:       request.Title,
+		Description: request.Description,
+	}
+	if request.Cover != nil {
+		collection.CoverUrl, err = h.saveCollectionCover(c, request.Cover)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+			return
+		}
+	}
+
+	id, err := h.collectionsRepo.Create(c, collection)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	logger := logger.GetLogger()
+	logger.Info("Collection has been created", zap.Int("collection_id", id))
+
+	c.JSON(http.StatusOK, gin.H{
+		"id": id,
+	})
+}
+
+// Update godoc
+// @Summary      Update collection
+// @Tags         collections
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id path int true "Collection id"
+// @Param        title formData string true "Title"
+// @Param        description formData string false "Description"
+// @Param        cover formData file false "Cover image, the current one is kept when omitted"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "Collection not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /collections/{id} [put]
+// @Security     Bearer
+func (h *CollectionsHandler) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Collection Id"))
+		return
+	}
+
+	collection, err := h.collectionsRepo.FindById(c, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Collection not found"))
+		return
+	}
+
+	var request collectionRequest
+	err = c.Bind(&request)
+	if err != nil || request.Title == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Could not bind payload"))
+		return
+	}
+
+	collection.Title = request.Title
+	collection.Description = request.Description
+	if request.Cover != nil {
+		collection.CoverUrl, err = h.saveCollectionCover(c, request.Cover)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+			return
+		}
+	}
+
+	err = h.collectionsRepo.Update(c, id, collection)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// Delete godoc
+// @Summary      Delete collection
+// @Tags         collections
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Collection id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid collection id"
+// @Failure      500 {object} models.ApiError
+// @Router       /collections/{id} [delete]
+// @Security     Bearer
+func (h *CollectionsHandler) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Collection Id"))
+		return
+	}
+
+	err = h.collectionsRepo.Delete(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleSetMovies godoc
+// @Summary      Replace collection movies
+// @Description  Sets the collection membership to the given movies in the given order.
+// @Tags         collections
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Collection id"
+// @Param        request body collectionMoviesRequest true "Ordered movie ids"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "Collection not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /collections/{id}/movies [put]
+// @Security     Bearer
+func (h *CollectionsHandler) HandleSetMovies(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Collection Id"))
+		return
+	}
+
+	_, err = h.collectionsRepo.FindById(c, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Collection not found"))
+		return
+	}
+
+	var request collectionMoviesRequest
+	err = c.BindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+
+	seen := make(map[int]bool)
+	for _, movieId := range request.MovieIds {
+		if seen[movieId] {
+			c.JSON(http.StatusBadRequest, models.NewApiError("Duplicate movie id"))
+			return
+		}
+		seen[movieId] = true
+	}
+
+	movies, err := h.moviesRepo.FindAllByIds(c, request.MovieIds, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if len(movies) != len(request.MovieIds) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Unknown movie id"))
+		return
+	}
+
+	err = h.collectionsRepo.SetMovies(c, id, request.MovieIds)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleAddMovie godoc
+// @Summary      Add movie to collection
+// @Tags         collections
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Collection id"
+// @Param        movieId path int true "Movie id"
+// @Param        position query int false "1-based position, appended when omitted"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "Collection or movie not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /collections/{id}/movies/{movieId} [post]
+// @Security     Bearer
+func (h *CollectionsHandler) HandleAddMovie(c *gin.Context) {
+	id, movieId, ok := h.parseMembershipIds(c)
+	if !ok {
+		return
+	}
+
+	_, err := h.moviesRepo.FindById(c, movieId, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie not found"))
+		return
+	}
+
+	position := 0
+	if positionStr := c.Query("position"); positionStr != "" {
+		position, err = strconv.Atoi(positionStr)
+		if err != nil || position < 1 {
+			c.JSON(http.StatusBadRequest, models.NewApiError("Invalid position"))
+			return
+		}
+	}
+
+	err = h.collectionsRepo.AddMovie(c, id, movieId, position)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleRemoveMovie godoc
+// @Summary      Remove movie from collection
+// @Tags         collections
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Collection id"
+// @Param        movieId path int true "Movie id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "Collection not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /collections/{id}/movies/{movieId} [delete]
+// @Security     Bearer
+func (h *CollectionsHandler) HandleRemoveMovie(c *gin.Context) {
+	id, movieId, ok := h.parseMembershipIds(c)
+	if !ok {
+		return
+	}
+
+	err := h.collectionsRepo.RemoveMovie(c, id, movieId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *CollectionsHandler) parseMembershipIds(c *gin.Context) (int, int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Collection Id"))
+		return 0, 0, false
+	}
+	movieId, err := strconv.Atoi(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+		return 0, 0, false
+	}
+
+	_, err = h.collectionsRepo.FindById(c, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Collection not found"))
+		return 0, 0, false
+	}
+	return id, movieId, true
+}
+
+func (h *CollectionsHandler) saveCollectionCover(c *gin.Context, cover *multipart.FileHeader) (string, error) {
+	filename := fmt.Sprintf("%s%s", uuid.NewString(), filepath.Ext(cover.Filename))
+	filepath := fmt.Sprintf("images/%s", filename)
+	err := c.SaveUploadedFile(cover, filepath)
+
+	return filename, err
+}
+
+// orderMoviesByIds arranges movies in the order of ids, skipping ids without a movie.
+func orderMoviesByIds(movies []models.Movie, ids []int) []models.Movie {
+	byId := make(map[int]models.Movie, len(movies))
+	for _, m := range movies {
+		byId[m.Id] = m
+	}
+
+	ordered := make([]models.Movie, 0, len(movies))
+	for _, id := range ids {
+		if m, ok := byId[id]; ok {
+			ordered = append(ordered, m)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,523 @@
+package handlers
+
+import (
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/profanity"
+	"goozinshe/repositories"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxCommentBodyLength  = 2000
+	maxReportReasonLength = 500
+)
+
+type CommentsHandler struct {
+	commentsRepo *repositories.CommentsRepository
+	moviesRepo   *repositories.MoviesRepository
+}
+
+func NewCommentsHandler(
+	commentsRepo *repositories.CommentsRepository,
+	moviesRepo *repositories.MoviesRepository) *CommentsHandler {
+	return &CommentsHandler{
+		commentsRepo: commentsRepo,
+		moviesRepo:   moviesRepo,
+	}
+}
+
+type createCommentRequest struct {
+	Body     string `json:"body"`
+	ParentId *int   `json:"parentId"`
+}
+
+type reactionRequest struct {
+	// like or dislike
+	Reaction string `json:"reaction"`
+}
+
+type reportCommentRequest struct {
+	Reason string `json:"reason"`
+}
+
+type blockedWordRequest struct {
+	Language string `json:"language"`
+	Word     string `json:"word"`
+}
+
+// HandleGetMovieComments godoc
+// @Summary      Get movie discussion
+// @Description  Top-level comments, newest first, each with its reply tree. Deleted comments with replies are kept as placeholders. Held comments are only shown to their author.
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Param        page query int false "Page number" default(1)
+// @Param        pageSize query int false "Page size" default(20)
+// @Success      200 {object} models.Page[models.Comment] "OK"
+// @Failure      400 {object} models.ApiError "Invalid movie id"
+// @Failure      404 {object} models.ApiError "Movie not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /movies/{id}/comments [get]
+// @Security     Bearer
+func (h *CommentsHandler) HandleGetMovieComments(c *gin.Context) {
+	movieId, ok := parseVisibleMovieId(c, h.moviesRepo)
+	if !ok {
+		return
+	}
+
+	page, pageSize := getPagination(c)
+	comments, total, err := h.commentsRepo.FindThreads(c, movieId, c.GetInt("profileId"), pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Page[models.Comment]{
+		Items:    comments,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// HandleCreateComment godoc
+// @Summary      Post comment
+// @Description  Posts a top-level comment or, with parentId, a reply. Comments containing words from the blocklist of any supported language are held for moderation.
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Param        request body createCommentRequest true "Comment"
+// @Success      200 {object} object{id=int,status=string} "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "Movie or parent comment not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /movies/{id}/comments [post]
+// @Security     Bearer
+func (h *CommentsHandler) HandleCreateComment(c *gin.Context) {
+	movieId, ok := parseVisibleMovieId(c, h.moviesRepo)
+	if !ok {
+		return
+	}
+
+	var request createCommentRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+	request.Body = strings.TrimSpace(request.Body)
+	if request.Body == "" || utf8.RuneCountInString(request.Body) > maxCommentBodyLength {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid comment body"))
+		return
+	}
+	if request.ParentId != nil {
+		parent, err := h.commentsRepo.FindById(c, *request.ParentId, c.GetInt("profileId"))
+		if err != nil || parent.MovieId != movieId || parent.IsRemoved() || parent.Status != models.CommentStatusPublished {
+			c.JSON(http.StatusNotFound, models.NewApiError("Parent comment not found"))
+			return
+		}
+	}
+
+	// The request language is chosen by the client, so the body is checked
+	// against the blocklists of all languages.
+	blocklist, err := h.commentsRepo.FindBlocklist(c, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	words := make([]string, 0, len(blocklist))
+	for _, blocked := range blocklist {
+		words = append(words, blocked.Word)
+	}
+	flagged := profanity.Find(request.Body, words)
+
+	id, err := h.commentsRepo.Create(c, models.Comment{
+		MovieId:   movieId,
+		ProfileId: c.GetInt("profileId"),
+		ParentId:  request.ParentId,
+		Language:  c.GetString("lang"),
+		Body:      request.Body,
+	}, flagged)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	status := models.CommentStatusPublished
+	if len(flagged) > 0 {
+		status = models.CommentStatusHeld
+		logger := logger.GetLogger()
+		logger.Info("Comment has been held for moderation", zap.Int("comment_id", id), zap.Strings("words", flagged))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":     id,
+		"status": status,
+	})
+}
+
+// HandleDeleteComment godoc
+// @Summary      Delete my comment
+// @Description  The comment stays in the thread as a placeholder while it has replies.
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Comment id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid comment id"
+// @Failure      404 {object} models.ApiError "Comment not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /comments/{id} [delete]
+// @Security     Bearer
+func (h *CommentsHandler) HandleDeleteComment(c *gin.Context) {
+	comment, ok := h.findComment(c)
+	if !ok {
+		return
+	}
+	if comment.IsRemoved() || comment.ProfileId != c.GetInt("profileId") {
+		c.JSON(http.StatusNotFound, models.NewApiError("Comment not found"))
+		return
+	}
+
+	err := h.commentsRepo.Delete(c, comment.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleSetReaction godoc
+// @Summary      Like or dislike comment
+// @Description  Replaces the current profile's previous reaction to the comment.
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Comment id"
+// @Param        request body reactionRequest true "Reaction"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data or own comment"
+// @Failure      404 {object} models.ApiError "Comment not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /comments/{id}/reaction [put]
+// @Security     Bearer
+func (h *CommentsHandler) HandleSetReaction(c *gin.Context) {
+	var request reactionRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+
+	var value int
+	switch request.Reaction {
+	case models.ReactionLike:
+		value = 1
+	case models.ReactionDislike:
+		value = -1
+	default:
+		c.JSON(http.StatusBadRequest, models.NewApiError("Reaction must be like or dislike"))
+		return
+	}
+
+	h.setReaction(c, value)
+}
+
+// HandleRemoveReaction godoc
+// @Summary      Remove my reaction to comment
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Comment id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid comment id"
+// @Failure      404 {object} models.ApiError "Comment not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /comments/{id}/reaction [delete]
+// @Security     Bearer
+func (h *CommentsHandler) HandleRemoveReaction(c *gin.Context) {
+	h.setReaction(c, 0)
+}
+
+// HandleReportComment godoc
+// @Summary      Report comment
+// @Description  Sends the comment to the moderation queue. Reporting again replaces the previous reason.
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Comment id"
+// @Param        request body reportCommentRequest true "Report"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data or own comment"
+// @Failure      404 {object} models.ApiError "Comment not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /comments/{id}/report [post]
+// @Security     Bearer
+func (h *CommentsHandler) HandleReportComment(c *gin.Context) {
+	comment, ok := h.findPublishedComment(c)
+	if !ok {
+		return
+	}
+	if comment.ProfileId == c.GetInt("profileId") {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Own comment can not be reported"))
+		return
+	}
+
+	var request reportCommentRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+	request.Reason = strings.TrimSpace(request.Reason)
+	if request.Reason == "" || utf8.RuneCountInString(request.Reason) > maxReportReasonLength {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid report reason"))
+		return
+	}
+
+	err := h.commentsRepo.Report(c, comment.Id, c.GetInt("profileId"), request.Reason)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	logger := logger.GetLogger()
+	logger.Info("Comment has been reported", zap.Int("comment_id", comment.Id))
+	c.Status(http.StatusOK)
+}
+
+// HandleGetFlaggedComments godoc
+// @Summary      Get comments awaiting moderation
+// @Description  Comments held by the blocklist and comments with open reports, oldest first. Editors and admins only.
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        page query int false "Page number" default(1)
+// @Param        pageSize query int false "Page size" default(20)
+// @Success      200 {object} models.Page[models.FlaggedComment] "OK"
+// @Failure      403 {object} models.ApiError "Not an editor"
+// @Failure      500 {object} models.ApiError
+// @Router       /moderation/comments [get]
+// @Security     Bearer
+func (h *CommentsHandler) HandleGetFlaggedComments(c *gin.Context) {
+	page, pageSize := getPagination(c)
+	comments, total, err := h.commentsRepo.FindFlagged(c, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Page[models.FlaggedComment]{
+		Items:    comments,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// HandleApproveComment godoc
+// @Summary      Approve comment
+// @Description  Publishes a held comment or dismisses the reports on a published one. Editors and admins only.
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Comment id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid comment id"
+// @Failure      403 {object} models.ApiError "Not an editor"
+// @Failure      404 {object} models.ApiError "Comment not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /moderation/comments/{id}/approve [post]
+// @Security     Bearer
+func (h *CommentsHandler) HandleApproveComment(c *gin.Context) {
+	h.moderate(c, models.CommentStatusPublished)
+}
+
+// HandleRejectComment godoc
+// @Summary      Reject comment
+// @Description  Removes the comment from the discussion and resolves its reports. Replies stay under a placeholder. Editors and admins only.
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Comment id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid comment id"
+// @Failure      403 {object} models.ApiError "Not an editor"
+// @Failure      404 {object} models.ApiError "Comment not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /moderation/comments/{id}/reject [post]
+// @Security     Bearer
+func (h *CommentsHandler) HandleRejectComment(c *gin.Context) {
+	h.moderate(c, models.CommentStatusRejected)
+}
+
+// HandleGetBlocklist godoc
+// @Summary      Get comment blocklist
+// @Description  Editors and admins only.
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        language query string false "Only words of this language"
+// @Success      200 {array} models.BlockedWord "OK"
+// @Failure      403 {object} models.ApiError "Not an editor"
+// @Failure      500 {object} models.ApiError
+// @Router       /moderation/blocklist [get]
+// @Security     Bearer
+func (h *CommentsHandler) HandleGetBlocklist(c *gin.Context) {
+	words, err := h.commentsRepo.FindBlocklist(c, strings.ToLower(c.Query("language")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, words)
+}
+
+// HandleAddBlockedWord godoc
+// @Summary      Add word to comment blocklist
+// @Description  New comments in the language that contain the word are held for moderation. Editors and admins only.
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        request body blockedWordRequest true "Blocked word"
+// @Success      200 {object} object{id=int} "OK"
+// @Failure      400 {object} models.ApiError "Unsupported language or not a single word"
+// @Failure      403 {object} models.ApiError "Not an editor"
+// @Failure      500 {object} models.ApiError
+// @Router       /moderation/blocklist [post]
+// @Security     Bearer
+func (h *CommentsHandler) HandleAddBlockedWord(c *gin.Context) {
+	var request blockedWordRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+	language := strings.ToLower(request.Language)
+	if !models.IsSupportedLanguage(language) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Unsupported language"))
+		return
+	}
+	words := profanity.Words(request.Word)
+	if len(words) != 1 {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Blocklist entry must be a single word"))
+		return
+	}
+
+	id, err := h.commentsRepo.AddBlockedWord(c, models.BlockedWord{
+		Language: language,
+		Word:     words[0],
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id": id,
+	})
+}
+
+// HandleDeleteBlockedWord godoc
+// @Summary      Remove word from comment blocklist
+// @Description  Editors and admins only.
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Blocked word id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid id"
+// @Failure      403 {object} models.ApiError "Not an editor"
+// @Failure      500 {object} models.ApiError
+// @Router       /moderation/blocklist/{id} [delete]
+// @Security     Bearer
+func (h *CommentsHandler) HandleDeleteBlockedWord(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Id"))
+		return
+	}
+
+	err = h.commentsRepo.DeleteBlockedWord(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *CommentsHandler) moderate(c *gin.Context, status string) {
+	comment, ok := h.findComment(c)
+	if !ok {
+		return
+	}
+	if comment.IsDeleted {
+		c.JSON(http.StatusNotFound, models.NewApiError("Comment not found"))
+		return
+	}
+
+	err := h.commentsRepo.Moderate(c, comment.Id, status, c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	logger := logger.GetLogger()
+	logger.Info("Comment has been moderated", zap.Int("comment_id", comment.Id), zap.String("status", status))
+	c.Status(http.StatusOK)
+}
+
+func (h *CommentsHandler) setReaction(c *gin.Context, value int) {
+	comment, ok := h.findPublishedComment(c)
+	if !ok {
+		return
+	}
+	if comment.ProfileId == c.GetInt("profileId") {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Own comment can not be reacted to"))
+		return
+	}
+
+	err := h.commentsRepo.SetReaction(c, comment.Id, c.GetInt("profileId"), value)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// findPublishedComment finds a comment that other viewers can see and
+// interact with.
+func (h *CommentsHandler) findPublishedComment(c *gin.Context) (models.Comment, bool) {
+	comment, ok := h.findComment(c)
+	if !ok {
+		return models.Comment{}, false
+	}
+	if comment.IsRemoved() || comment.Status != models.CommentStatusPublished {
+		c.JSON(http.StatusNotFound, models.NewApiError("Comment not found"))
+		return models.Comment{}, false
+	}
+	return comment, true
+}
+
+func (h *CommentsHandler) findComment(c *gin.Context) (models.Comment, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Comment Id"))
+		return models.Comment{}, false
+	}
+
+	comment, err := h.commentsRepo.FindById(c, id, c.GetInt("profileId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Comment not found"))
+		return models.Comment{}, false
+	}
+	return comment, true
+}
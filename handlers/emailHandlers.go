@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"goozinshe/config"
+	"goozinshe/models"
+	"goozinshe/notifier"
+	"goozinshe/repositories"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailHandler struct {
+	emailRepo *repositories.EmailRepository
+}
+
+func NewEmailHandler(emailRepo *repositories.EmailRepository) *EmailHandler {
+	return &EmailHandler{emailRepo: emailRepo}
+}
+
+type emailPreferencesRequest struct {
+	Language     *string `json:"language"`
+	NewReleases  *bool   `json:"newReleases"`
+	WeeklyDigest *bool   `json:"weeklyDigest"`
+}
+
+func (r emailPreferencesRequest) applyTo(preferences models.EmailPreferences) models.EmailPreferences {
+	if r.Language != nil {
+		preferences.Language = *r.Language
+	}
+	if r.NewReleases != nil {
+		preferences.NewReleases = *r.NewReleases
+	}
+	if r.WeeklyDigest != nil {
+		preferences.WeeklyDigest = *r.WeeklyDigest
+	}
+	return preferences
+}
+
+type unsubscribeResponse struct {
+	Kind       string `json:"kind"`
+	Subscribed bool   `json:"subscribed"`
+}
+
+// HandleGetEmailPreferences godoc
+// @Summary      Get email preferences
+// @Description  Language of the emails and which optional emails the user gets. Verification and password reset emails are always sent.
+// @Tags         emails
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} models.EmailPreferences "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/email-preferences [get]
+// @Security     Bearer
+func (h *EmailHandler) HandleGetEmailPreferences(c *gin.Context) {
+	preferences, err := h.emailRepo.GetPreferences(c, c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// HandleSetEmailPreferences godoc
+// @Summary      Change email preferences
+// @Description  Only the fields present in the request are changed.
+// @Tags         emails
+// @Accept       json
+// @Produce      json
+// @Param        request body emailPreferencesRequest true "Email preferences"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/email-preferences [patch]
+// @Security     Bearer
+func (h *EmailHandler) HandleSetEmailPreferences(c *gin.Context) {
+	var request emailPreferencesRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+	if request.Language != nil && !models.IsSupportedLanguage(*request.Language) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Unsupported language"))
+		return
+	}
+
+	preferences, err := h.emailRepo.GetPreferences(c, c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	err = h.emailRepo.SetPreferences(c, c.GetInt("userId"), request.applyTo(preferences))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleGetUnsubscribe godoc
+// @Summary      Check unsubscribe link
+// @Description  Tells which emails the link in an email turns off, so that the page can ask for confirmation. Does not change anything.
+// @Tags         emails
+// @Accept       json
+// @Produce      json
+// @Param        token query string true "Token from the unsubscribe link"
+// @Success      200 {object} unsubscribeResponse "OK"
+// @Failure      400 {object} models.ApiError "Invalid token"
+// @Failure      500 {object} models.ApiError
+// @Router       /unsubscribe [get]
+func (h *EmailHandler) HandleGetUnsubscribe(c *gin.Context) {
+	userId, kind, err := notifier.ParseUnsubscribeToken(config.Config.EmailUnsubscribeSecret, c.Query("token"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid token"))
+		return
+	}
+
+	preferences, err := h.emailRepo.GetPreferences(c, userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, unsubscribeResponse{Kind: kind, Subscribed: preferences.Allows(kind)})
+}
+
+// HandleUnsubscribe godoc
+// @Summary      Unsubscribe
+// @Description  Turns off the emails the link was issued for, without signing in. Mail clients call it for one-click unsubscribe.
+// @Tags         emails
+// @Accept       json
+// @Produce      json
+// @Param        token query string true "Token from the unsubscribe link"
+// @Success      200 {object} unsubscribeResponse "OK"
+// @Failure      400 {object} models.ApiError "Invalid token"
+// @Failure      500 {object} models.ApiError
+// @Router       /unsubscribe [post]
+func (h *EmailHandler) HandleUnsubscribe(c *gin.Context) {
+	userId, kind, err := notifier.ParseUnsubscribeToken(config.Config.EmailUnsubscribeSecret, c.Query("token"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid token"))
+		return
+	}
+
+	preferences, err := h.emailRepo.GetPreferences(c, userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	switch kind {
+	case models.EmailNewRelease:
+		preferences.NewReleases = false
+	case models.EmailWeeklyDigest:
+		preferences.WeeklyDigest = false
+	}
+
+	err = h.emailRepo.SetPreferences(c, userId, preferences)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, unsubscribeResponse{Kind: kind, Subscribed: false})
+}
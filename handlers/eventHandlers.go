@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"goozinshe/config"
+	"goozinshe/events"
+	"goozinshe/models"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultHeartbeatInterval = 25 * time.Second
+
+type EventsHandler struct {
+	broker *events.Broker
+}
+
+func NewEventsHandler(broker *events.Broker) *EventsHandler {
+	return &EventsHandler{broker: broker}
+}
+
+// HandleStream godoc
+// @Summary      Stream catalog and watchlist updates
+// @Description  Server-Sent Events stream of movie.created, movie.updated, movie.deleted, genre.created, genre.updated, genre.deleted and, for the current profile only, watchlist.changed. Each event carries an id; reconnect with the Last-Event-ID header (or lastEventId query param) to receive the events missed meanwhile. A "reset" event means some of them are gone and the client should reload its data. EventSource can not send the Authorization header, so browsers pass the token in the access_token query param instead.
+// @Tags         events
+// @Produce      text/event-stream
+// @Param        Last-Event-ID header string false "Id of the last received event"
+// @Param        lastEventId query string false "Same as Last-Event-ID, for clients that can not set headers"
+// @Param        access_token query string false "Access token, for clients that can not set the Authorization header"
+// @Success      200 "Event stream"
+// @Failure      400 {object} models.ApiError "Invalid event id"
+// @Router       /events [get]
+// @Security     Bearer
+func (h *EventsHandler) HandleStream(c *gin.Context) {
+	lastEventIdStr := c.GetHeader("Last-Event-ID")
+	if lastEventIdStr == "" {
+		lastEventIdStr = c.Query("lastEventId")
+	}
+	var lastEventId int64
+	if lastEventIdStr != "" {
+		var err error
+		lastEventId, err = strconv.ParseInt(lastEventIdStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewApiError("Invalid event id"))
+			return
+		}
+	}
+
+	sub, missed, ok := h.broker.Subscribe(getViewer(c), lastEventId)
+	defer h.broker.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+	if !ok {
+		fmt.Fprint(c.Writer, "event: reset\ndata: {}\n\n")
+	}
+	for _, event := range missed {
+		writeEvent(c.Writer, event)
+	}
+	c.Writer.Flush()
+
+	interval := config.Config.EventsHeartbeatInterval
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, open := <-sub.Events:
+			if !open {
+				return
+			}
+			writeEvent(c.Writer, event)
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+// publishWatchlistChanged tells the profile's other clients to reload the
+// watchlist.
+func publishWatchlistChanged(broker *events.Broker, profileId int, movieId int) {
+	broker.Publish(events.Event{
+		Type:      events.WatchlistChanged,
+		Data:      gin.H{"movieId": movieId},
+		ProfileId: profileId,
+	})
+}
+
+func writeEvent(w io.Writer, event events.Event) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		data = []byte("{}")
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+}
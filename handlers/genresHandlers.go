@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
+	"goozinshe/events"
 	"goozinshe/logger"
 	"goozinshe/models"
+	"goozinshe/recommendations"
 	"goozinshe/repositories"
 	"net/http"
 	"strconv"
@@ -12,12 +16,19 @@ import (
 )
 
 type GenreHandlers struct {
-	repo *repositories.GenresRepository
+	repo    *repositories.GenresRepository
+	similar *recommendations.SimilarMovies
+	broker  *events.Broker
 }
 
-func NewGenreHandlers(repo *repositories.GenresRepository) *GenreHandlers {
+func NewGenreHandlers(
+	repo *repositories.GenresRepository,
+	similar *recommendations.SimilarMovies,
+	broker *events.Broker) *GenreHandlers {
 	return &GenreHandlers{
-		repo: repo,
+		repo:    repo,
+		similar: similar,
+		broker:  broker,
 	}
 }
 
@@ -27,6 +38,7 @@ func NewGenreHandlers(repo *repositories.GenresRepository) *GenreHandlers {
 // @Accept  json
 // @Produce  json
 // @Param id path int true "Genre id"
+// @Param lang query string false "Language (kk, ru, en)"
 // @Success 200 {object} models.Genre "OK"
 // @Failure 400 {object} models.ApiError "Invalid Genre id"
 // @Failure 404 {object} models.ApiError "Genre not found"
@@ -42,7 +54,7 @@ func (h *GenreHandlers) FindById(c *gin.Context) {
 		return
 	}
 
-	genre, err := h.repo.FindById(c, id)
+	genre, err := h.repo.FindById(c, id, c.GetString("lang"))
 	if err != nil {
 		logger.Error(err.Error())
 		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
@@ -57,11 +69,12 @@ func (h *GenreHandlers) FindById(c *gin.Context) {
 // @Tags        genres
 // @Accept      json
 // @Produce     json
+// @Param       lang query string false "Language (kk, ru, en)"
 // @Success     200 {array} models.Genre
 // @Failure     500 {object} models.ApiError
 // @Router      /genres [get]
 func (h *GenreHandlers) FindAll(c *gin.Context) {
-	genres, err := h.repo.FindAll(c)
+	genres, err := h.repo.FindAll(c, c.GetString("lang"))
 	if err != nil {
 		c.Status(http.StatusInternalServerError)
 		return
@@ -95,6 +108,7 @@ func (h *GenreHandlers) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
 		return
 	}
+	h.broker.Publish(events.Event{Type: events.GenreCreated, Data: gin.H{"id": id}})
 
 	c.JSON(http.StatusOK, gin.H{
 		"id": id,
@@ -121,7 +135,7 @@ func (h *GenreHandlers) Update(c *gin.Context) {
 		return
 	}
 
-	_, err = h.repo.FindById(c, id)
+	_, err = h.repo.FindById(c, id, c.GetString("lang"))
 	if err != nil {
 		logger.Error(err.Error())
 		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
@@ -142,6 +156,8 @@ func (h *GenreHandlers) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
 		return
 	}
+	h.similar.Invalidate()
+	h.broker.Publish(events.Event{Type: events.GenreUpdated, Data: gin.H{"id": id}})
 
 	c.Status(http.StatusOK)
 }
@@ -165,7 +181,7 @@ func (h *GenreHandlers) Delete(c *gin.Context) {
 		return
 	}
 
-	_, err = h.repo.FindById(c, id)
+	_, err = h.repo.FindById(c, id, c.GetString("lang"))
 	if err != nil {
 		logger.Error(err.Error())
 		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
@@ -178,6 +194,95 @@ func (h *GenreHandlers) Delete(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
 		return
 	}
+	h.similar.Invalidate()
+	h.broker.Publish(events.Event{Type: events.GenreDeleted, Data: gin.H{"id": id}})
 
 	c.Status(http.StatusOK)
 }
+
+// HandleFollow godoc
+// @Summary      Follow genre
+// @Description  The profile gets a new_release notification when a movie of the genre is added.
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Genre id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid genre id"
+// @Failure      404 {object} models.ApiError "Genre not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /genres/{id}/follow [post]
+// @Security     Bearer
+func (h *GenreHandlers) HandleFollow(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Genre Id"))
+		return
+	}
+
+	_, err = h.repo.FindById(c, id, c.GetString("lang"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, models.NewApiError("Genre not found"))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	err = h.repo.Follow(c, c.GetInt("profileId"), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleUnfollow godoc
+// @Summary      Unfollow genre
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Genre id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid genre id"
+// @Failure      500 {object} models.ApiError
+// @Router       /genres/{id}/follow [delete]
+// @Security     Bearer
+func (h *GenreHandlers) HandleUnfollow(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Genre Id"))
+		return
+	}
+
+	err = h.repo.Unfollow(c, c.GetInt("profileId"), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleGetFollowed godoc
+// @Summary      Get followed genres
+// @Description  Most recently followed first.
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Param        lang query string false "Language (kk, ru, en)"
+// @Success      200 {array} models.Genre "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/followed-genres [get]
+// @Security     Bearer
+func (h *GenreHandlers) HandleGetFollowed(c *gin.Context) {
+	genres, err := h.repo.FindFollowed(c, c.GetInt("profileId"), c.GetString("lang"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, genres)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"goozinshe/watching"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const historyDateLayout = "2006-01-02"
+
+type HistoryHandler struct {
+	moviesRepo  *repositories.MoviesRepository
+	historyRepo *repositories.HistoryRepository
+	watching    *watching.Service
+}
+
+func NewHistoryHandler(
+	moviesRepo *repositories.MoviesRepository,
+	historyRepo *repositories.HistoryRepository,
+	watching *watching.Service) *HistoryHandler {
+	return &HistoryHandler{
+		moviesRepo:  moviesRepo,
+		historyRepo: historyRepo,
+		watching:    watching,
+	}
+}
+
+// addHistoryRequest logs a rewatch; WatchedAt defaults to now and may be in the past.
+type addHistoryRequest struct {
+	MovieId   int        `json:"movieId"`
+	WatchedAt *time.Time `json:"watchedAt"`
+}
+
+// HandleGetHistory godoc
+// @Summary      Get watch history
+// @Description  Every (re)watch of the current profile, newest first.
+// @Tags         history
+// @Accept       json
+// @Produce      json
+// @Param        from query string false "Start date, inclusive (YYYY-MM-DD)"
+// @Param        to query string false "End date, inclusive (YYYY-MM-DD)"
+// @Param        page query int false "Page number" default(1)
+// @Param        pageSize query int false "Page size" default(20)
+// @Param        lang query string false "Language (kk, ru, en)"
+// @Success      200 {object} models.Page[models.HistoryEntry] "OK"
+// @Failure      400 {object} models.ApiError "Invalid date"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/history [get]
+// @Security     Bearer
+func (h *HistoryHandler) HandleGetHistory(c *gin.Context) {
+	var from, to time.Time
+	var err error
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err = time.Parse(historyDateLayout, fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewApiError("Invalid from date"))
+			return
+		}
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		to, err = time.Parse(historyDateLayout, toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewApiError("Invalid to date"))
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+
+	page, pageSize := getPagination(c)
+	viewer := getViewer(c)
+	events, total, err := h.historyRepo.FindPage(c, viewer, from, to, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	movieIds := make([]int, 0, len(events))
+	for _, e := range events {
+		movieIds = append(movieIds, e.MovieId)
+	}
+	movies, err := h.moviesRepo.FindAllByIds(c, movieIds, viewer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	moviesById := make(map[int]models.Movie, len(movies))
+	for _, m := range movies {
+		moviesById[m.Id] = m
+	}
+
+	entries := make([]models.HistoryEntry, 0, len(events))
+	for _, e := range events {
+		if m, ok := moviesById[e.MovieId]; ok {
+			entries = append(entries, models.HistoryEntry{Id: e.Id, WatchedAt: e.WatchedAt, Movie: m})
+		}
+	}
+
+	c.JSON(http.StatusOK, models.Page[models.HistoryEntry]{
+		Items:    entries,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// HandleAddHistory godoc
+// @Summary      Log a (re)watch
+// @Description  Adds a watch event, optionally backdated, and marks the movie as watched.
+// @Tags         history
+// @Accept       json
+// @Produce      json
+// @Param        request body addHistoryRequest true "Watched movie"
+// @Success      200 {object} object{id=int} "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "Movie not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/history [post]
+// @Security     Bearer
+func (h *HistoryHandler) HandleAddHistory(c *gin.Context) {
+	logger := logger.GetLogger()
+	var request addHistoryRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+
+	watchedAt := time.Now()
+	if request.WatchedAt != nil {
+		if request.WatchedAt.After(watchedAt) {
+			c.JSON(http.StatusBadRequest, models.NewApiError("watchedAt can not be in the future"))
+			return
+		}
+		watchedAt = *request.WatchedAt
+	}
+
+	viewer := getViewer(c)
+	exists, err := h.moviesRepo.Exists(c, request.MovieId, viewer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie not found"))
+		return
+	}
+
+	id, err := h.watching.LogWatch(c, viewer.ProfileId, request.MovieId, watchedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	logger.Info("Watch event has been added", zap.Int("movie_id", request.MovieId), zap.Int("profile_id", viewer.ProfileId))
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+// HandleDeleteHistory godoc
+// @Summary      Delete history entry
+// @Tags         history
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "History entry id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid id"
+// @Failure      404 {object} models.ApiError "Entry not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/history/{id} [delete]
+// @Security     Bearer
+func (h *HistoryHandler) HandleDeleteHistory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid history entry id"))
+		return
+	}
+
+	found, err := h.historyRepo.Delete(c, c.GetInt("profileId"), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.NewApiError("History entry not found"))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobsHandler struct {
+	jobsRepo *repositories.JobsRepository
+}
+
+func NewJobsHandler(jobsRepo *repositories.JobsRepository) *JobsHandler {
+	return &JobsHandler{jobsRepo: jobsRepo}
+}
+
+// HandleGetJobs godoc
+// @Summary      Get background jobs
+// @Description  Newest first. Failed jobs ran out of attempts; LastError holds the error of the latest attempt. Admins only.
+// @Tags         jobs
+// @Accept       json
+// @Produce      json
+// @Param        queue query string false "Queue, e.g. default or maintenance"
+// @Param        type query string false "Job type, e.g. charts.refresh"
+// @Param        status query string false "pending, running, succeeded or failed"
+// @Param        page query int false "Page number" default(1)
+// @Param        pageSize query int false "Page size" default(20)
+// @Success      200 {object} models.Page[models.Job] "OK"
+// @Failure      400 {object} models.ApiError "Invalid status"
+// @Failure      403 {object} models.ApiError "Not an admin"
+// @Failure      500 {object} models.ApiError
+// @Router       /admin/jobs [get]
+// @Security     Bearer
+func (h *JobsHandler) HandleGetJobs(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", models.JobStatusPending, models.JobStatusRunning, models.JobStatusSucceeded, models.JobStatusFailed:
+	default:
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid status"))
+		return
+	}
+
+	page, pageSize := getPagination(c)
+	jobs, total, err := h.jobsRepo.FindAll(c, c.Query("queue"), c.Query("type"), status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Page[models.Job]{
+		Items:    jobs,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// HandleGetJobCounts godoc
+// @Summary      Count background jobs
+// @Description  Number of jobs per queue and status. Admins only.
+// @Tags         jobs
+// @Accept       json
+// @Produce      json
+// @Success      200 {array} models.JobCount "OK"
+// @Failure      403 {object} models.ApiError "Not an admin"
+// @Failure      500 {object} models.ApiError
+// @Router       /admin/jobs/counts [get]
+// @Security     Bearer
+func (h *JobsHandler) HandleGetJobCounts(c *gin.Context) {
+	counts, err := h.jobsRepo.CountByStatus(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, counts)
+}
+
+// HandleRetryJob godoc
+// @Summary      Retry failed job
+// @Description  Queues a failed job again with a fresh set of attempts. Admins only.
+// @Tags         jobs
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Job id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid job id"
+// @Failure      403 {object} models.ApiError "Not an admin"
+// @Failure      404 {object} models.ApiError "Failed job not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /admin/jobs/{id}/retry [post]
+// @Security     Bearer
+func (h *JobsHandler) HandleRetryJob(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid job id"))
+		return
+	}
+
+	found, err := h.jobsRepo.Retry(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.NewApiError("Failed job not found"))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
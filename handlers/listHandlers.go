@@ -0,0 +1,638 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"go.uber.org/zap"
+	"goozinshe/events"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	shareTokenBytes = 24
+	// shareTokenLength is the length of a base64url encoded share token.
+	shareTokenLength = 32
+)
+
+type ListsHandler struct {
+	listsRepo  *repositories.ListsRepository
+	moviesRepo *repositories.MoviesRepository
+	socialRepo *repositories.SocialRepository
+	broker     *events.Broker
+}
+
+func NewListsHandler(
+	listsRepo *repositories.ListsRepository,
+	moviesRepo *repositories.MoviesRepository,
+	socialRepo *repositories.SocialRepository,
+	broker *events.Broker) *ListsHandler {
+	return &ListsHandler{
+		listsRepo:  listsRepo,
+		moviesRepo: moviesRepo,
+		socialRepo: socialRepo,
+		broker:     broker,
+	}
+}
+
+type listRequest struct {
+	Name     string `json:"name"`
+	IsPublic bool   `json:"isPublic"`
+}
+
+type addListMovieRequest struct {
+	Note     string `json:"note"`
+	Position int    `json:"position"`
+}
+
+// listEntryRequest changes the fields that are present. RemindAt is a
+// YYYY-MM-DD date; an empty string removes the reminder.
+type listEntryRequest struct {
+	Note     *string `json:"note"`
+	Priority *int    `json:"priority"`
+	RemindAt *string `json:"remindAt"`
+}
+
+const reminderDateLayout = "2006-01-02"
+
+func (r listEntryRequest) toUpdate() (models.ListEntryUpdate, error) {
+	update := models.ListEntryUpdate{
+		Note:     r.Note,
+		Priority: r.Priority,
+	}
+	if r.Priority != nil && !models.IsValidPriority(*r.Priority) {
+		return models.ListEntryUpdate{}, errors.New("Invalid priority")
+	}
+	if r.RemindAt != nil {
+		if *r.RemindAt == "" {
+			update.ClearReminder = true
+		} else {
+			remindAt, err := time.Parse(reminderDateLayout, *r.RemindAt)
+			if err != nil {
+				return models.ListEntryUpdate{}, errors.New("Invalid reminder date")
+			}
+			update.RemindAt = &remindAt
+		}
+	}
+	return update, nil
+}
+
+type moveListMovieRequest struct {
+	Position int `json:"position"`
+}
+
+// FindAll godoc
+// @Summary      Get my lists
+// @Description  Lists of the current profile, the default watchlist first.
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Success      200 {array} models.List "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /lists [get]
+// @Security     Bearer
+func (h *ListsHandler) FindAll(c *gin.Context) {
+	lists, err := h.listsRepo.FindAllByProfileId(c, c.GetInt("profileId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, lists)
+}
+
+// FindById godoc
+// @Summary      Get list with its movies in order
+// @Description  Own lists and public lists of other profiles can be viewed.
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "List id"
+// @Param        lang query string false "Language (kk, ru, en)"
+// @Success      200 {object} models.ListWithItems "OK"
+// @Failure      400 {object} models.ApiError "Invalid list id"
+// @Failure      404 {object} models.ApiError "List not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /lists/{id} [get]
+// @Security     Bearer
+func (h *ListsHandler) FindById(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid List Id"))
+		return
+	}
+
+	list, err := h.listsRepo.FindById(c, id)
+	isOwner := err == nil && list.ProfileId == c.GetInt("profileId")
+	if err != nil || (!list.IsPublic && !isOwner) {
+		c.JSON(http.StatusNotFound, models.NewApiError("List not found"))
+		return
+	}
+	if !isOwner {
+		list.ShareToken = ""
+	}
+
+	items, err := h.findItems(c, list, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ListWithItems{
+		List:  list,
+		Items: items,
+	})
+}
+
+// HandleShare godoc
+// @Summary      Share list by link
+// @Description  Generates a new unguessable share token for the list, replacing the previous one, so that anyone with the link can view the list without an account.
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "List id"
+// @Success      200 {object} object{token=string,url=string} "OK"
+// @Failure      400 {object} models.ApiError "Invalid list id"
+// @Failure      404 {object} models.ApiError "List not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /lists/{id}/share [post]
+// @Security     Bearer
+func (h *ListsHandler) HandleShare(c *gin.Context) {
+	list, ok := h.findOwnList(c)
+	if !ok {
+		return
+	}
+
+	token, err := generateShareToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	err = h.listsRepo.SetShareToken(c, list.Id, token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token": token,
+		"url":   absoluteUrl(c, "shared/lists/"+token),
+	})
+}
+
+// HandleRevokeShare godoc
+// @Summary      Stop sharing list by link
+// @Description  Invalidates the list's share token; links handed out before stop working.
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "List id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid list id"
+// @Failure      404 {object} models.ApiError "List not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /lists/{id}/share [delete]
+// @Security     Bearer
+func (h *ListsHandler) HandleRevokeShare(c *gin.Context) {
+	list, ok := h.findOwnList(c)
+	if !ok {
+		return
+	}
+
+	err := h.listsRepo.SetShareToken(c, list.Id, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleGetShared godoc
+// @Summary      Get shared list
+// @Description  Read-only view of a list shared by link. No authorization is needed, poster URLs are absolute.
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        token path string true "Share token"
+// @Param        lang query string false "Language (kk, ru, en)"
+// @Success      200 {object} models.ListWithItems "OK"
+// @Failure      404 {object} models.ApiError "List not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /shared/lists/{token} [get]
+func (h *ListsHandler) HandleGetShared(c *gin.Context) {
+	token := c.Param("token")
+	if len(token) != shareTokenLength {
+		c.JSON(http.StatusNotFound, models.NewApiError("List not found"))
+		return
+	}
+
+	list, err := h.listsRepo.FindByShareToken(c, token)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("List not found"))
+		return
+	}
+	list.ShareToken = ""
+
+	viewer := models.Viewer{
+		Language:     c.GetString("lang"),
+		MaxAgeRating: models.MaxAgeRating,
+	}
+	items, err := h.findItems(c, list, viewer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	for i := range items {
+		items[i].Movie.PosterUrl = imageUrl(c, items[i].Movie.PosterUrl)
+	}
+
+	c.JSON(http.StatusOK, models.ListWithItems{
+		List:  list,
+		Items: items,
+	})
+}
+
+// Create godoc
+// @Summary      Create list
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        request body listRequest true "List data"
+// @Success      200 {object} object{id=int} "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      500 {object} models.ApiError
+// @Router       /lists [post]
+// @Security     Bearer
+func (h *ListsHandler) Create(c *gin.Context) {
+	var request listRequest
+	if err := c.BindJSON(&request); err != nil || strings.TrimSpace(request.Name) == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+
+	id, err := h.listsRepo.Create(c, models.List{
+		ProfileId: c.GetInt("profileId"),
+		Name:      strings.TrimSpace(request.Name),
+		IsPublic:  request.IsPublic,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	logger := logger.GetLogger()
+	logger.Info("List has been created", zap.Int("list_id", id))
+
+	c.JSON(http.StatusOK, gin.H{
+		"id": id,
+	})
+}
+
+// Update godoc
+// @Summary      Rename list or change its visibility
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "List id"
+// @Param        request body listRequest true "List data"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "List not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /lists/{id} [put]
+// @Security     Bearer
+func (h *ListsHandler) Update(c *gin.Context) {
+	list, ok := h.findOwnList(c)
+	if !ok {
+		return
+	}
+
+	var request listRequest
+	if err := c.BindJSON(&request); err != nil || strings.TrimSpace(request.Name) == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+
+	list.Name = strings.TrimSpace(request.Name)
+	list.IsPublic = request.IsPublic
+	err := h.listsRepo.Update(c, list.Id, list)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// Delete godoc
+// @Summary      Delete list
+// @Description  The default watchlist can not be deleted.
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "List id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid list id or default list"
+// @Failure      404 {object} models.ApiError "List not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /lists/{id} [delete]
+// @Security     Bearer
+func (h *ListsHandler) Delete(c *gin.Context) {
+	list, ok := h.findOwnList(c)
+	if !ok {
+		return
+	}
+	if list.IsDefault {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Default list can not be deleted"))
+		return
+	}
+
+	err := h.listsRepo.Delete(c, list.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleAddMovie godoc
+// @Summary      Add movie to list
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "List id"
+// @Param        movieId path int true "Movie id"
+// @Param        request body addListMovieRequest false "Note and 1-based position, appended when position is omitted"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "List or movie not found"
+// @Failure      409 {object} models.ApiError "Movie is already in the list"
+// @Failure      500 {object} models.ApiError
+// @Router       /lists/{id}/movies/{movieId} [post]
+// @Security     Bearer
+func (h *ListsHandler) HandleAddMovie(c *gin.Context) {
+	list, movieId, ok := h.parseMembershipIds(c)
+	if !ok {
+		return
+	}
+
+	var request addListMovieRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&request); err != nil || request.Position < 0 {
+			c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+			return
+		}
+	}
+
+	exists, err := h.moviesRepo.Exists(c, movieId, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie not found"))
+		return
+	}
+
+	added, err := h.listsRepo.AddMovie(c, list.Id, movieId, request.Note, request.Position)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !added {
+		c.JSON(http.StatusConflict, models.NewApiError("Movie is already in the list"))
+		return
+	}
+	recordActivity(c, h.socialRepo, listActivity(list, movieId))
+	h.notifyWatchlistChanged(list, movieId)
+
+	c.Status(http.StatusOK)
+}
+
+// HandleUpdateEntry godoc
+// @Summary      Change note, priority or reminder of a list movie
+// @Description  Only the fields present in the request are changed. Priority is 1 (low), 2 (normal) or 3 (high).
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "List id"
+// @Param        movieId path int true "Movie id"
+// @Param        request body listEntryRequest true "Fields to change"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "List or movie not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /lists/{id}/movies/{movieId} [patch]
+// @Security     Bearer
+func (h *ListsHandler) HandleUpdateEntry(c *gin.Context) {
+	list, movieId, ok := h.parseMembershipIds(c)
+	if !ok {
+		return
+	}
+
+	var request listEntryRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+	update, err := request.toUpdate()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
+		return
+	}
+
+	found, err := h.listsRepo.UpdateEntry(c, list.Id, movieId, update)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie is not in the list"))
+		return
+	}
+	h.notifyWatchlistChanged(list, movieId)
+
+	c.Status(http.StatusOK)
+}
+
+// HandleMoveMovie godoc
+// @Summary      Move movie within list
+// @Description  Drag-and-drop reordering: the movie takes the given position and the movies in between shift by one.
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "List id"
+// @Param        movieId path int true "Movie id"
+// @Param        request body moveListMovieRequest true "New 1-based position"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "List or movie not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /lists/{id}/movies/{movieId}/move [post]
+// @Security     Bearer
+func (h *ListsHandler) HandleMoveMovie(c *gin.Context) {
+	list, movieId, ok := h.parseMembershipIds(c)
+	if !ok {
+		return
+	}
+
+	var request moveListMovieRequest
+	if err := c.BindJSON(&request); err != nil || request.Position < 1 {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid position"))
+		return
+	}
+
+	found, err := h.listsRepo.MoveMovie(c, list.Id, movieId, request.Position)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie is not in the list"))
+		return
+	}
+	h.notifyWatchlistChanged(list, movieId)
+
+	c.Status(http.StatusOK)
+}
+
+// HandleRemoveMovie godoc
+// @Summary      Remove movie from list
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "List id"
+// @Param        movieId path int true "Movie id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "List not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /lists/{id}/movies/{movieId} [delete]
+// @Security     Bearer
+func (h *ListsHandler) HandleRemoveMovie(c *gin.Context) {
+	list, movieId, ok := h.parseMembershipIds(c)
+	if !ok {
+		return
+	}
+
+	err := h.listsRepo.RemoveMovie(c, list.Id, movieId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	retractActivity(c, h.socialRepo, listActivity(list, movieId))
+	h.notifyWatchlistChanged(list, movieId)
+
+	c.Status(http.StatusOK)
+}
+
+// notifyWatchlistChanged publishes a watchlist change when the list is the
+// default one that backs the watchlist.
+func (h *ListsHandler) notifyWatchlistChanged(list models.List, movieId int) {
+	if list.IsDefault {
+		publishWatchlistChanged(h.broker, list.ProfileId, movieId)
+	}
+}
+
+// listActivity describes adding the movie to the list. Additions to the
+// default list are watchlist activity and do not reveal the list itself.
+func listActivity(list models.List, movieId int) models.ActivityEvent {
+	if list.IsDefault {
+		return models.ActivityEvent{ProfileId: list.ProfileId, Type: models.ActivityWatchlisted, MovieId: movieId}
+	}
+	return models.ActivityEvent{ProfileId: list.ProfileId, Type: models.ActivityListed, MovieId: movieId, ListId: &list.Id}
+}
+
+// findOwnList loads the list from the id path param and makes sure it
+// belongs to the current profile, responding with an error otherwise.
+func (h *ListsHandler) findOwnList(c *gin.Context) (models.List, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid List Id"))
+		return models.List{}, false
+	}
+
+	list, err := h.listsRepo.FindById(c, id)
+	if err != nil || list.ProfileId != c.GetInt("profileId") {
+		c.JSON(http.StatusNotFound, models.NewApiError("List not found"))
+		return models.List{}, false
+	}
+	return list, true
+}
+
+func (h *ListsHandler) parseMembershipIds(c *gin.Context) (models.List, int, bool) {
+	list, ok := h.findOwnList(c)
+	if !ok {
+		return models.List{}, 0, false
+	}
+	movieId, err := strconv.Atoi(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+		return models.List{}, 0, false
+	}
+	return list, movieId, true
+}
+
+// generateShareToken returns shareTokenLength URL-safe characters of
+// crypto/rand output.
+func generateShareToken() (string, error) {
+	buf := make([]byte, shareTokenBytes)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// findItems loads the list movies visible to the viewer in list order.
+func (h *ListsHandler) findItems(c *gin.Context, list models.List, viewer models.Viewer) ([]models.ListItem, error) {
+	entries, err := h.listsRepo.FindEntries(c, list.Id)
+	if err != nil {
+		return nil, err
+	}
+	return buildListItems(c, h.moviesRepo, entries, viewer)
+}
+
+// buildListItems joins list entries with the movies visible to the viewer,
+// keeping the order of entries.
+func buildListItems(c *gin.Context, moviesRepo *repositories.MoviesRepository, entries []models.ListEntry, viewer models.Viewer) ([]models.ListItem, error) {
+	movieIds := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		movieIds = append(movieIds, entry.MovieId)
+	}
+	movies, err := moviesRepo.FindAllByIds(c, movieIds, viewer)
+	if err != nil {
+		return nil, err
+	}
+	byId := make(map[int]models.Movie, len(movies))
+	for _, movie := range movies {
+		byId[movie.Id] = movie
+	}
+
+	items := make([]models.ListItem, 0, len(entries))
+	for _, entry := range entries {
+		movie, ok := byId[entry.MovieId]
+		if !ok {
+			continue
+		}
+		items = append(items, models.ListItem{
+			Movie:    movie,
+			Note:     entry.Note,
+			Position: entry.Position,
+			Priority: entry.Priority,
+			RemindAt: entry.RemindAt,
+			AddedAt:  entry.AddedAt,
+		})
+	}
+	return items, nil
+}
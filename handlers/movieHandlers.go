@@ -3,49 +3,81 @@ package handlers
 import (
 	"fmt"
 	"go.uber.org/zap"
+	"goozinshe/events"
 	"goozinshe/logger"
 	"goozinshe/models"
+	"goozinshe/recommendations"
 	"goozinshe/repositories"
+	"goozinshe/watching"
 	"mime/multipart"
 	"net/http"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+const (
+	defaultSimilarLimit = 10
+	maxSimilarLimit     = 50
+)
+
 type MoviesHandler struct {
 	moviesRepo *repositories.MoviesRepository
 	genresRepo *repositories.GenresRepository
+	tagsRepo   *repositories.TagsRepository
+	similar    *recommendations.SimilarMovies
+	socialRepo *repositories.SocialRepository
+	broker     *events.Broker
+	watching   *watching.Service
 }
 
 type createMovieRequest struct {
-	Title       string                `form:"title"`
-	Description string                `form:"description"`
-	ReleaseYear int                   `form:"releaseYear"`
-	Director    string                `form:"director"`
-	TrailerUrl  string                `form:"trailerUrl"`
-	GenreIds    []int                 `form:"genreIds"`
-	Poster      *multipart.FileHeader `form:"poster"`
+	Title          string                `form:"title"`
+	Description    string                `form:"description"`
+	ReleaseYear    int                   `form:"releaseYear"`
+	AgeRating      *int                  `form:"ageRating"`
+	RuntimeMinutes int                   `form:"runtimeMinutes"`
+	Director       string                `form:"director"`
+	TrailerUrl     string                `form:"trailerUrl"`
+	GenreIds       []int                 `form:"genreIds"`
+	TagIds         []int                 `form:"tagIds"`
+	Poster         *multipart.FileHeader `form:"poster"`
 }
 
+// updateMovieRequest keeps the stored age rating when AgeRating is omitted,
+// so that clients unaware of the field do not reset it to 0+.
 type updateMovieRequest struct {
-	Title       string                `form:"title"`
-	Description string                `form:"description"`
-	ReleaseYear int                   `form:"releaseYear"`
-	Director    string                `form:"director"`
-	TrailerUrl  string                `form:"trailerUrl"`
-	GenreIds    []int                 `form:"genreIds"`
-	Poster      *multipart.FileHeader `form:"poster"`
+	Title          string                `form:"title"`
+	Description    string                `form:"description"`
+	ReleaseYear    int                   `form:"releaseYear"`
+	AgeRating      *int                  `form:"ageRating"`
+	RuntimeMinutes int                   `form:"runtimeMinutes"`
+	Director       string                `form:"director"`
+	TrailerUrl     string                `form:"trailerUrl"`
+	GenreIds       []int                 `form:"genreIds"`
+	TagIds         []int                 `form:"tagIds"`
+	Poster         *multipart.FileHeader `form:"poster"`
 }
 
 func NewMoviesHandler(
 	moviesRepo *repositories.MoviesRepository,
-	genreRepo *repositories.GenresRepository) *MoviesHandler {
+	genreRepo *repositories.GenresRepository,
+	tagsRepo *repositories.TagsRepository,
+	similar *recommendations.SimilarMovies,
+	socialRepo *repositories.SocialRepository,
+	broker *events.Broker,
+	watching *watching.Service) *MoviesHandler {
 	return &MoviesHandler{
 		moviesRepo: moviesRepo,
 		genresRepo: genreRepo,
+		tagsRepo:   tagsRepo,
+		similar:    similar,
+		socialRepo: socialRepo,
+		broker:     broker,
+		watching:   watching,
 	}
 }
 
@@ -55,6 +87,7 @@ func NewMoviesHandler(
 // @Accept       json
 // @Produce      json
 // @Param        id path int true "Movie id"
+// @Param        lang query string false "Language (kk, ru, en)"
 // @Success      200 {object} models.Movie "OK"
 // @Failure      400 {object} models.ApiError "Invalid movie id"
 // @Failure      404 {object} models.ApiError "Movie not found"
@@ -69,7 +102,7 @@ func (h *MoviesHandler) FindById(c *gin.Context) {
 		return
 	}
 
-	movie, err := h.moviesRepo.FindById(c, id)
+	movie, err := h.moviesRepo.FindById(c, id, getViewer(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
 		return
@@ -84,7 +117,9 @@ func (h *MoviesHandler) FindById(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        filters query models.MovieFilters true "Movie filters"
+// @Param        lang query string false "Language (kk, ru, en)"
 // @Success      200 {object} models.Movie "OK"
+// @Failure      400 {object} models.ApiError "Invalid tag ids"
 // @Failure      500 {object} models.ApiError
 // @Router       /movies [get]
 // @Security     Bearer
@@ -93,10 +128,17 @@ func (h *MoviesHandler) FindAll(c *gin.Context) {
 		SearchTerm: c.Query("search"),
 		IsWatched:  c.Query("iswatched"),
 		GenreId:    c.Query("genreids"),
+		Tags:       c.Query("tags"),
 		Sort:       c.Query("sort"),
 	}
+	tagIds, err := parseTagIds(filters.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid tag ids"))
+		return
+	}
+	filters.TagIds = tagIds
 
-	movies, err := h.moviesRepo.FindAll(c, filters)
+	movies, err := h.moviesRepo.FindAll(c, filters, getViewer(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, err)
 		return
@@ -105,6 +147,76 @@ func (h *MoviesHandler) FindAll(c *gin.Context) {
 	c.JSON(http.StatusOK, movies)
 }
 
+// HandleGetSimilar godoc
+// @Summary      Get similar movies
+// @Description  Movies sharing genres, tags, director or release era with the given one, best match first. Movies already watched by the current profile are left out.
+// @Tags         movies
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Param        limit query int false "Maximum number of movies" default(10)
+// @Param        lang query string false "Language (kk, ru, en)"
+// @Success      200 {array} models.Movie "OK"
+// @Failure      400 {object} models.ApiError "Invalid movie id"
+// @Failure      404 {object} models.ApiError "Movie not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /movies/{id}/similar [get]
+// @Security     Bearer
+func (h *MoviesHandler) HandleGetSimilar(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSimilarLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultSimilarLimit
+	}
+	if limit > maxSimilarLimit {
+		limit = maxSimilarLimit
+	}
+
+	viewer := getViewer(c)
+	exists, err := h.moviesRepo.Exists(c, id, viewer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie not found"))
+		return
+	}
+
+	ranked, err := h.similar.Find(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	ids := make([]int, 0, len(ranked))
+	for _, r := range ranked {
+		ids = append(ids, r.MovieId)
+	}
+	movies, err := h.moviesRepo.FindAllByIds(c, ids, viewer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	unwatched := make([]models.Movie, 0, len(movies))
+	for _, movie := range orderMoviesByIds(movies, ids) {
+		if !movie.IsWatched {
+			unwatched = append(unwatched, movie)
+		}
+	}
+	if len(unwatched) > limit {
+		unwatched = unwatched[:limit]
+	}
+
+	c.JSON(http.StatusOK, unwatched)
+}
+
 // Create godoc
 // @Summary      Create movie
 // @Tags         movies
@@ -113,9 +225,12 @@ func (h *MoviesHandler) FindAll(c *gin.Context) {
 // @Param        title formData string true "Title"
 // @Param        description formData string true "Description"
 // @Param        releaseYear formData int true "Year of release"
+// @Param        ageRating formData int true "Minimum viewer age (0, 6, 12, 16, 18)"
+// @Param        runtimeMinutes formData int false "Runtime in minutes"
 // @Param        director formData string true "Director"
 // @Param        trailerUrl formData string true "Trailer URL"
 // @Param        genreIds formData []int true "Genre ids"
+// @Param        tagIds formData []int false "Tag ids"
 // @Param        poster formData file true "Poster image"
 // @Success      200 {object} object{id=int} "OK"
 // @Failure      400 {object} models.ApiError "Invalid data"
@@ -131,12 +246,29 @@ func (h *MoviesHandler) Create(c *gin.Context) {
 		return
 	}
 
+	// Parental control fails closed: a movie is never visible to kids
+	// profiles just because nobody rated it.
+	if request.AgeRating == nil || !models.IsValidAgeRating(*request.AgeRating) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid age rating"))
+		return
+	}
+	if request.RuntimeMinutes < 0 {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid runtime"))
+		return
+	}
+
 	genres, err := h.genresRepo.FindAllByIds(c, request.GenreIds)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
 		return
 	}
 
+	tags, err := h.tagsRepo.FindAllByIds(c, request.TagIds)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
+		return
+	}
+
 	filename, err := h.saveMoviePoster(c, request.Poster)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
@@ -144,13 +276,16 @@ func (h *MoviesHandler) Create(c *gin.Context) {
 	}
 
 	movie := models.Movie{
-		Title:       request.Title,
-		Description: request.Description,
-		ReleaseYear: request.ReleaseYear,
-		Director:    request.Director,
-		TrailerUrl:  request.TrailerUrl,
-		PosterUrl:   filename,
-		Genres:      genres,
+		Title:          request.Title,
+		Description:    request.Description,
+		ReleaseYear:    request.ReleaseYear,
+		AgeRating:      *request.AgeRating,
+		RuntimeMinutes: request.RuntimeMinutes,
+		Director:       request.Director,
+		TrailerUrl:     request.TrailerUrl,
+		PosterUrl:      filename,
+		Genres:         genres,
+		Tags:           tags,
 	}
 
 	id, err := h.moviesRepo.Create(c, movie)
@@ -159,6 +294,9 @@ func (h *MoviesHandler) Create(c *gin.Context) {
 		return
 	}
 
+	h.similar.Invalidate()
+	h.broker.Publish(events.Event{Type: events.MovieCreated, Data: gin.H{"id": id}, AgeRating: movie.AgeRating})
+
 	logger := logger.GetLogger()
 	logger.Info("Movie has been created", zap.Int("movie_id", id))
 
@@ -184,9 +322,12 @@ func (h *MoviesHandler) saveMoviePoster(c *gin.Context, poster *multipart.FileHe
 // @Param        title formData string true "Title"
 // @Param        description formData string true "Description"
 // @Param        releaseYear formData int true "Year of release"
+// @Param        ageRating formData int false "Minimum viewer age (0, 6, 12, 16, 18); the current one is kept when omitted"
+// @Param        runtimeMinutes formData int false "Runtime in minutes"
 // @Param        director formData string true "Director"
 // @Param        trailerUrl formData string true "Trailer URL"
 // @Param        genreIds formData []int true "Genre ids"
+// @Param        tagIds formData []int false "Tag ids"
 // @Param        poster formData file true "Poster image"
 // @Success      200 {object} object{id=int} "OK"
 // @Failure      400 {object} models.ApiError "Invalid data"
@@ -201,7 +342,7 @@ func (h *MoviesHandler) Update(c *gin.Context) {
 		return
 	}
 
-	_, err = h.moviesRepo.FindById(c, id)
+	existing, err := h.moviesRepo.FindById(c, id, getViewer(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
 		return
@@ -214,12 +355,31 @@ func (h *MoviesHandler) Update(c *gin.Context) {
 		return
 	}
 
+	ageRating := existing.AgeRating
+	if request.AgeRating != nil {
+		ageRating = *request.AgeRating
+	}
+	if !models.IsValidAgeRating(ageRating) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid age rating"))
+		return
+	}
+	if request.RuntimeMinutes < 0 {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid runtime"))
+		return
+	}
+
 	genres, err := h.genresRepo.FindAllByIds(c, request.GenreIds)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
 		return
 	}
 
+	tags, err := h.tagsRepo.FindAllByIds(c, request.TagIds)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
+		return
+	}
+
 	filename, err := h.saveMoviePoster(c, request.Poster)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
@@ -227,19 +387,25 @@ func (h *MoviesHandler) Update(c *gin.Context) {
 	}
 
 	movie := models.Movie{
-		Title:       request.Title,
-		Description: request.Description,
-		ReleaseYear: request.ReleaseYear,
-		Director:    request.Director,
-		TrailerUrl:  request.TrailerUrl,
-		PosterUrl:   filename,
-		Genres:      genres,
+		Title:          request.Title,
+		Description:    request.Description,
+		ReleaseYear:    request.ReleaseYear,
+		AgeRating:      ageRating,
+		RuntimeMinutes: request.RuntimeMinutes,
+		Director:       request.Director,
+		TrailerUrl:     request.TrailerUrl,
+		PosterUrl:      filename,
+		Genres:         genres,
+		Tags:           tags,
 	}
 
 	err = h.moviesRepo.Update(c, id, movie)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, err)
+		return
 	}
+	h.similar.Invalidate()
+	h.broker.Publish(events.Event{Type: events.MovieUpdated, Data: gin.H{"id": id}, AgeRating: movie.AgeRating})
 
 	logger := logger.GetLogger()
 	logger.Info("Movie has been updated", zap.Int("movie_id", id))
@@ -266,7 +432,7 @@ func (h *MoviesHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	_, err = h.moviesRepo.FindById(c, id)
+	movie, err := h.moviesRepo.FindById(c, id, getViewer(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
 		return
@@ -275,7 +441,10 @@ func (h *MoviesHandler) Delete(c *gin.Context) {
 	err = h.moviesRepo.Delete(c, id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, err)
+		return
 	}
+	h.similar.Invalidate()
+	h.broker.Publish(events.Event{Type: events.MovieDeleted, Data: gin.H{"id": id}, AgeRating: movie.AgeRating})
 	logger := logger.GetLogger()
 	logger.Info("Movie has been deleted", zap.Int("movie_id", id))
 	c.Status(http.StatusOK)
@@ -290,14 +459,13 @@ func (h *MoviesHandler) Delete(c *gin.Context) {
 // @Param        rating query int true "Movie rating"
 // @Success      200 "OK"
 // @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "Movie not found"
 // @Failure      500 {object} models.ApiError
 // @Router       /movies/{movieId}/rate [patch]
 // @Security     Bearer
 func (h *MoviesHandler) HandleSetRating(c *gin.Context) {
-	idStr := c.Param("movieId")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+	id, ok := parseVisibleMovieParam(c, h.moviesRepo, "movieId")
+	if !ok {
 		return
 	}
 
@@ -308,11 +476,17 @@ func (h *MoviesHandler) HandleSetRating(c *gin.Context) {
 		return
 	}
 
-	err = h.moviesRepo.SetRating(c, id, rating)
+	err = h.moviesRepo.SetRating(c, c.GetInt("profileId"), id, rating)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
 		return
 	}
+	recordActivity(c, h.socialRepo, models.ActivityEvent{
+		ProfileId: c.GetInt("profileId"),
+		Type:      models.ActivityRated,
+		MovieId:   id,
+		Rating:    &rating,
+	})
 
 	logger := logger.GetLogger()
 	logger.Info("Could not set rating", zap.Int("movie_id", id))
@@ -321,6 +495,7 @@ func (h *MoviesHandler) HandleSetRating(c *gin.Context) {
 
 // HandleSetWatched godoc
 // @Summary      Mark movie as watched
+// @Description  A movie that becomes watched is taken off the watchlist when the profile has autoRemoveWatched enabled.
 // @Tags         movies
 // @Accept       json
 // @Produce      json
@@ -328,14 +503,13 @@ func (h *MoviesHandler) HandleSetRating(c *gin.Context) {
 // @Param        watched query bool true "Flag value"
 // @Success      200 "OK"
 // @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "Movie not found"
 // @Failure      500 {object} models.ApiError
 // @Router       /movies/{movieId}/setWatched [patch]
 // @Security     Bearer
 func (h *MoviesHandler) HandleSetWatched(c *gin.Context) {
-	idStr := c.Param("movieId")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+	id, ok := parseVisibleMovieParam(c, h.moviesRepo, "movieId")
+	if !ok {
 		return
 	}
 
@@ -346,7 +520,7 @@ func (h *MoviesHandler) HandleSetWatched(c *gin.Context) {
 		return
 	}
 
-	err = h.moviesRepo.SetWatched(c, id, isWatched)
+	err = h.watching.SetWatched(c, c.GetInt("profileId"), id, isWatched)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
 		return
@@ -354,3 +528,25 @@ func (h *MoviesHandler) HandleSetWatched(c *gin.Context) {
 
 	c.Status(http.StatusOK)
 }
+
+// parseTagIds parses the comma separated tags filter, dropping repeated ids.
+func parseTagIds(tags string) ([]int, error) {
+	if tags == "" {
+		return nil, nil
+	}
+
+	tagIds := make([]int, 0)
+	seen := make(map[int]bool)
+	for _, idStr := range strings.Split(tags, ",") {
+		tagId, err := strconv.Atoi(strings.TrimSpace(idStr))
+		if err != nil || tagId <= 0 {
+			return nil, fmt.Errorf("invalid tag id %q", idStr)
+		}
+		if seen[tagId] {
+			continue
+		}
+		seen[tagId] = true
+		tagIds = append(tagIds, tagId)
+	}
+	return tagIds, nil
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTagIds(t *testing.T) {
+	tests := []struct {
+		tags    string
+		want    []int
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"3", []int{3}, false},
+		{"3,1,2", []int{3, 1, 2}, false},
+		{" 3 , 1 ", []int{3, 1}, false},
+		{"3,1,3,1", []int{3, 1}, false},
+		{"abc", nil, true},
+		{"1,abc", nil, true},
+		{"1,,2", nil, true},
+		{"1,-2", nil, true},
+		{"0", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseTagIds(tt.tags)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTagIds(%q) error = %v, wantErr %v", tt.tags, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseTagIds(%q) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationsHandler struct {
+	notificationsRepo *repositories.NotificationsRepository
+	moviesRepo        *repositories.MoviesRepository
+}
+
+func NewNotificationsHandler(
+	notificationsRepo *repositories.NotificationsRepository,
+	moviesRepo *repositories.MoviesRepository) *NotificationsHandler {
+	return &NotificationsHandler{
+		notificationsRepo: notificationsRepo,
+		moviesRepo:        moviesRepo,
+	}
+}
+
+// HandleGetNotifications godoc
+// @Summary      Get notifications
+// @Description  Notifications of the current profile, newest first: trailer_added (a movie on the watchlist got a trailer), review_approved and new_release (a new movie in a genre the profile follows, see POST /genres/{id}/follow). Notifications are kept for NOTIFICATIONS_TTL.
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Param        unread query bool false "Only unread notifications"
+// @Param        page query int false "Page number" default(1)
+// @Param        pageSize query int false "Page size" default(20)
+// @Param        lang query string false "Language (kk, ru, en)"
+// @Success      200 {object} models.Page[models.Notification] "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/notifications [get]
+// @Security     Bearer
+func (h *NotificationsHandler) HandleGetNotifications(c *gin.Context) {
+	viewer := getViewer(c)
+	page, pageSize := getPagination(c)
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+	notifications, total, err := h.notificationsRepo.FindAll(c, viewer, unreadOnly, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	movieIds := make([]int, 0, len(notifications))
+	for _, notification := range notifications {
+		movieIds = append(movieIds, notification.MovieId)
+	}
+	movies, err := h.moviesRepo.FindAllByIds(c, movieIds, viewer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	byId := make(map[int]models.Movie, len(movies))
+	for _, movie := range movies {
+		byId[movie.Id] = movie
+	}
+	for i := range notifications {
+		notifications[i].Movie = byId[notifications[i].MovieId]
+	}
+
+	c.JSON(http.StatusOK, models.Page[models.Notification]{
+		Items:    notifications,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// HandleGetUnreadCount godoc
+// @Summary      Count unread notifications
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} models.UnreadCount "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/notifications/unread-count [get]
+// @Security     Bearer
+func (h *NotificationsHandler) HandleGetUnreadCount(c *gin.Context) {
+	count, err := h.notificationsRepo.CountUnread(c, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.UnreadCount{Unread: count})
+}
+
+// HandleMarkRead godoc
+// @Summary      Mark notification as read
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Notification id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid notification id"
+// @Failure      404 {object} models.ApiError "Notification not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/notifications/{id}/read [post]
+// @Security     Bearer
+func (h *NotificationsHandler) HandleMarkRead(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid notification id"))
+		return
+	}
+
+	found, err := h.notificationsRepo.MarkRead(c, id, c.GetInt("profileId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.NewApiError("Notification not found"))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleMarkAllRead godoc
+// @Summary      Mark all notifications as read
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Success      200 "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/notifications/read-all [post]
+// @Security     Bearer
+func (h *NotificationsHandler) HandleMarkAllRead(c *gin.Context) {
+	err := h.notificationsRepo.MarkAllRead(c, c.GetInt("profileId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
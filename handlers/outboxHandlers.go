@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OutboxHandler struct {
+	outboxRepo *repositories.OutboxRepository
+}
+
+func NewOutboxHandler(outboxRepo *repositories.OutboxRepository) *OutboxHandler {
+	return &OutboxHandler{outboxRepo: outboxRepo}
+}
+
+// HandleGetDeadEvents godoc
+// @Summary      Get dead outbox events
+// @Description  Events the outbox relay gave up on after OUTBOX_MAX_ATTEMPTS, the most recently failed first. LastError holds the error of the latest attempt, prefixed with the name of the failing sink. Admins only.
+// @Tags         outbox
+// @Accept       json
+// @Produce      json
+// @Param        page query int false "Page number" default(1)
+// @Param        pageSize query int false "Page size" default(20)
+// @Success      200 {object} models.Page[models.OutboxEvent] "OK"
+// @Failure      403 {object} models.ApiError "Not an admin"
+// @Failure      500 {object} models.ApiError
+// @Router       /admin/outbox/dead-events [get]
+// @Security     Bearer
+func (h *OutboxHandler) HandleGetDeadEvents(c *gin.Context) {
+	page, pageSize := getPagination(c)
+	events, total, err := h.outboxRepo.FindDead(c, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Page[models.OutboxEvent]{
+		Items:    events,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// HandleRetryDeadEvent godoc
+// @Summary      Retry dead outbox event
+// @Description  Hands the event back to the relay with a fresh set of attempts. It is published to every sink again, after the events pending by then. Admins only.
+// @Tags         outbox
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Outbox event id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid event id"
+// @Failure      403 {object} models.ApiError "Not an admin"
+// @Failure      404 {object} models.ApiError "Dead event not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /admin/outbox/dead-events/{id}/retry [post]
+// @Security     Bearer
+func (h *OutboxHandler) HandleRetryDeadEvent(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid event id"))
+		return
+	}
+
+	found, err := h.outboxRepo.Retry(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.NewApiError("Dead event not found"))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
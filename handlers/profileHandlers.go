@@ -0,0 +1,423 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var (
+	pinPattern    = regexp.MustCompile(`^[0-9]{4}$`)
+	errInvalidPin = errors.New("PIN must consist of 4 digits")
+)
+
+type ProfilesHandler struct {
+	repo *repositories.ProfilesRepository
+}
+
+func NewProfilesHandler(repo *repositories.ProfilesRepository) *ProfilesHandler {
+	return &ProfilesHandler{repo: repo}
+}
+
+type createProfileRequest struct {
+	Name         string `json:"name"`
+	IsKids       bool   `json:"isKids"`
+	MaxAgeRating int    `json:"maxAgeRating"`
+	Pin          string `json:"pin"`
+}
+
+// updateProfileRequest keeps the current PIN when Pin is omitted and removes it when Pin is empty.
+type updateProfileRequest struct {
+	Name              string  `json:"name"`
+	IsKids            bool    `json:"isKids"`
+	MaxAgeRating      int     `json:"maxAgeRating"`
+	Pin               *string `json:"pin"`
+	AutoRemoveWatched *bool   `json:"autoRemoveWatched"`
+}
+
+type selectProfileRequest struct {
+	Pin string `json:"pin"`
+}
+
+type uploadAvatarRequest struct {
+	Avatar *multipart.FileHeader `form:"avatar"`
+}
+
+type profileResponse struct {
+	Id                int    `json:"id"`
+	Name              string `json:"name"`
+	AvatarUrl         string `json:"avatarUrl"`
+	IsKids            bool   `json:"isKids"`
+	IsDefault         bool   `json:"isDefault"`
+	IsCurrent         bool   `json:"isCurrent"`
+	MaxAgeRating      int    `json:"maxAgeRating"`
+	HasPin            bool   `json:"hasPin"`
+	AutoRemoveWatched bool   `json:"autoRemoveWatched"`
+}
+
+func newProfileResponse(p models.Profile, currentProfileId int) profileResponse {
+	return profileResponse{
+		Id:                p.Id,
+		Name:              p.Name,
+		AvatarUrl:         p.AvatarUrl,
+		IsKids:            p.IsKids,
+		IsDefault:         p.IsDefault,
+		IsCurrent:         p.Id == currentProfileId,
+		MaxAgeRating:      p.MaxAgeRating,
+		HasPin:            p.HasPin(),
+		AutoRemoveWatched: p.AutoRemoveWatched,
+	}
+}
+
+// FindAll godoc
+// @Summary      Get profiles of the current user
+// @Tags         profiles
+// @Accept       json
+// @Produce      json
+// @Success      200 {array} profileResponse "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /profiles [get]
+// @Security     Bearer
+func (h *ProfilesHandler) FindAll(c *gin.Context) {
+	profiles, err := h.repo.FindAllByUserId(c, c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	dtos := make([]profileResponse, 0, len(profiles))
+	for _, p := range profiles {
+		dtos = append(dtos, newProfileResponse(p, c.GetInt("profileId")))
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+// Create godoc
+// @Summary      Create profile
+// @Tags         profiles
+// @Accept       json
+// @Produce      json
+// @Param        profile body createProfileRequest true "Profile to create"
+// @Success      200 {object} object{id=int} "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      403 {object} models.ApiError "Restricted profile"
+// @Failure      500 {object} models.ApiError
+// @Router       /profiles [post]
+// @Security     Bearer
+func (h *ProfilesHandler) Create(c *gin.Context) {
+	logger := logger.GetLogger()
+	if isRestrictedViewer(c) {
+		c.JSON(http.StatusForbidden, models.NewApiError("Profiles can not be managed from a restricted profile"))
+		return
+	}
+
+	var request createProfileRequest
+	err := c.BindJSON(&request)
+	if err != nil || request.Name == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+	if !models.IsValidAgeRating(request.MaxAgeRating) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid age rating"))
+		return
+	}
+
+	pinHash, err := hashPin(request.Pin)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
+		return
+	}
+
+	id, err := h.repo.Create(c, models.Profile{
+		UserId:       c.GetInt("userId"),
+		Name:         request.Name,
+		IsKids:       request.IsKids,
+		MaxAgeRating: kidsAgeRating(request.IsKids, request.MaxAgeRating),
+		PinHash:      pinHash,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError("could not create profile"))
+		return
+	}
+
+	logger.Info("Profile has been created", zap.Int("profile_id", id))
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+// Update godoc
+// @Summary      Update profile
+// @Tags         profiles
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Profile id"
+// @Param        profile body updateProfileRequest true "Updated profile data"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      403 {object} models.ApiError "Restricted profile"
+// @Failure      404 {object} models.ApiError "Profile not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /profiles/{id} [put]
+// @Security     Bearer
+func (h *ProfilesHandler) Update(c *gin.Context) {
+	if isRestrictedViewer(c) {
+		c.JSON(http.StatusForbidden, models.NewApiError("Profiles can not be managed from a restricted profile"))
+		return
+	}
+
+	profile, ok := h.findOwnProfile(c)
+	if !ok {
+		return
+	}
+
+	var request updateProfileRequest
+	err := c.BindJSON(&request)
+	if err != nil || request.Name == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+	if !models.IsValidAgeRating(request.MaxAgeRating) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid age rating"))
+		return
+	}
+
+	if profile.IsDefault && (request.IsKids || request.MaxAgeRating < models.MaxAgeRating) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Default profile can not be restricted"))
+		return
+	}
+
+	profile.Name = request.Name
+	profile.IsKids = request.IsKids
+	profile.MaxAgeRating = kidsAgeRating(request.IsKids, request.MaxAgeRating)
+	if request.Pin != nil {
+		profile.PinHash, err = hashPin(*request.Pin)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
+			return
+		}
+	}
+	if request.AutoRemoveWatched != nil {
+		profile.AutoRemoveWatched = *request.AutoRemoveWatched
+	}
+
+	err = h.repo.Update(c, profile.Id, profile)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// Delete godoc
+// @Summary      Delete profile
+// @Tags         profiles
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Profile id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid id"
+// @Failure      403 {object} models.ApiError "Restricted profile"
+// @Failure      404 {object} models.ApiError "Profile not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /profiles/{id} [delete]
+// @Security     Bearer
+func (h *ProfilesHandler) Delete(c *gin.Context) {
+	if isRestrictedViewer(c) {
+		c.JSON(http.StatusForbidden, models.NewApiError("Profiles can not be managed from a restricted profile"))
+		return
+	}
+
+	profile, ok := h.findOwnProfile(c)
+	if !ok {
+		return
+	}
+	if profile.IsDefault {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Default profile can not be deleted"))
+		return
+	}
+
+	err := h.repo.Delete(c, profile.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleUploadAvatar godoc
+// @Summary      Upload profile avatar
+// @Tags         profiles
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id path int true "Profile id"
+// @Param        avatar formData file true "Avatar image"
+// @Success      200 {object} object{avatarUrl=string} "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      403 {object} models.ApiError "Restricted profile"
+// @Failure      404 {object} models.ApiError "Profile not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /profiles/{id}/avatar [post]
+// @Security     Bearer
+func (h *ProfilesHandler) HandleUploadAvatar(c *gin.Context) {
+	if isRestrictedViewer(c) {
+		c.JSON(http.StatusForbidden, models.NewApiError("Profiles can not be managed from a restricted profile"))
+		return
+	}
+
+	profile, ok := h.findOwnProfile(c)
+	if !ok {
+		return
+	}
+
+	var request uploadAvatarRequest
+	err := c.Bind(&request)
+	if err != nil || request.Avatar == nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Could not bind payload"))
+		return
+	}
+
+	filename := fmt.Sprintf("%s%s", uuid.NewString(), filepath.Ext(request.Avatar.Filename))
+	err = c.SaveUploadedFile(request.Avatar, fmt.Sprintf("images/%s", filename))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	err = h.repo.SetAvatar(c, profile.Id, filename)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"avatarUrl": filename})
+}
+
+// HandleSelect godoc
+// @Summary      Switch to profile
+// @Description  Returns a token scoped to the profile. PIN is required when the profile has one.
+// @Tags         profiles
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Profile id"
+// @Param        request body selectProfileRequest false "Profile PIN"
+// @Success      200 {object} object{token=string} "OK"
+// @Failure      400 {object} models.ApiError "Invalid id"
+// @Failure      401 {object} models.ApiError "Invalid PIN"
+// @Failure      404 {object} models.ApiError "Profile not found"
+// @Failure      429 {object} models.ApiError "Too many wrong PINs"
+// @Header       429 {integer} Retry-After "Seconds until the profile can be selected again"
+// @Failure      500 {object} models.ApiError
+// @Router       /profiles/{id}/select [post]
+// @Security     Bearer
+func (h *ProfilesHandler) HandleSelect(c *gin.Context) {
+	logger := logger.GetLogger()
+	profile, ok := h.findOwnProfile(c)
+	if !ok {
+		return
+	}
+
+	var request selectProfileRequest
+	_ = c.ShouldBindJSON(&request)
+	if profile.HasPin() {
+		lockedFor, err := h.repo.PinLockRemaining(c, profile.Id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+			return
+		}
+		if lockedFor > 0 {
+			abortPinLocked(c, lockedFor)
+			return
+		}
+
+		err = bcrypt.CompareHashAndPassword([]byte(profile.PinHash), []byte(request.Pin))
+		if err != nil {
+			logger.Warn("Invalid profile PIN", zap.Int("profile_id", profile.Id))
+			lockedFor, err = h.repo.RecordPinFailure(c, profile.Id)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+				return
+			}
+			if lockedFor > 0 {
+				abortPinLocked(c, lockedFor)
+				return
+			}
+			c.JSON(http.StatusUnauthorized, models.NewApiError("Invalid PIN"))
+			return
+		}
+
+		err = h.repo.ResetPinFailures(c, profile.Id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+			return
+		}
+	}
+
+	tokenString, err := issueToken(profile.UserId, profile.Id)
+	if err != nil {
+		logger.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, models.NewApiError("Couldn't sign JWT"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": tokenString})
+}
+
+func abortPinLocked(c *gin.Context, lockedFor time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedFor.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, models.NewApiError("Too many wrong PINs, try again later"))
+}
+
+func (h *ProfilesHandler) findOwnProfile(c *gin.Context) (models.Profile, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid profile id"))
+		return models.Profile{}, false
+	}
+
+	profile, err := h.repo.FindById(c, id)
+	if err != nil || profile.UserId != c.GetInt("userId") {
+		c.JSON(http.StatusNotFound, models.NewApiError("Profile not found"))
+		return models.Profile{}, false
+	}
+	return profile, true
+}
+
+func kidsAgeRating(isKids bool, maxAgeRating int) int {
+	if isKids && maxAgeRating > models.KidsMaxAgeRating {
+		return models.KidsMaxAgeRating
+	}
+	return maxAgeRating
+}
+
+func isRestrictedViewer(c *gin.Context) bool {
+	return c.GetInt("maxAgeRating") < models.MaxAgeRating
+}
+
+func hashPin(pin string) (string, error) {
+	if pin == "" {
+		return "", nil
+	}
+	if !pinPattern.MatchString(pin) {
+		return "", errInvalidPin
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"goozinshe/models"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestKidsAgeRating(t *testing.T) {
+	tests := []struct {
+		isKids       bool
+		maxAgeRating int
+		want         int
+	}{
+		{false, 18, 18},
+		{false, 12, 12},
+		{false, 0, 0},
+		{true, 18, models.KidsMaxAgeRating},
+		{true, 12, models.KidsMaxAgeRating},
+		{true, 6, 6},
+		{true, 0, 0},
+	}
+	for _, tt := range tests {
+		got := kidsAgeRating(tt.isKids, tt.maxAgeRating)
+		if got != tt.want {
+			t.Errorf("kidsAgeRating(%v, %d) = %d, want %d", tt.isKids, tt.maxAgeRating, got, tt.want)
+		}
+	}
+}
+
+func TestIsRestrictedViewer(t *testing.T) {
+	tests := []struct {
+		maxAgeRating int
+		want         bool
+	}{
+		{18, false},
+		{16, true},
+		{6, true},
+		{0, true},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("maxAgeRating", tt.maxAgeRating)
+		got := isRestrictedViewer(c)
+		if got != tt.want {
+			t.Errorf("isRestrictedViewer with maxAgeRating %d = %v, want %v", tt.maxAgeRating, got, tt.want)
+		}
+		if getViewer(c).MaxAgeRating != tt.maxAgeRating {
+			t.Errorf("getViewer().MaxAgeRating = %d, want %d", getViewer(c).MaxAgeRating, tt.maxAgeRating)
+		}
+	}
+}
+
+func TestIsValidAgeRating(t *testing.T) {
+	for _, rating := range models.AgeRatings {
+		if !models.IsValidAgeRating(rating) {
+			t.Errorf("IsValidAgeRating(%d) = false, want true", rating)
+		}
+	}
+	for _, rating := range []int{-1, 3, 13, 21} {
+		if models.IsValidAgeRating(rating) {
+			t.Errorf("IsValidAgeRating(%d) = true, want false", rating)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"go.uber.org/zap"
+	"goozinshe/config"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"goozinshe/watching"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultContinueWatchingLimit = 20
+	defaultWatchedThreshold      = 0.9
+)
+
+type ProgressHandler struct {
+	moviesRepo   *repositories.MoviesRepository
+	progressRepo *repositories.ProgressRepository
+	watching     *watching.Service
+}
+
+func NewProgressHandler(
+	moviesRepo *repositories.MoviesRepository,
+	progressRepo *repositories.ProgressRepository,
+	watching *watching.Service) *ProgressHandler {
+	return &ProgressHandler{
+		moviesRepo:   moviesRepo,
+		progressRepo: progressRepo,
+		watching:     watching,
+	}
+}
+
+type saveProgressRequest struct {
+	PositionSeconds int `json:"positionSeconds"`
+	DurationSeconds int `json:"durationSeconds"`
+}
+
+// HandleSaveProgress godoc
+// @Summary      Save playback position
+// @Description  Meant to be called as a player heartbeat. Marks the movie as watched once the position passes the configured share of its duration.
+// @Tags         progress
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Param        progress body saveProgressRequest true "Playback position"
+// @Success      200 {object} models.PlaybackProgress "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "Movie not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /movies/{id}/progress [put]
+// @Security     Bearer
+func (h *ProgressHandler) HandleSaveProgress(c *gin.Context) {
+	logger := logger.GetLogger()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+		return
+	}
+
+	var request saveProgressRequest
+	err = c.BindJSON(&request)
+	if err != nil || request.PositionSeconds < 0 || request.DurationSeconds <= 0 {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+	if request.PositionSeconds > request.DurationSeconds {
+		request.PositionSeconds = request.DurationSeconds
+	}
+
+	viewer := getViewer(c)
+	exists, err := h.moviesRepo.Exists(c, id, viewer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie not found"))
+		return
+	}
+
+	threshold := config.Config.WatchedThreshold
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultWatchedThreshold
+	}
+	progress := models.PlaybackProgress{
+		MovieId:         id,
+		PositionSeconds: request.PositionSeconds,
+		DurationSeconds: request.DurationSeconds,
+		Completed:       float64(request.PositionSeconds) >= threshold*float64(request.DurationSeconds),
+	}
+	justCompleted, err := h.progressRepo.Save(c, viewer.ProfileId, progress)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	if justCompleted {
+		err = h.watching.SetWatched(c, viewer.ProfileId, id, true)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+			return
+		}
+		logger.Info("Movie has been watched to the end", zap.Int("movie_id", id), zap.Int("profile_id", viewer.ProfileId))
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// HandleGetProgress godoc
+// @Summary      Get playback position
+// @Tags         progress
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Success      200 {object} models.PlaybackProgress "OK"
+// @Failure      400 {object} models.ApiError "Invalid movie id"
+// @Failure      404 {object} models.ApiError "No progress for the movie"
+// @Router       /movies/{id}/progress [get]
+// @Security     Bearer
+func (h *ProgressHandler) HandleGetProgress(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+		return
+	}
+
+	progress, err := h.progressRepo.FindByMovieId(c, c.GetInt("profileId"), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Progress not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// HandleContinueWatching godoc
+// @Summary      Get "continue watching" row
+// @Description  Started but unfinished movies, most recently watched first.
+// @Tags         progress
+// @Accept       json
+// @Produce      json
+// @Param        limit query int false "Maximum number of movies" default(20)
+// @Param        lang query string false "Language (kk, ru, en)"
+// @Success      200 {array} models.ContinueWatchingEntry "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/continue-watching [get]
+// @Security     Bearer
+func (h *ProgressHandler) HandleContinueWatching(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultContinueWatchingLimit)))
+	if err != nil || limit <= 0 {
+		limit = defaultContinueWatchingLimit
+	}
+
+	viewer := getViewer(c)
+	progress, err := h.progressRepo.FindUnfinished(c, viewer.ProfileId, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	movieIds := make([]int, 0, len(progress))
+	for _, p := range progress {
+		movieIds = append(movieIds, p.MovieId)
+	}
+	movies, err := h.moviesRepo.FindAllByIds(c, movieIds, viewer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	moviesById := make(map[int]models.Movie, len(movies))
+	for _, m := range movies {
+		moviesById[m.Id] = m
+	}
+	entries := make([]models.ContinueWatchingEntry, 0, len(progress))
+	for _, p := range progress {
+		if m, ok := moviesById[p.MovieId]; ok {
+			entries = append(entries, models.ContinueWatchingEntry{Movie: m, Progress: p})
+		}
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"goozinshe/models"
+	"goozinshe/recommendations"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRecommendationsLimit = 20
+	maxRecommendationsLimit     = 100
+)
+
+type RecommendationsHandler struct {
+	recommender *recommendations.Recommender
+}
+
+func NewRecommendationsHandler(recommender *recommendations.Recommender) *RecommendationsHandler {
+	return &RecommendationsHandler{recommender: recommender}
+}
+
+// HandleGetRecommendations godoc
+// @Summary      Get personal recommendations
+// @Description  Movies liked by profiles with a similar taste, each with a reason code, the id of the movie or genre it is based on and an explanation in the requested language. Profiles without enough ratings get popular movies from their favourite genres.
+// @Tags         recommendations
+// @Accept       json
+// @Produce      json
+// @Param        limit query int false "Maximum number of movies" default(20)
+// @Param        lang query string false "Language (kk, ru, en)"
+// @Success      200 {array} models.Recommendation "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/recommendations [get]
+// @Security     Bearer
+func (h *RecommendationsHandler) HandleGetRecommendations(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultRecommendationsLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultRecommendationsLimit
+	}
+	if limit > maxRecommendationsLimit {
+		limit = maxRecommendationsLimit
+	}
+
+	suggestions, err := h.recommender.ForViewer(c, getViewer(c), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
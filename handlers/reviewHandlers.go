@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxReviewTitleLength = 200
+	maxReviewBodyLength  = 10000
+)
+
+type ReviewsHandler struct {
+	reviewsRepo *repositories.ReviewsRepository
+	moviesRepo  *repositories.MoviesRepository
+	socialRepo  *repositories.SocialRepository
+}
+
+func NewReviewsHandler(
+	reviewsRepo *repositories.ReviewsRepository,
+	moviesRepo *repositories.MoviesRepository,
+	socialRepo *repositories.SocialRepository) *ReviewsHandler {
+	return &ReviewsHandler{
+		reviewsRepo: reviewsRepo,
+		moviesRepo:  moviesRepo,
+		socialRepo:  socialRepo,
+	}
+}
+
+type reviewRequest struct {
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	IsSpoiler bool   `json:"isSpoiler"`
+}
+
+type rejectReviewRequest struct {
+	Reason string `json:"reason"`
+}
+
+// HandleGetMovieReviews godoc
+// @Summary      Get movie reviews
+// @Description  Approved reviews of the movie.
+// @Tags         reviews
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Param        sort query string false "newest (default) or helpful"
+// @Param        page query int false "Page number" default(1)
+// @Param        pageSize query int false "Page size" default(20)
+// @Success      200 {object} models.Page[models.Review] "OK"
+// @Failure      400 {object} models.ApiError "Invalid movie id"
+// @Failure      404 {object} models.ApiError "Movie not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /movies/{id}/reviews [get]
+// @Security     Bearer
+func (h *ReviewsHandler) HandleGetMovieReviews(c *gin.Context) {
+	movieId, ok := parseVisibleMovieId(c, h.moviesRepo)
+	if !ok {
+		return
+	}
+
+	page, pageSize := getPagination(c)
+	reviews, total, err := h.reviewsRepo.FindApprovedByMovieId(c, movieId, c.Query("sort"), pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Page[models.Review]{
+		Items:    reviews,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// HandleGetMyReview godoc
+// @Summary      Get my review of the movie
+// @Description  The current profile's review in any moderation status.
+// @Tags         reviews
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Success      200 {object} models.Review "OK"
+// @Failure      400 {object} models.ApiError "Invalid movie id"
+// @Failure      404 {object} models.ApiError "Review not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /movies/{id}/review [get]
+// @Security     Bearer
+func (h *ReviewsHandler) HandleGetMyReview(c *gin.Context) {
+	review, ok := h.findMyReview(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// HandleSaveMyReview godoc
+// @Summary      Write or edit my review of the movie
+// @Description  A profile has at most one review per movie. Editing keeps the previous text in the history; new and edited reviews wait for moderation.
+// @Tags         reviews
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Param        request body reviewRequest true "Review"
+// @Success      200 {object} object{id=int} "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "Movie not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /movies/{id}/review [put]
+// @Security     Bearer
+func (h *ReviewsHandler) HandleSaveMyReview(c *gin.Context) {
+	movieId, ok := parseVisibleMovieId(c, h.moviesRepo)
+	if !ok {
+		return
+	}
+
+	var request reviewRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+	request.Title = strings.TrimSpace(request.Title)
+	request.Body = strings.TrimSpace(request.Body)
+	if request.Title == "" || utf8.RuneCountInString(request.Title) > maxReviewTitleLength {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid review title"))
+		return
+	}
+	if request.Body == "" || utf8.RuneCountInString(request.Body) > maxReviewBodyLength {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid review body"))
+		return
+	}
+
+	id, err := h.reviewsRepo.Save(c, models.Review{
+		MovieId:   movieId,
+		ProfileId: c.GetInt("profileId"),
+		Title:     request.Title,
+		Body:      request.Body,
+		IsSpoiler: request.IsSpoiler,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	logger := logger.GetLogger()
+	logger.Info("Review has been saved", zap.Int("review_id", id))
+
+	c.JSON(http.StatusOK, gin.H{
+		"id": id,
+	})
+}
+
+// HandleDeleteMyReview godoc
+// @Summary      Delete my review of the movie
+// @Tags         reviews
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid movie id"
+// @Failure      404 {object} models.ApiError "Review not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /movies/{id}/review [delete]
+// @Security     Bearer
+func (h *ReviewsHandler) HandleDeleteMyReview(c *gin.Context) {
+	review, ok := h.findMyReview(c)
+	if !ok {
+		return
+	}
+
+	err := h.reviewsRepo.Delete(c, review.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleGetMyReviewHistory godoc
+// @Summary      Get edit history of my review
+// @Tags         reviews
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Success      200 {array} models.ReviewRevision "OK"
+// @Failure      400 {object} models.ApiError "Invalid movie id"
+// @Failure      404 {object} models.ApiError "Review not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /movies/{id}/review/history [get]
+// @Security     Bearer
+func (h *ReviewsHandler) HandleGetMyReviewHistory(c *gin.Context) {
+	review, ok := h.findMyReview(c)
+	if !ok {
+		return
+	}
+
+	h.respondWithRevisions(c, review.Id)
+}
+
+// HandleMarkHelpful godoc
+// @Summary      Mark review as helpful
+// @Tags         reviews
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Review id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid review id or own review"
+// @Failure      404 {object} models.ApiError "Review not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /reviews/{id}/helpful [post]
+// @Security     Bearer
+func (h *ReviewsHandler) HandleMarkHelpful(c *gin.Context) {
+	h.setHelpful(c, true)
+}
+
+// HandleUnmarkHelpful godoc
+// @Summary      Withdraw helpful vote
+// @Tags         reviews
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Review id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid review id"
+// @Failure      404 {object} models.ApiError "Review not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /reviews/{id}/helpful [delete]
+// @Security     Bearer
+func (h *ReviewsHandler) HandleUnmarkHelpful(c *gin.Context) {
+	h.setHelpful(c, false)
+}
+
+// HandleGetModerationQueue godoc
+// @Summary      Get reviews awaiting moderation
+// @Description  Pending reviews, oldest changes first. Editors and admins only.
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        page query int false "Page number" default(1)
+// @Param        pageSize query int false "Page size" default(20)
+// @Success      200 {object} models.Page[models.Review] "OK"
+// @Failure      403 {object} models.ApiError "Not an editor"
+// @Failure      500 {object} models.ApiError
+// @Router       /moderation/reviews [get]
+// @Security     Bearer
+func (h *ReviewsHandler) HandleGetModerationQueue(c *gin.Context) {
+	page, pageSize := getPagination(c)
+	reviews, total, err := h.reviewsRepo.FindPending(c, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Page[models.Review]{
+		Items:    reviews,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// HandleGetReviewHistory godoc
+// @Summary      Get edit history of a review
+// @Description  Editors and admins only.
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Review id"
+// @Success      200 {array} models.ReviewRevision "OK"
+// @Failure      400 {object} models.ApiError "Invalid review id"
+// @Failure      403 {object} models.ApiError "Not an editor"
+// @Failure      404 {object} models.ApiError "Review not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /moderation/reviews/{id}/history [get]
+// @Security     Bearer
+func (h *ReviewsHandler) HandleGetReviewHistory(c *gin.Context) {
+	review, ok := h.findReview(c)
+	if !ok {
+		return
+	}
+
+	h.respondWithRevisions(c, review.Id)
+}
+
+// HandleApprove godoc
+// @Summary      Approve review
+// @Description  Editors and admins only.
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Review id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid review id"
+// @Failure      403 {object} models.ApiError "Not an editor"
+// @Failure      404 {object} models.ApiError "Review not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /moderation/reviews/{id}/approve [post]
+// @Security     Bearer
+func (h *ReviewsHandler) HandleApprove(c *gin.Context) {
+	review, ok := h.findReview(c)
+	if !ok {
+		return
+	}
+
+	h.moderate(c, review, models.ReviewStatusApproved, "")
+}
+
+// HandleReject godoc
+// @Summary      Reject review
+// @Description  The reason is shown to the author. Editors and admins only.
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Review id"
+// @Param        request body rejectReviewRequest true "Rejection reason"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid review id or missing reason"
+// @Failure      403 {object} models.ApiError "Not an editor"
+// @Failure      404 {object} models.ApiError "Review not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /moderation/reviews/{id}/reject [post]
+// @Security     Bearer
+func (h *ReviewsHandler) HandleReject(c *gin.Context) {
+	review, ok := h.findReview(c)
+	if !ok {
+		return
+	}
+
+	var request rejectReviewRequest
+	if err := c.BindJSON(&request); err != nil || strings.TrimSpace(request.Reason) == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Rejection reason is required"))
+		return
+	}
+
+	h.moderate(c, review, models.ReviewStatusRejected, strings.TrimSpace(request.Reason))
+}
+
+func (h *ReviewsHandler) moderate(c *gin.Context, review models.Review, status string, reason string) {
+	err := h.reviewsRepo.Moderate(c, review.Id, status, reason, c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	if status == models.ReviewStatusApproved {
+		recordActivity(c, h.socialRepo, models.ActivityEvent{
+			ProfileId: review.ProfileId,
+			Type:      models.ActivityReviewed,
+			MovieId:   review.MovieId,
+			ReviewId:  &review.Id,
+		})
+	}
+
+	logger := logger.GetLogger()
+	logger.Info("Review has been moderated", zap.Int("review_id", review.Id), zap.String("status", status))
+	c.Status(http.StatusOK)
+}
+
+func (h *ReviewsHandler) setHelpful(c *gin.Context, helpful bool) {
+	review, ok := h.findReview(c)
+	if !ok {
+		return
+	}
+	if review.Status != models.ReviewStatusApproved {
+		c.JSON(http.StatusNotFound, models.NewApiError("Review not found"))
+		return
+	}
+	if review.ProfileId == c.GetInt("profileId") {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Own review can not be voted for"))
+		return
+	}
+
+	err := h.reviewsRepo.SetHelpful(c, review.Id, c.GetInt("profileId"), helpful)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *ReviewsHandler) respondWithRevisions(c *gin.Context, id int) {
+	revisions, err := h.reviewsRepo.FindRevisions(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+func (h *ReviewsHandler) findMyReview(c *gin.Context) (models.Review, bool) {
+	movieId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+		return models.Review{}, false
+	}
+
+	review, err := h.reviewsRepo.FindByProfileAndMovie(c, c.GetInt("profileId"), movieId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Review not found"))
+		return models.Review{}, false
+	}
+	return review, true
+}
+
+func (h *ReviewsHandler) findReview(c *gin.Context) (models.Review, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Review Id"))
+		return models.Review{}, false
+	}
+
+	review, err := h.reviewsRepo.FindById(c, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Review not found"))
+		return models.Review{}, false
+	}
+	return review, true
+}
@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SocialHandler struct {
+	socialRepo   *repositories.SocialRepository
+	profilesRepo *repositories.ProfilesRepository
+	moviesRepo   *repositories.MoviesRepository
+}
+
+func NewSocialHandler(
+	socialRepo *repositories.SocialRepository,
+	profilesRepo *repositories.ProfilesRepository,
+	moviesRepo *repositories.MoviesRepository) *SocialHandler {
+	return &SocialHandler{
+		socialRepo:   socialRepo,
+		profilesRepo: profilesRepo,
+		moviesRepo:   moviesRepo,
+	}
+}
+
+type privacyRequest struct {
+	PublishRatings *bool `json:"publishRatings"`
+	PublishWatched *bool `json:"publishWatched"`
+	PublishLists   *bool `json:"publishLists"`
+	PublishReviews *bool `json:"publishReviews"`
+}
+
+func (r privacyRequest) applyTo(settings models.PrivacySettings) models.PrivacySettings {
+	if r.PublishRatings != nil {
+		settings.PublishRatings = *r.PublishRatings
+	}
+	if r.PublishWatched != nil {
+		settings.PublishWatched = *r.PublishWatched
+	}
+	if r.PublishLists != nil {
+		settings.PublishLists = *r.PublishLists
+	}
+	if r.PublishReviews != nil {
+		settings.PublishReviews = *r.PublishReviews
+	}
+	return settings
+}
+
+// HandleFollow godoc
+// @Summary      Follow profile
+// @Description  Activity of followed profiles shows up in the feed. Kids profiles can neither follow nor be followed.
+// @Tags         social
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Profile id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid profile id or own profile"
+// @Failure      403 {object} models.ApiError "Kids profile"
+// @Failure      404 {object} models.ApiError "Profile not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /follows/{id} [post]
+// @Security     Bearer
+func (h *SocialHandler) HandleFollow(c *gin.Context) {
+	followeeId, ok := h.parseFolloweeId(c)
+	if !ok {
+		return
+	}
+	if c.GetInt("maxAgeRating") < models.MaxAgeRating {
+		c.JSON(http.StatusForbidden, models.NewApiError("Kids profiles can not follow"))
+		return
+	}
+
+	followee, err := h.profilesRepo.FindById(c, followeeId)
+	if err != nil || followee.IsKids {
+		c.JSON(http.StatusNotFound, models.NewApiError("Profile not found"))
+		return
+	}
+
+	err = h.socialRepo.Follow(c, c.GetInt("profileId"), followeeId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleUnfollow godoc
+// @Summary      Unfollow profile
+// @Tags         social
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Profile id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid profile id"
+// @Failure      500 {object} models.ApiError
+// @Router       /follows/{id} [delete]
+// @Security     Bearer
+func (h *SocialHandler) HandleUnfollow(c *gin.Context) {
+	followeeId, ok := h.parseFolloweeId(c)
+	if !ok {
+		return
+	}
+
+	err := h.socialRepo.Unfollow(c, c.GetInt("profileId"), followeeId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleGetFollowing godoc
+// @Summary      Get followed profiles
+// @Tags         social
+// @Accept       json
+// @Produce      json
+// @Param        page query int false "Page number" default(1)
+// @Param        pageSize query int false "Page size" default(20)
+// @Success      200 {object} models.Page[models.Follow] "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/following [get]
+// @Security     Bearer
+func (h *SocialHandler) HandleGetFollowing(c *gin.Context) {
+	page, pageSize := getPagination(c)
+	follows, total, err := h.socialRepo.FindFollowing(c, c.GetInt("profileId"), pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Page[models.Follow]{
+		Items:    follows,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// HandleGetFollowers godoc
+// @Summary      Get followers
+// @Tags         social
+// @Accept       json
+// @Produce      json
+// @Param        page query int false "Page number" default(1)
+// @Param        pageSize query int false "Page size" default(20)
+// @Success      200 {object} models.Page[models.Follow] "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/followers [get]
+// @Security     Bearer
+func (h *SocialHandler) HandleGetFollowers(c *gin.Context) {
+	page, pageSize := getPagination(c)
+	follows, total, err := h.socialRepo.FindFollowers(c, c.GetInt("profileId"), pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Page[models.Follow]{
+		Items:    follows,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// HandleGetPrivacy godoc
+// @Summary      Get privacy settings
+// @Description  Which activity of the current profile its followers see.
+// @Tags         social
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} models.PrivacySettings "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/privacy [get]
+// @Security     Bearer
+func (h *SocialHandler) HandleGetPrivacy(c *gin.Context) {
+	settings, err := h.socialRepo.GetPrivacy(c, c.GetInt("profileId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// HandleSetPrivacy godoc
+// @Summary      Change privacy settings
+// @Description  Only the fields present in the request are changed. Settings apply to past activity as well.
+// @Tags         social
+// @Accept       json
+// @Produce      json
+// @Param        request body privacyRequest true "Privacy settings"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/privacy [patch]
+// @Security     Bearer
+func (h *SocialHandler) HandleSetPrivacy(c *gin.Context) {
+	var request privacyRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+
+	settings, err := h.socialRepo.GetPrivacy(c, c.GetInt("profileId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	err = h.socialRepo.SetPrivacy(c, c.GetInt("profileId"), request.applyTo(settings))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleGetFeed godoc
+// @Summary      Get activity feed
+// @Description  Ratings, watched movies, list additions and reviews of followed profiles, newest first.
+// @Tags         social
+// @Accept       json
+// @Produce      json
+// @Param        page query int false "Page number" default(1)
+// @Param        pageSize query int false "Page size" default(20)
+// @Param        lang query string false "Language (kk, ru, en)"
+// @Success      200 {object} models.Page[models.FeedItem] "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/feed [get]
+// @Security     Bearer
+func (h *SocialHandler) HandleGetFeed(c *gin.Context) {
+	viewer := getViewer(c)
+	page, pageSize := getPagination(c)
+	items, total, err := h.socialRepo.FindFeed(c, viewer, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	movieIds := make([]int, 0, len(items))
+	for _, item := range items {
+		movieIds = append(movieIds, item.MovieId)
+	}
+	movies, err := h.moviesRepo.FindAllByIds(c, movieIds, viewer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	byId := make(map[int]models.Movie, len(movies))
+	for _, movie := range movies {
+		byId[movie.Id] = movie
+	}
+	for i := range items {
+		items[i].Movie = byId[items[i].MovieId]
+	}
+
+	c.JSON(http.StatusOK, models.Page[models.FeedItem]{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+func (h *SocialHandler) parseFolloweeId(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Profile Id"))
+		return 0, false
+	}
+	if id == c.GetInt("profileId") {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Profile can not follow itself"))
+		return 0, false
+	}
+	return id, true
+}
+
+// recordActivity publishes the event to the profile's followers. Failures
+// are logged and do not fail the request that caused the event.
+func recordActivity(c *gin.Context, socialRepo *repositories.SocialRepository, event models.ActivityEvent) {
+	err := socialRepo.RecordActivity(c, event)
+	if err != nil {
+		logger := logger.GetLogger()
+		logger.Warn("Activity has not been recorded", zap.String("type", event.Type), zap.Error(err))
+	}
+}
+
+// retractActivity removes the event of an action the profile has undone.
+func retractActivity(c *gin.Context, socialRepo *repositories.SocialRepository, event models.ActivityEvent) {
+	err := socialRepo.DeleteActivity(c, event)
+	if err != nil {
+		logger := logger.GetLogger()
+		logger.Warn("Activity has not been retracted", zap.String("type", event.Type), zap.Error(err))
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatsHandler struct {
+	statsRepo *repositories.StatsRepository
+}
+
+func NewStatsHandler(statsRepo *repositories.StatsRepository) *StatsHandler {
+	return &StatsHandler{statsRepo: statsRepo}
+}
+
+// HandleGetMyStats godoc
+// @Summary      Get viewing statistics
+// @Description  Views and minutes watched by year and month, top genres and directors and ratings given by the current profile.
+// @Tags         stats
+// @Accept       json
+// @Produce      json
+// @Param        year query int false "Only count views from this year and ratings of the movies watched in it"
+// @Param        lang query string false "Language (kk, ru, en)"
+// @Success      200 {object} models.ViewingStats "OK"
+// @Failure      400 {object} models.ApiError "Invalid year"
+// @Failure      500 {object} models.ApiError
+// @Router       /me/stats [get]
+// @Security     Bearer
+func (h *StatsHandler) HandleGetMyStats(c *gin.Context) {
+	filter, ok := h.parseFilter(c)
+	if !ok {
+		return
+	}
+	filter.ProfileId = getViewer(c).ProfileId
+
+	h.respond(c, filter)
+}
+
+// HandleGetAllStats godoc
+// @Summary      Get platform viewing statistics
+// @Description  The same figures as /me/stats aggregated across all users. Admins only.
+// @Tags         stats
+// @Accept       json
+// @Produce      json
+// @Param        year query int false "Only count views from this year and ratings of the movies watched in it"
+// @Param        lang query string false "Language (kk, ru, en)"
+// @Success      200 {object} models.ViewingStats "OK"
+// @Failure      400 {object} models.ApiError "Invalid year"
+// @Failure      403 {object} models.ApiError "Not an admin"
+// @Failure      500 {object} models.ApiError
+// @Router       /admin/stats [get]
+// @Security     Bearer
+func (h *StatsHandler) HandleGetAllStats(c *gin.Context) {
+	filter, ok := h.parseFilter(c)
+	if !ok {
+		return
+	}
+
+	h.respond(c, filter)
+}
+
+func (h *StatsHandler) parseFilter(c *gin.Context) (models.StatsFilter, bool) {
+	filter := models.StatsFilter{Language: c.GetString("lang")}
+	if yearStr := c.Query("year"); yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil || year <= 0 {
+			c.JSON(http.StatusBadRequest, models.NewApiError("Invalid year"))
+			return models.StatsFilter{}, false
+		}
+		filter.Year = year
+	}
+	return filter, true
+}
+
+func (h *StatsHandler) respond(c *gin.Context, filter models.StatsFilter) {
+	stats, err := h.statsRepo.GetStats(c, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/recommendations"
+	"goozinshe/repositories"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagsHandler struct {
+	repo    *repositories.TagsRepository
+	similar *recommendations.SimilarMovies
+}
+
+func NewTagsHandler(repo *repositories.TagsRepository, similar *recommendations.SimilarMovies) *TagsHandler {
+	return &TagsHandler{repo: repo, similar: similar}
+}
+
+type tagRequest struct {
+	Name string `json:"name"`
+}
+
+type mergeTagsRequest struct {
+	SourceIds []int `json:"sourceIds"`
+}
+
+// FindAll godoc
+// @Summary      Get all tags with usage counts
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        search query string false "Part of the tag name"
+// @Success      200 {array} models.TagUsage "OK"
+// @Failure      500 {object} models.ApiError
+// @Router       /tags [get]
+// @Security     Bearer
+func (h *TagsHandler) FindAll(c *gin.Context) {
+	tags, err := h.repo.FindAll(c, c.Query("search"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// FindById godoc
+// @Summary      Find tag by id
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Tag id"
+// @Success      200 {object} models.TagUsage "OK"
+// @Failure      400 {object} models.ApiError "Invalid tag id"
+// @Failure      404 {object} models.ApiError "Tag not found"
+// @Router       /tags/{id} [get]
+// @Security     Bearer
+func (h *TagsHandler) FindById(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Tag Id"))
+		return
+	}
+
+	tag, err := h.repo.FindById(c, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Tag not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// Create godoc
+// @Summary      Create tag
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        tag body tagRequest true "Tag to create"
+// @Success      200 {object} object{id=int} "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Router       /tags [post]
+// @Security     Bearer
+func (h *TagsHandler) Create(c *gin.Context) {
+	logger := logger.GetLogger()
+	var request tagRequest
+	err := c.BindJSON(&request)
+	name := strings.TrimSpace(request.Name)
+	if err != nil || name == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+
+	id, err := h.repo.Create(c, models.Tag{Name: name})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
+		return
+	}
+
+	logger.Info("Tag has been created", zap.Int("tag_id", id))
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+// Update godoc
+// @Summary      Rename tag
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Tag id"
+// @Param        tag body tagRequest true "Updated tag"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "Tag not found"
+// @Router       /tags/{id} [put]
+// @Security     Bearer
+func (h *TagsHandler) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Tag Id"))
+		return
+	}
+
+	_, err = h.repo.FindById(c, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Tag not found"))
+		return
+	}
+
+	var request tagRequest
+	err = c.BindJSON(&request)
+	name := strings.TrimSpace(request.Name)
+	if err != nil || name == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+
+	err = h.repo.Update(c, id, models.Tag{Name: name})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// Delete godoc
+// @Summary      Delete tag
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Tag id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid tag id"
+// @Failure      500 {object} models.ApiError
+// @Router       /tags/{id} [delete]
+// @Security     Bearer
+func (h *TagsHandler) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Tag Id"))
+		return
+	}
+
+	err = h.repo.Delete(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	h.similar.Invalidate()
+
+	c.Status(http.StatusOK)
+}
+
+// HandleMerge godoc
+// @Summary      Merge duplicate tags
+// @Description  Reassigns every movie of the source tags to the target tag and deletes the source tags.
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Target tag id"
+// @Param        request body mergeTagsRequest true "Tags to merge into the target"
+// @Success      200 {object} models.TagUsage "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "Tag not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /tags/{id}/merge [post]
+// @Security     Bearer
+func (h *TagsHandler) HandleMerge(c *gin.Context) {
+	logger := logger.GetLogger()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Tag Id"))
+		return
+	}
+
+	_, err = h.repo.FindById(c, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Tag not found"))
+		return
+	}
+
+	var request mergeTagsRequest
+	err = c.BindJSON(&request)
+	if err != nil || len(request.SourceIds) == 0 {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+	for _, sourceId := range request.SourceIds {
+		if sourceId == id {
+			c.JSON(http.StatusBadRequest, models.NewApiError("Tag can not be merged into itself"))
+			return
+		}
+	}
+
+	err = h.repo.Merge(c, id, request.SourceIds)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	h.similar.Invalidate()
+
+	merged, err := h.repo.FindById(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	logger.Info("Tags have been merged", zap.Int("tag_id", id), zap.Ints("source_ids", request.SourceIds))
+	c.JSON(http.StatusOK, merged)
+}
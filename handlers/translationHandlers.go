@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TranslationsHandler struct {
+	moviesRepo       *repositories.MoviesRepository
+	genresRepo       *repositories.GenresRepository
+	translationsRepo *repositories.TranslationsRepository
+}
+
+type movieTranslationRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type genreTranslationRequest struct {
+	Title string `json:"title"`
+}
+
+func NewTranslationsHandler(
+	moviesRepo *repositories.MoviesRepository,
+	genresRepo *repositories.GenresRepository,
+	translationsRepo *repositories.TranslationsRepository) *TranslationsHandler {
+	return &TranslationsHandler{
+		moviesRepo:       moviesRepo,
+		genresRepo:       genresRepo,
+		translationsRepo: translationsRepo,
+	}
+}
+
+// HandleGetMovieTranslations godoc
+// @Summary      Get movie translations
+// @Tags         translations
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Success      200 {array} models.MovieTranslation "OK"
+// @Failure      400 {object} models.ApiError "Invalid movie id"
+// @Failure      500 {object} models.ApiError
+// @Router       /movies/{id}/translations [get]
+// @Security     Bearer
+func (h *TranslationsHandler) HandleGetMovieTranslations(c *gin.Context) {
+	logger := logger.GetLogger()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		logger.Error("Could not parse movie id", zap.String("id", idStr))
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+		return
+	}
+
+	translations, err := h.translationsRepo.FindMovieTranslations(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, translations)
+}
+
+// HandleSetMovieTranslation godoc
+// @Summary      Create or replace movie translation
+// @Tags         translations
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Param        lang path string true "Language (kk, ru, en)"
+// @Param        translation body movieTranslationRequest true "Translated fields"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      500 {object} models.ApiError
+// @Router       /movies/{id}/translations/{lang} [put]
+// @Security     Bearer
+func (h *TranslationsHandler) HandleSetMovieTranslation(c *gin.Context) {
+	logger := logger.GetLogger()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		logger.Error("Could not parse movie id", zap.String("id", idStr))
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+		return
+	}
+
+	lang := c.Param("lang")
+	if !models.IsSupportedLanguage(lang) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Unsupported language"))
+		return
+	}
+
+	var request movieTranslationRequest
+	err = c.BindJSON(&request)
+	if err != nil || request.Title == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+
+	_, err = h.moviesRepo.FindById(c, id, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
+		return
+	}
+
+	err = h.translationsRepo.UpsertMovieTranslation(c, models.MovieTranslation{
+		MovieId:     id,
+		Language:    lang,
+		Title:       request.Title,
+		Description: request.Description,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	logger.Info("Movie translation has been saved", zap.Int("movie_id", id), zap.String("lang", lang))
+	c.Status(http.StatusOK)
+}
+
+// HandleDeleteMovieTranslation godoc
+// @Summary      Delete movie translation
+// @Tags         translations
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Param        lang path string true "Language (kk, ru, en)"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      500 {object} models.ApiError
+// @Router       /movies/{id}/translations/{lang} [delete]
+// @Security     Bearer
+func (h *TranslationsHandler) HandleDeleteMovieTranslation(c *gin.Context) {
+	logger := logger.GetLogger()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		logger.Error("Could not parse movie id", zap.String("id", idStr))
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+		return
+	}
+
+	lang := c.Param("lang")
+	if !models.IsSupportedLanguage(lang) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Unsupported language"))
+		return
+	}
+
+	err = h.translationsRepo.DeleteMovieTranslation(c, id, lang)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleGetGenreTranslations godoc
+// @Summary      Get genre translations
+// @Tags         translations
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Genre id"
+// @Success      200 {array} models.GenreTranslation "OK"
+// @Failure      400 {object} models.ApiError "Invalid genre id"
+// @Failure      500 {object} models.ApiError
+// @Router       /genres/{id}/translations [get]
+// @Security     Bearer
+func (h *TranslationsHandler) HandleGetGenreTranslations(c *gin.Context) {
+	logger := logger.GetLogger()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		logger.Error("Could not parse genre id", zap.String("id", idStr))
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Genre Id"))
+		return
+	}
+
+	translations, err := h.translationsRepo.FindGenreTranslations(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, translations)
+}
+
+// HandleSetGenreTranslation godoc
+// @Summary      Create or replace genre translation
+// @Tags         translations
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Genre id"
+// @Param        lang path string true "Language (kk, ru, en)"
+// @Param        translation body genreTranslationRequest true "Translated fields"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      500 {object} models.ApiError
+// @Router       /genres/{id}/translations/{lang} [put]
+// @Security     Bearer
+func (h *TranslationsHandler) HandleSetGenreTranslation(c *gin.Context) {
+	logger := logger.GetLogger()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		logger.Error("Could not parse genre id", zap.String("id", idStr))
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Genre Id"))
+		return
+	}
+
+	lang := c.Param("lang")
+	if !models.IsSupportedLanguage(lang) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Unsupported language"))
+		return
+	}
+
+	var request genreTranslationRequest
+	err = c.BindJSON(&request)
+	if err != nil || request.Title == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+
+	_, err = h.genresRepo.FindById(c, id, lang)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
+		return
+	}
+
+	err = h.translationsRepo.UpsertGenreTranslation(c, models.GenreTranslation{
+		GenreId:  id,
+		Language: lang,
+		Title:    request.Title,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	logger.Info("Genre translation has been saved", zap.Int("genre_id", id), zap.String("lang", lang))
+	c.Status(http.StatusOK)
+}
+
+// HandleDeleteGenreTranslation godoc
+// @Summary      Delete genre translation
+// @Tags         translations
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Genre id"
+// @Param        lang path string true "Language (kk, ru, en)"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      500 {object} models.ApiError
+// @Router       /genres/{id}/translations/{lang} [delete]
+// @Security     Bearer
+func (h *TranslationsHandler) HandleDeleteGenreTranslation(c *gin.Context) {
+	logger := logger.GetLogger()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		logger.Error("Could not parse genre id", zap.String("id", idStr))
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Genre Id"))
+		return
+	}
+
+	lang := c.Param("lang")
+	if !models.IsSupportedLanguage(lang) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Unsupported language"))
+		return
+	}
+
+	err = h.translationsRepo.DeleteGenreTranslation(c, id, lang)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
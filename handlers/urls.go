@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"fmt"
+	"goozinshe/config"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// absoluteUrl turns a path on this server into an absolute URL. PUBLIC_URL is
+// used when configured, otherwise the URL is derived from the request.
+func absoluteUrl(c *gin.Context, path string) string {
+	base := strings.TrimRight(config.Config.PublicUrl, "/")
+	if base == "" {
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+			scheme = proto
+		}
+		base = fmt.Sprintf("%s://%s", scheme, c.Request.Host)
+	}
+	return base + "/" + strings.TrimLeft(path, "/")
+}
+
+// imageUrl returns a URL of an uploaded image that works without
+// authorization. Values that already are URLs are returned unchanged.
+func imageUrl(c *gin.Context, image string) string {
+	if image == "" || strings.HasPrefix(image, "http://") || strings.HasPrefix(image, "https://") {
+		return image
+	}
+	return absoluteUrl(c, "images/"+image)
+}
@@ -6,17 +6,19 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"goozinshe/logger"
 	"goozinshe/models"
+	"goozinshe/notifier"
 	"goozinshe/repositories"
 	"net/http"
 	"strconv"
 )
 
 type UsersHandlers struct {
-	repo *repositories.UsersRepository
+	repo     *repositories.UsersRepository
+	notifier *notifier.Notifier
 }
 
-func NewUsersHandlers(repo *repositories.UsersRepository) *UsersHandlers {
-	return &UsersHandlers{repo: repo}
+func NewUsersHandlers(repo *repositories.UsersRepository, notifier *notifier.Notifier) *UsersHandlers {
+	return &UsersHandlers{repo: repo, notifier: notifier}
 }
 
 type createUserRequest struct {
@@ -42,6 +44,10 @@ type updateUserRequest struct {
 	Email string `json:"email"`
 }
 
+type changeRoleRequest struct {
+	Role string `json:"role"`
+}
+
 type changePasswordRequest struct {
 	Name     string `json:"name"`
 	Email    string `json:"email"`
@@ -50,6 +56,7 @@ type changePasswordRequest struct {
 
 // Create godoc
 // @Summary Create a user
+// @Description Sends the user an email with a link to confirm the address.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -86,6 +93,12 @@ func (h *UsersHandlers) Create(c *gin.Context) {
 		return
 	}
 
+	user.Id = id
+	err = h.notifier.EnqueueVerification(c, user)
+	if err != nil {
+		logger.Error("Could not enqueue verification email", zap.Int("user_id", id), zap.Error(err))
+	}
+
 	c.JSON(http.StatusOK, gin.H{"id": id})
 }
 
@@ -248,6 +261,56 @@ func (h *UsersHandlers) ChangePassword(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+// ChangeRole godoc
+// @Summary Change user role
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User id"
+// @Param request body changeRoleRequest true "New role (user, editor, admin)"
+// @Success 200
+// @Failure 400 {object} models.ApiError
+// @Failure 404 {object} models.ApiError
+// @Failure 500 {object} models.ApiError
+// @Router /admin/users/{id}/role [patch]
+// @Security Bearer
+func (h *UsersHandlers) ChangeRole(c *gin.Context) {
+	logger := logger.GetLogger()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		logger.Error("Could not parse id", zap.Error(err))
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid user Id"))
+		return
+	}
+
+	var request changeRoleRequest
+	if err := c.BindJSON(&request); err != nil {
+		logger.Error(err.Error())
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+	if !models.IsValidRole(request.Role) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid role"))
+		return
+	}
+
+	_, err = h.repo.FindById(c, id)
+	if err != nil {
+		logger.Error("Could not find user", zap.String("id", idStr), zap.Error(err))
+		c.JSON(http.StatusNotFound, models.NewApiError("User not found"))
+		return
+	}
+
+	err = h.repo.ChangeRole(c, id, request.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
 // Delete godoc
 // @Summary Delete a user
 // @Tags users
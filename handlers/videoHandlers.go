@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"goozinshe/transcoder"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VideosHandler struct {
+	moviesRepo *repositories.MoviesRepository
+	videosRepo *repositories.VideosRepository
+	pipeline   *transcoder.Pipeline
+}
+
+func NewVideosHandler(
+	moviesRepo *repositories.MoviesRepository,
+	videosRepo *repositories.VideosRepository,
+	pipeline *transcoder.Pipeline) *VideosHandler {
+	return &VideosHandler{
+		moviesRepo: moviesRepo,
+		videosRepo: videosRepo,
+		pipeline:   pipeline,
+	}
+}
+
+type uploadVideoRequest struct {
+	Video *multipart.FileHeader `form:"video"`
+}
+
+var streamContentTypes = map[string]string{
+	".m3u8": "application/vnd.apple.mpegurl",
+	".ts":   "video/mp2t",
+	".m4s":  "video/iso.segment",
+	".mp4":  "video/mp4",
+}
+
+// HandleUpload godoc
+// @Summary      Upload movie video
+// @Description  Stores the source file and packages it into HLS renditions in the background. The previous video is streamed until the new one is ready.
+// @Tags         videos
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Param        video formData file true "Source video"
+// @Success      202 {object} models.Video "Accepted"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      403 {object} models.ApiError "Not an editor"
+// @Failure      404 {object} models.ApiError "Movie not found"
+// @Failure      409 {object} models.ApiError "Video is being processed"
+// @Failure      500 {object} models.ApiError
+// @Router       /movies/{id}/video [post]
+// @Security     Bearer
+func (h *VideosHandler) HandleUpload(c *gin.Context) {
+	logger := logger.GetLogger()
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+		return
+	}
+
+	_, err = h.moviesRepo.FindById(c, id, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie not found"))
+		return
+	}
+
+	var request uploadVideoRequest
+	err = c.Bind(&request)
+	if err != nil || request.Video == nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Could not bind payload"))
+		return
+	}
+
+	version, ok, err := h.videosRepo.ClaimUpload(c, id, transcoder.ProcessingTimeout)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusConflict, models.NewApiError("Video is being processed"))
+		return
+	}
+
+	source := transcoder.SourcePath(id, version, filepath.Ext(request.Video.Filename))
+	err = os.MkdirAll(filepath.Dir(source), 0755)
+	if err == nil {
+		err = c.SaveUploadedFile(request.Video, source)
+	}
+	if err == nil {
+		err = h.pipeline.Enqueue(c, transcoder.TranscodePayload{MovieId: id, Version: version, Source: source})
+	}
+	if err != nil {
+		_ = h.videosRepo.Fail(c, id, version, err.Error())
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	logger.Info("Movie video has been uploaded", zap.Int("movie_id", id), zap.Int("version", version))
+	c.JSON(http.StatusAccepted, models.Video{MovieId: id, Status: models.VideoStatusProcessing})
+}
+
+// HandleGetStatus godoc
+// @Summary      Get movie video status
+// @Tags         videos
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Movie id"
+// @Success      200 {object} models.Video "OK"
+// @Failure      400 {object} models.ApiError "Invalid movie id"
+// @Failure      403 {object} models.ApiError "Not an editor"
+// @Failure      404 {object} models.ApiError "Video not found"
+// @Router       /movies/{id}/video [get]
+// @Security     Bearer
+func (h *VideosHandler) HandleGetStatus(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+		return
+	}
+
+	_, err = h.moviesRepo.FindById(c, id, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie not found"))
+		return
+	}
+
+	video, err := h.videosRepo.FindByMovieId(c, id, transcoder.ProcessingTimeout)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Video not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, video)
+}
+
+// HandleStream godoc
+// @Summary      Stream movie video
+// @Description  Serves the HLS master playlist (master.m3u8), rendition playlists and segments. Supports Range requests. The master playlist points into the directory of the published upload (e.g. v3/720p/index.m3u8), whose files never change and may be cached for good.
+// @Tags         videos
+// @Produce      application/vnd.apple.mpegurl
+// @Produce      video/mp2t
+// @Param        id path int true "Movie id"
+// @Param        file path string true "Playlist or segment path, e.g. master.m3u8 or v3/720p/segment_0001.ts"
+// @Success      200 "OK"
+// @Success      206 "Partial content"
+// @Failure      400 {object} models.ApiError "Invalid path"
+// @Failure      404 {object} models.ApiError "Video not found"
+// @Router       /movies/{id}/stream/{file} [get]
+// @Security     Bearer
+func (h *VideosHandler) HandleStream(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+		return
+	}
+
+	name := filepath.Clean(strings.TrimPrefix(c.Param("file"), "/"))
+	contentType, ok := streamContentTypes[filepath.Ext(name)]
+	if !ok || name == "." || strings.HasPrefix(name, "..") || filepath.IsAbs(name) {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid path"))
+		return
+	}
+
+	_, err = h.moviesRepo.FindById(c, id, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie not found"))
+		return
+	}
+
+	if name == "master.m3u8" {
+		h.serveMasterPlaylist(c, id)
+		return
+	}
+
+	// Files of an upload version never change.
+	dir, rest, found := strings.Cut(name, "/")
+	version, ok := transcoder.ParseVersionPath(dir)
+	if !found || !ok {
+		c.JSON(http.StatusNotFound, models.NewApiError("Video not found"))
+		return
+	}
+	path := filepath.Join(transcoder.VersionDir(id, version), rest)
+	cacheControl := "private, max-age=31536000, immutable"
+	if filepath.Ext(name) == ".m3u8" {
+		cacheControl = "private, no-cache"
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Video not found"))
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil || stat.IsDir() {
+		c.JSON(http.StatusNotFound, models.NewApiError("Video not found"))
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", cacheControl)
+	http.ServeContent(c.Writer, c.Request, stat.Name(), stat.ModTime(), file)
+}
+
+// serveMasterPlaylist serves the master playlist of the published upload
+// with its rendition playlists prefixed by the version directory. While a
+// new upload is processed the previous one is still served.
+func (h *VideosHandler) serveMasterPlaylist(c *gin.Context, movieId int) {
+	video, err := h.videosRepo.FindByMovieId(c, movieId, transcoder.ProcessingTimeout)
+	if err != nil || video.PublishedVersion == 0 {
+		c.JSON(http.StatusNotFound, models.NewApiError("Video not found"))
+		return
+	}
+	playlist, err := os.ReadFile(filepath.Join(transcoder.VersionDir(movieId, video.PublishedVersion), "master.m3u8"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Video not found"))
+		return
+	}
+
+	prefix := transcoder.VersionPath(video.PublishedVersion) + "/"
+	lines := strings.Split(string(playlist), "\n")
+	for i, line := range lines {
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines[i] = prefix + line
+		}
+	}
+	playlist = []byte(strings.Join(lines, "\n"))
+
+	c.Header("Cache-Control", "private, no-cache")
+	c.Data(http.StatusOK, streamContentTypes[".m3u8"], playlist)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func getViewer(c *gin.Context) models.Viewer {
+	return models.Viewer{
+		UserId:       c.GetInt("userId"),
+		ProfileId:    c.GetInt("profileId"),
+		Language:     c.GetString("lang"),
+		MaxAgeRating: c.GetInt("maxAgeRating"),
+	}
+}
+
+// parseVisibleMovieId reads the movie id path param and makes sure the movie
+// is visible to the current profile, responding with an error otherwise.
+func parseVisibleMovieId(c *gin.Context, moviesRepo *repositories.MoviesRepository) (int, bool) {
+	return parseVisibleMovieParam(c, moviesRepo, "id")
+}
+
+// parseVisibleMovieParam is parseVisibleMovieId for routes that name the
+// movie id path param differently.
+func parseVisibleMovieParam(c *gin.Context, moviesRepo *repositories.MoviesRepository, param string) (int, bool) {
+	movieId, err := strconv.Atoi(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid Movie Id"))
+		return 0, false
+	}
+
+	exists, err := moviesRepo.Exists(c, movieId, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return 0, false
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie not found"))
+		return 0, false
+	}
+	return movieId, true
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/middlewares"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"goozinshe/watchparty"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// The API allows any origin (see the CORS config in main) and authenticates
+// with a bearer token rather than cookies, so the origin is not checked here
+// either. Browsers pass the token as a subprotocol, which has to be echoed
+// back for the handshake to succeed.
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	Subprotocols:    []string{middlewares.AccessTokenProtocol},
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+type WatchPartiesHandler struct {
+	hub          *watchparty.Hub
+	moviesRepo   *repositories.MoviesRepository
+	profilesRepo *repositories.ProfilesRepository
+}
+
+func NewWatchPartiesHandler(
+	hub *watchparty.Hub,
+	moviesRepo *repositories.MoviesRepository,
+	profilesRepo *repositories.ProfilesRepository) *WatchPartiesHandler {
+	return &WatchPartiesHandler{
+		hub:          hub,
+		moviesRepo:   moviesRepo,
+		profilesRepo: profilesRepo,
+	}
+}
+
+type createWatchPartyRequest struct {
+	MovieId int `json:"movieId"`
+}
+
+// HandleCreate godoc
+// @Summary      Create watch party
+// @Description  Opens a room for watching the movie together. Share the returned code to invite friends. Rooms nobody has been in for WATCH_PARTY_IDLE_TIMEOUT are closed.
+// @Tags         watch-parties
+// @Accept       json
+// @Produce      json
+// @Param        request body createWatchPartyRequest true "Movie"
+// @Success      200 {object} watchparty.RoomInfo "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      404 {object} models.ApiError "Movie not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /watch-parties [post]
+// @Security     Bearer
+func (h *WatchPartiesHandler) HandleCreate(c *gin.Context) {
+	var request createWatchPartyRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+
+	exists, err := h.moviesRepo.Exists(c, request.MovieId, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie not found"))
+		return
+	}
+
+	room, err := h.hub.Create(request.MovieId, c.GetInt("profileId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	logger := logger.GetLogger()
+	logger.Info("Watch party has been created", zap.String("code", room.Code), zap.Int("movie_id", room.MovieId))
+
+	c.JSON(http.StatusOK, room.Info())
+}
+
+// HandleGet godoc
+// @Summary      Get watch party
+// @Description  Current playback state and participants of the room.
+// @Tags         watch-parties
+// @Accept       json
+// @Produce      json
+// @Param        code path string true "Invite code"
+// @Success      200 {object} watchparty.RoomInfo "OK"
+// @Failure      404 {object} models.ApiError "Watch party not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /watch-parties/{code} [get]
+// @Security     Bearer
+func (h *WatchPartiesHandler) HandleGet(c *gin.Context) {
+	room, ok := h.findRoom(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, room.Info())
+}
+
+// HandleJoin godoc
+// @Summary      Join watch party
+// @Description  Upgrades to a WebSocket. Clients send JSON messages {"type": "play"|"pause"|"seek", "position": seconds}, {"type": "chat", "text": "..."} and {"type": "ping", "clientTime": ms}. The server answers with messages of Type "welcome" (room state), "playback", "chat", "presence", "pong" and "error", each stamped with ServerTime in Unix milliseconds. Browsers can not set the Authorization header on the handshake, so they pass the token as subprotocols ["access_token", token] or in the access_token query param.
+// @Tags         watch-parties
+// @Param        code path string true "Invite code"
+// @Param        access_token query string false "Access token, for clients that can not set the Authorization header"
+// @Success      101 "Switching protocols"
+// @Failure      404 {object} models.ApiError "Watch party not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /watch-parties/{code}/ws [get]
+// @Security     Bearer
+func (h *WatchPartiesHandler) HandleJoin(c *gin.Context) {
+	room, ok := h.findRoom(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.profilesRepo.FindById(c, c.GetInt("profileId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already responded to the client.
+		return
+	}
+
+	participant := watchparty.Participant{ProfileId: profile.Id, Name: profile.Name}
+	err = room.Serve(conn, participant)
+	if err != nil {
+		logger := logger.GetLogger()
+		logger.Info("Could not join watch party", zap.String("code", room.Code), zap.Error(err))
+	}
+}
+
+// findRoom looks the room up by the invite code and makes sure its movie is
+// visible to the current profile.
+func (h *WatchPartiesHandler) findRoom(c *gin.Context) (*watchparty.Room, bool) {
+	room, ok := h.hub.Find(strings.ToUpper(c.Param("code")))
+	if !ok {
+		c.JSON(http.StatusNotFound, models.NewApiError("Watch party not found"))
+		return nil, false
+	}
+
+	exists, err := h.moviesRepo.Exists(c, room.MovieId, getViewer(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return nil, false
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, models.NewApiError("Watch party not found"))
+		return nil, false
+	}
+	return room, true
+}
@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"go.uber.org/zap"
+	"goozinshe/events"
 	"goozinshe/logger"
 	"goozinshe/models"
 	"goozinshe/repositories"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,23 +16,28 @@ import (
 type WatchlistHandler struct {
 	moviesRepo    *repositories.MoviesRepository
 	watchlistRepo *repositories.WatchlistRepository
+	socialRepo    *repositories.SocialRepository
+	broker        *events.Broker
 }
 
-func NewWatchlistHandler(moviesRepo *repositories.MoviesRepository, watchlistRepo *repositories.WatchlistRepository) *WatchlistHandler {
-	return &WatchlistHandler{moviesRepo: moviesRepo, watchlistRepo: watchlistRepo}
+func NewWatchlistHandler(moviesRepo *repositories.MoviesRepository, watchlistRepo *repositories.WatchlistRepository, socialRepo *repositories.SocialRepository, broker *events.Broker) *WatchlistHandler {
+	return &WatchlistHandler{moviesRepo: moviesRepo, watchlistRepo: watchlistRepo, socialRepo: socialRepo, broker: broker}
 }
 
 // HandleGetMovies godoc
 // @Summary Get movies from watchlist
+// @Description Movies of the default list of the current profile, in list order.
 // @Tags watchlist
 // @Accept json
 // @Produce json
+// @Param sort query string false "Order: position (default), priority or added"
+// @Param lang query string false "Language (kk, ru, en)"
 // @Success 200
 // @Failure 500 {object} models.ApiError
 // @Router /watchlist [get]
 func (h *WatchlistHandler) HandleGetMovies(c *gin.Context) {
 	logger := logger.GetLogger()
-	movies, err := h.watchlistRepo.GetMoviesFromWatchlist(c)
+	movies, err := h.watchlistRepo.GetMoviesFromWatchlist(c, getViewer(c), c.Query("sort"))
 	if err != nil {
 		logger.Error("Could not get movies", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
@@ -42,6 +49,7 @@ func (h *WatchlistHandler) HandleGetMovies(c *gin.Context) {
 
 // HandleAddMovie godoc
 // @Summary Add movie to the watchlist
+// @Description Appends the movie to the default list; adding it twice is a no-op.
 // @Tags watchlist
 // @Accept json
 // @Produce json
@@ -58,19 +66,25 @@ func (h *WatchlistHandler) HandleAddMovie(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid movie id"))
 		return
 	}
-	_, err = h.moviesRepo.FindById(c, id)
+	_, err = h.moviesRepo.FindById(c, id, getViewer(c))
 	if err != nil {
 		logger.Error("Could not find movie", zap.String("movieId", idStr), zap.Error(err))
 		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
 		return
 	}
 
-	err = h.watchlistRepo.AddToWatchlist(c, id)
+	err = h.watchlistRepo.AddToWatchlist(c, c.GetInt("profileId"), id)
 	if err != nil {
 		logger.Error("Could not add movie", zap.String("movieId", idStr), zap.Error(err))
 		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
 		return
 	}
+	recordActivity(c, h.socialRepo, models.ActivityEvent{
+		ProfileId: c.GetInt("profileId"),
+		Type:      models.ActivityWatchlisted,
+		MovieId:   id,
+	})
+	publishWatchlistChanged(h.broker, c.GetInt("profileId"), id)
 
 	c.Status(http.StatusOK)
 }
@@ -94,19 +108,148 @@ func (h *WatchlistHandler) HandleRemoveMovie(c *gin.Context) {
 		return
 	}
 
-	_, err = h.moviesRepo.FindById(c, id)
+	_, err = h.moviesRepo.FindById(c, id, getViewer(c))
 	if err != nil {
 		logger.Error("Could not find movie", zap.String("movieId", idStr), zap.Error(err))
 		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
 		return
 	}
 
-	err = h.watchlistRepo.RemoveFromWatchlist(c, id)
+	err = h.watchlistRepo.RemoveFromWatchlist(c, c.GetInt("profileId"), id)
 	if err != nil {
 		logger.Error(err.Error())
 		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
 		return
 	}
+	retractActivity(c, h.socialRepo, models.ActivityEvent{
+		ProfileId: c.GetInt("profileId"),
+		Type:      models.ActivityWatchlisted,
+		MovieId:   id,
+	})
+	publishWatchlistChanged(h.broker, c.GetInt("profileId"), id)
 
 	c.Status(http.StatusOK)
 }
+
+// HandleUpdateMovie godoc
+// @Summary Change priority, reminder or note of a watchlist movie
+// @Description Only the fields present in the request are changed. Priority is 1 (low), 2 (normal) or 3 (high); an empty remindAt removes the reminder.
+// @Tags watchlist
+// @Accept json
+// @Produce json
+// @Param movieId path int true "Movie id"
+// @Param request body listEntryRequest true "Fields to change"
+// @Success 200
+// @Failure 400 {object} models.ApiError "Invalid data"
+// @Failure 404 {object} models.ApiError "Movie is not on the watchlist"
+// @Failure 500 {object} models.ApiError
+// @Router /watchlist/{movieId} [patch]
+func (h *WatchlistHandler) HandleUpdateMovie(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid movie id"))
+		return
+	}
+
+	var request listEntryRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return
+	}
+	update, err := request.toUpdate()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err.Error()))
+		return
+	}
+
+	found, err := h.watchlistRepo.UpdateWatchlistEntry(c, c.GetInt("profileId"), id, update)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie is not on the watchlist"))
+		return
+	}
+	publishWatchlistChanged(h.broker, c.GetInt("profileId"), id)
+
+	c.Status(http.StatusOK)
+}
+
+// HandleMoveMovie godoc
+// @Summary Move movie within the watchlist
+// @Tags watchlist
+// @Accept json
+// @Produce json
+// @Param movieId path int true "Movie id"
+// @Param request body moveListMovieRequest true "New 1-based position"
+// @Success 200
+// @Failure 400 {object} models.ApiError "Invalid data"
+// @Failure 404 {object} models.ApiError "Movie is not on the watchlist"
+// @Failure 500 {object} models.ApiError
+// @Router /watchlist/{movieId}/move [post]
+func (h *WatchlistHandler) HandleMoveMovie(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid movie id"))
+		return
+	}
+
+	var request moveListMovieRequest
+	if err := c.BindJSON(&request); err != nil || request.Position < 1 {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid position"))
+		return
+	}
+
+	found, err := h.watchlistRepo.MoveInWatchlist(c, c.GetInt("profileId"), id, request.Position)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.NewApiError("Movie is not on the watchlist"))
+		return
+	}
+	publishWatchlistChanged(h.broker, c.GetInt("profileId"), id)
+
+	c.Status(http.StatusOK)
+}
+
+// HandleGetDueReminders godoc
+// @Summary Get due watchlist reminders
+// @Description Watchlist movies whose reminder date has come, earliest first.
+// @Tags watchlist
+// @Accept json
+// @Produce json
+// @Param date query string false "Reference date (YYYY-MM-DD), today by default"
+// @Param lang query string false "Language (kk, ru, en)"
+// @Success 200 {array} models.ListItem
+// @Failure 400 {object} models.ApiError "Invalid date"
+// @Failure 500 {object} models.ApiError
+// @Router /me/reminders [get]
+func (h *WatchlistHandler) HandleGetDueReminders(c *gin.Context) {
+	date := time.Now()
+	if dateStr := c.Query("date"); dateStr != "" {
+		var err error
+		date, err = time.Parse(reminderDateLayout, dateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewApiError("Invalid date"))
+			return
+		}
+	}
+
+	viewer := getViewer(c)
+	entries, err := h.watchlistRepo.FindDueReminders(c, viewer.ProfileId, date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	items, err := buildListItems(c, h.moviesRepo, entries, viewer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"goozinshe/webhooks"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhooksHandler struct {
+	webhooksRepo *repositories.WebhooksRepository
+}
+
+func NewWebhooksHandler(webhooksRepo *repositories.WebhooksRepository) *WebhooksHandler {
+	return &WebhooksHandler{webhooksRepo: webhooksRepo}
+}
+
+type webhookRequest struct {
+	Url string `json:"url"`
+	// Secret is generated when left empty; on update the current one is kept.
+	Secret      string   `json:"secret"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	IsActive    *bool    `json:"isActive"`
+}
+
+// HandleGetWebhooks godoc
+// @Summary      Get webhooks
+// @Description  Admins only.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Success      200 {array} models.Webhook "OK"
+// @Failure      403 {object} models.ApiError "Not an admin"
+// @Failure      500 {object} models.ApiError
+// @Router       /admin/webhooks [get]
+// @Security     Bearer
+func (h *WebhooksHandler) HandleGetWebhooks(c *gin.Context) {
+	webhooks, err := h.webhooksRepo.FindAll(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// HandleCreateWebhook godoc
+// @Summary      Create webhook
+// @Description  Subscribes the URL to catalog events (movie.created, movie.updated, movie.deleted, genre.created, genre.updated, genre.deleted; none listed means all). Each delivery is a POST of {"Id", "Type", "OccurredAt", "Data"} with the X-Ozinshe-Event, X-Ozinshe-Delivery, X-Ozinshe-Timestamp and X-Ozinshe-Signature headers. The signature is "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret. Any response outside 2xx is retried with exponential backoff up to WEBHOOK_MAX_ATTEMPTS times. Admins only.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request body webhookRequest true "Webhook"
+// @Success      200 {object} object{id=int,secret=string} "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      403 {object} models.ApiError "Not an admin"
+// @Failure      500 {object} models.ApiError
+// @Router       /admin/webhooks [post]
+// @Security     Bearer
+func (h *WebhooksHandler) HandleCreateWebhook(c *gin.Context) {
+	webhook, ok := bindWebhook(c)
+	if !ok {
+		return
+	}
+	if webhook.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+			return
+		}
+		webhook.Secret = secret
+	}
+
+	id, err := h.webhooksRepo.Create(c, webhook)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	logger := logger.GetLogger()
+	logger.Info("Webhook has been created", zap.Int("webhook_id", id))
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":     id,
+		"secret": webhook.Secret,
+	})
+}
+
+// HandleUpdateWebhook godoc
+// @Summary      Update webhook
+// @Description  Admins only.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Webhook id"
+// @Param        request body webhookRequest true "Webhook"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      403 {object} models.ApiError "Not an admin"
+// @Failure      404 {object} models.ApiError "Webhook not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /admin/webhooks/{id} [put]
+// @Security     Bearer
+func (h *WebhooksHandler) HandleUpdateWebhook(c *gin.Context) {
+	existing, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+	webhook, ok := bindWebhook(c)
+	if !ok {
+		return
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = existing.Secret
+	}
+
+	err := h.webhooksRepo.Update(c, existing.Id, webhook)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleDeleteWebhook godoc
+// @Summary      Delete webhook
+// @Description  Also drops its pending and dead deliveries. Admins only.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Webhook id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid webhook id"
+// @Failure      403 {object} models.ApiError "Not an admin"
+// @Failure      404 {object} models.ApiError "Webhook not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /admin/webhooks/{id} [delete]
+// @Security     Bearer
+func (h *WebhooksHandler) HandleDeleteWebhook(c *gin.Context) {
+	webhook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	err := h.webhooksRepo.Delete(c, webhook.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// HandleGetWebhookDeliveries godoc
+// @Summary      Get deliveries of a webhook
+// @Description  Newest first. Admins only.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Webhook id"
+// @Param        status query string false "pending, delivered or dead"
+// @Param        page query int false "Page number" default(1)
+// @Param        pageSize query int false "Page size" default(20)
+// @Success      200 {object} models.Page[models.WebhookDelivery] "OK"
+// @Failure      400 {object} models.ApiError "Invalid data"
+// @Failure      403 {object} models.ApiError "Not an admin"
+// @Failure      404 {object} models.ApiError "Webhook not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /admin/webhooks/{id}/deliveries [get]
+// @Security     Bearer
+func (h *WebhooksHandler) HandleGetWebhookDeliveries(c *gin.Context) {
+	webhook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+	h.findDeliveries(c, webhook.Id)
+}
+
+// HandleGetDeliveries godoc
+// @Summary      Get webhook deliveries
+// @Description  Deliveries of all webhooks, newest first. Use status=dead for the dead letters: deliveries that ran out of attempts. Admins only.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        status query string false "pending, delivered or dead"
+// @Param        page query int false "Page number" default(1)
+// @Param        pageSize query int false "Page size" default(20)
+// @Success      200 {object} models.Page[models.WebhookDelivery] "OK"
+// @Failure      400 {object} models.ApiError "Invalid status"
+// @Failure      403 {object} models.ApiError "Not an admin"
+// @Failure      500 {object} models.ApiError
+// @Router       /admin/webhook-deliveries [get]
+// @Security     Bearer
+func (h *WebhooksHandler) HandleGetDeliveries(c *gin.Context) {
+	h.findDeliveries(c, 0)
+}
+
+// HandleRedeliver godoc
+// @Summary      Redeliver webhook delivery
+// @Description  Queues the delivery again with a fresh set of attempts, whatever its status. The payload and X-Ozinshe-Delivery id stay the same, so subscribers can deduplicate. Admins only.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Delivery id"
+// @Success      200 "OK"
+// @Failure      400 {object} models.ApiError "Invalid delivery id"
+// @Failure      403 {object} models.ApiError "Not an admin"
+// @Failure      404 {object} models.ApiError "Delivery not found"
+// @Failure      500 {object} models.ApiError
+// @Router       /admin/webhook-deliveries/{id}/redeliver [post]
+// @Security     Bearer
+func (h *WebhooksHandler) HandleRedeliver(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid delivery id"))
+		return
+	}
+
+	found, err := h.webhooksRepo.Redeliver(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.NewApiError("Delivery not found"))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *WebhooksHandler) findDeliveries(c *gin.Context, webhookId int) {
+	status := c.Query("status")
+	switch status {
+	case "", models.DeliveryStatusPending, models.DeliveryStatusDelivered, models.DeliveryStatusDead:
+	default:
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid status"))
+		return
+	}
+
+	page, pageSize := getPagination(c)
+	deliveries, total, err := h.webhooksRepo.FindDeliveries(c, webhookId, status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Page[models.WebhookDelivery]{
+		Items:    deliveries,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+func (h *WebhooksHandler) findWebhook(c *gin.Context) (models.Webhook, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid webhook id"))
+		return models.Webhook{}, false
+	}
+
+	webhook, err := h.webhooksRepo.FindById(c, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.NewApiError("Webhook not found"))
+		return models.Webhook{}, false
+	}
+	return webhook, true
+}
+
+func bindWebhook(c *gin.Context) (models.Webhook, bool) {
+	var request webhookRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Invalid request payload"))
+		return models.Webhook{}, false
+	}
+
+	target, err := url.Parse(strings.TrimSpace(request.Url))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		c.JSON(http.StatusBadRequest, models.NewApiError("Webhook url must be an absolute http(s) url"))
+		return models.Webhook{}, false
+	}
+	eventTypes := make([]string, 0, len(request.Events))
+	for _, eventType := range request.Events {
+		if !webhooks.IsEventType(eventType) {
+			c.JSON(http.StatusBadRequest, models.NewApiError("Unknown event type: "+eventType))
+			return models.Webhook{}, false
+		}
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	isActive := true
+	if request.IsActive != nil {
+		isActive = *request.IsActive
+	}
+	return models.Webhook{
+		Url:         target.String(),
+		Secret:      request.Secret,
+		Events:      eventTypes,
+		Description: strings.TrimSpace(request.Description),
+		IsActive:    isActive,
+	}, true
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,75 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"goozinshe/config"
+	"goozinshe/events"
+	"goozinshe/jobs"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"time"
+)
+
+const defaultTtl = 30 * 24 * time.Hour
+
+// Inbox turns catalog events into in-app notifications. It is an outbox
+// sink; the outbox event id is saved with every notification, so a repeated
+// event does not notify anyone twice.
+type Inbox struct {
+	repo *repositories.NotificationsRepository
+}
+
+func NewInbox(repo *repositories.NotificationsRepository) *Inbox {
+	return &Inbox{repo: repo}
+}
+
+func (i *Inbox) Name() string {
+	return "inbox"
+}
+
+func (i *Inbox) Publish(ctx context.Context, event models.OutboxEvent) error {
+	var add func(c context.Context, eventId string, id int) (int64, error)
+	switch event.Type {
+	case events.MovieCreated:
+		add = i.repo.AddNewRelease
+	case events.TrailerAdded:
+		add = i.repo.AddTrailerAdded
+	case events.ReviewApproved:
+		add = i.repo.AddReviewApproved
+	default:
+		return nil
+	}
+
+	var data struct {
+		Id int `json:"id"`
+	}
+	if err := json.Unmarshal(event.Payload, &data); err != nil {
+		return err
+	}
+	count, err := add(ctx, event.EventId, data.Id)
+	if err != nil {
+		return err
+	}
+
+	logger := logger.GetLogger()
+	logger.Debug("Notifications have been added", zap.String("event_id", event.EventId), zap.Int64("count", count))
+	return nil
+}
+
+// CleanupJob deletes notifications created more than NOTIFICATIONS_TTL ago.
+var CleanupJob = jobs.Type[jobs.NoPayload]{Name: "notifications.cleanup", Queue: jobs.MaintenanceQueue}
+
+// Register makes the runner clean up expired notifications.
+func (i *Inbox) Register(runner *jobs.Runner) {
+	jobs.Handle(runner, CleanupJob, func(ctx context.Context, _ jobs.NoPayload) error {
+		ttl := config.Config.NotificationsTtl
+		if ttl <= 0 {
+			ttl = defaultTtl
+		}
+		return i.repo.DeleteOlderThan(ctx, time.Now().Add(-ttl))
+	})
+	jobs.Recur(runner, CleanupJob, runner.CleanupSchedule, jobs.NoPayload{})
+}
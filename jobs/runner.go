@@ -0,0 +1,394 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"goozinshe/config"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultQueue        = "default"
+	MaintenanceQueue    = "maintenance"
+	defaultMaxAttempts  = 5
+	defaultPollInterval = time.Second
+	defaultLease        = 5 * time.Minute
+	defaultRetention    = 7 * 24 * time.Hour
+	// defaultCleanupSchedule is daily at 04:00 UTC.
+	defaultCleanupSchedule = "0 4 * * *"
+	firstRetryDelay        = 10 * time.Second
+	maxRetryDelay          = time.Hour
+	maxErrorLength         = 1000
+)
+
+// NoPayload is the payload of jobs that need no arguments.
+type NoPayload struct{}
+
+// Type describes a kind of job whose payload is a T. Declare it once next to
+// the code that does the work and use it both to handle and to enqueue the
+// jobs, so that the payload types always match.
+type Type[T any] struct {
+	Name  string
+	Queue string
+	// MaxAttempts defaults to 5.
+	MaxAttempts int
+	// Lease is how long a job may run before it is considered abandoned and
+	// cancelled, JOBS_LEASE by default. Leases are per queue, so long-running
+	// types need a queue of their own.
+	Lease time.Duration
+}
+
+func (t Type[T]) queue() string {
+	if t.Queue == "" {
+		return DefaultQueue
+	}
+	return t.Queue
+}
+
+func (t Type[T]) maxAttempts() int {
+	if t.MaxAttempts <= 0 {
+		return defaultMaxAttempts
+	}
+	return t.MaxAttempts
+}
+
+type handlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+type attemptKey struct{}
+
+type attempt struct {
+	number, max int
+}
+
+// LastAttempt reports whether the job running with ctx will not be retried
+// if it fails, so that the handler can record the failure for good.
+func LastAttempt(ctx context.Context) bool {
+	a, ok := ctx.Value(attemptKey{}).(attempt)
+	return ok && a.number >= a.max
+}
+
+type scheduledJob struct {
+	name     string
+	schedule Schedule
+	job      models.Job
+}
+
+// Runner executes jobs from the Postgres-backed queues. Every queue is served
+// by its own pool of workers, sized by JOBS_CONCURRENCY. Several instances
+// can run side by side: jobs are claimed with SELECT ... FOR UPDATE SKIP
+// LOCKED, and a job whose worker dies is picked up again once its lease
+// (JOBS_LEASE) expires, so handlers must be safe to run more than once.
+type Runner struct {
+	repo         *repositories.JobsRepository
+	handlers     map[string]handlerFunc
+	queues       map[string]int
+	leases       map[string]time.Duration
+	schedules    []scheduledJob
+	pollInterval time.Duration
+	lease        time.Duration
+	// CleanupSchedule is when housekeeping jobs run, JOBS_CLEANUP_SCHEDULE.
+	CleanupSchedule Schedule
+}
+
+func NewRunner(repo *repositories.JobsRepository) *Runner {
+	pollInterval := config.Config.JobsPollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	lease := config.Config.JobsLease
+	if lease <= 0 {
+		lease = defaultLease
+	}
+	r := &Runner{
+		repo:         repo,
+		handlers:     make(map[string]handlerFunc),
+		queues:       parseConcurrency(config.Config.JobsConcurrency),
+		leases:       make(map[string]time.Duration),
+		pollInterval: pollInterval,
+		lease:        lease,
+	}
+
+	cleanupSchedule, err := ParseCron(config.Config.JobsCleanupSchedule)
+	if err != nil {
+		cleanupSchedule = MustParseCron(defaultCleanupSchedule)
+	}
+	r.CleanupSchedule = cleanupSchedule
+	Handle(r, CleanupJob, r.cleanup)
+	Recur(r, CleanupJob, cleanupSchedule, NoPayload{})
+	return r
+}
+
+// parseConcurrency reads "queue=workers" pairs separated by commas, e.g.
+// "default=4,maintenance=1". Queues not listed get one worker.
+func parseConcurrency(value string) map[string]int {
+	queues := map[string]int{DefaultQueue: 1, MaintenanceQueue: 1}
+	for _, pair := range strings.Split(value, ",") {
+		name, workers, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(workers))
+		if err != nil || n < 1 {
+			continue
+		}
+		queues[strings.TrimSpace(name)] = n
+	}
+	return queues
+}
+
+// Handle registers the handler for jobs of the type. A job fails when the
+// handler returns an error or panics; it is retried with exponential backoff
+// until it runs out of attempts.
+func Handle[T any](r *Runner, t Type[T], handle func(ctx context.Context, payload T) error) {
+	r.handlers[t.Name] = func(ctx context.Context, data json.RawMessage) error {
+		var payload T
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+		return handle(ctx, payload)
+	}
+	if _, ok := r.queues[t.queue()]; !ok {
+		r.queues[t.queue()] = 1
+	}
+	if t.Lease > r.leases[t.queue()] {
+		r.leases[t.queue()] = t.Lease
+	}
+}
+
+func (r *Runner) leaseOf(queue string) time.Duration {
+	if lease, ok := r.leases[queue]; ok {
+		return lease
+	}
+	return r.lease
+}
+
+type enqueueOptions struct {
+	runAt     time.Time
+	uniqueKey *string
+}
+
+type EnqueueOption func(*enqueueOptions)
+
+// RunAt delays the job until the given time.
+func RunAt(t time.Time) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.runAt = t
+	}
+}
+
+// UniqueKey makes Enqueue skip the job when one with the same key has
+// already been enqueued.
+func UniqueKey(key string) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.uniqueKey = &key
+	}
+}
+
+// Enqueue adds a job of the type. It returns the id of the new job, or zero
+// when a job with the same unique key already exists.
+func Enqueue[T any](c context.Context, r *Runner, t Type[T], payload T, options ...EnqueueOption) (int64, error) {
+	job, err := newJob(t, payload, options...)
+	if err != nil {
+		return 0, err
+	}
+	id, _, err := r.repo.Enqueue(c, job)
+	return id, err
+}
+
+func newJob[T any](t Type[T], payload T, options ...EnqueueOption) (models.Job, error) {
+	opts := enqueueOptions{runAt: time.Now()}
+	for _, option := range options {
+		option(&opts)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return models.Job{}, err
+	}
+	return models.Job{
+		Queue:       t.queue(),
+		Type:        t.Name,
+		Payload:     data,
+		MaxAttempts: t.maxAttempts(),
+		RunAt:       opts.runAt,
+		UniqueKey:   opts.uniqueKey,
+	}, nil
+}
+
+// Recur enqueues a job of the type at every time the schedule is due.
+// Every instance runs the schedule, but each slot is enqueued only once.
+// An Every schedule also enqueues its current slot on start, so that a
+// fresh deployment does not wait a whole interval for the first run.
+func Recur[T any](r *Runner, t Type[T], schedule Schedule, payload T) {
+	job, err := newJob(t, payload)
+	if err != nil {
+		panic(err)
+	}
+	r.schedules = append(r.schedules, scheduledJob{name: t.Name, schedule: schedule, job: job})
+}
+
+// Run starts the workers and schedules and blocks until ctx is cancelled.
+func (r *Runner) Run(ctx context.Context) {
+	for queue, workers := range r.queues {
+		go r.work(ctx, queue, workers)
+	}
+	for _, scheduled := range r.schedules {
+		go r.schedule(ctx, scheduled)
+	}
+	<-ctx.Done()
+}
+
+func (r *Runner) schedule(ctx context.Context, scheduled scheduledJob) {
+	logger := logger.GetLogger()
+	enqueue := func(slot time.Time) {
+		job := scheduled.job
+		key := scheduled.name + "@" + slot.UTC().Format(time.RFC3339)
+		job.UniqueKey = &key
+		job.RunAt = slot
+		if _, _, err := r.repo.Enqueue(ctx, job); err != nil {
+			logger.Error("Could not enqueue scheduled job", zap.String("type", scheduled.name), zap.Error(err))
+		}
+	}
+
+	if e, ok := scheduled.schedule.(every); ok {
+		enqueue(e.current(time.Now()))
+	}
+	for {
+		next := scheduled.schedule.Next(time.Now())
+		if next.IsZero() {
+			logger.Warn("Schedule is never due", zap.String("type", scheduled.name))
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		enqueue(next)
+	}
+}
+
+// work keeps up to workers jobs of the queue running. It polls for due jobs
+// every JOBS_POLL_INTERVAL and right after a job finishes.
+func (r *Runner) work(ctx context.Context, queue string, workers int) {
+	logger := logger.GetLogger()
+	slots := make(chan struct{}, workers)
+	done := make(chan struct{}, 1)
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		free := workers - len(slots)
+		if free > 0 {
+			jobs, err := r.repo.Claim(ctx, queue, free, r.leaseOf(queue))
+			if err != nil {
+				logger.Error("Could not claim jobs", zap.String("queue", queue), zap.Error(err))
+			}
+			for _, job := range jobs {
+				slots <- struct{}{}
+				go func(job models.Job) {
+					defer func() {
+						<-slots
+						select {
+						case done <- struct{}{}:
+						default:
+						}
+					}()
+					r.execute(ctx, job)
+				}(job)
+			}
+			if err == nil && len(jobs) == free {
+				// The queue may have more due jobs; claim again once a
+				// worker is free.
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-done:
+		}
+	}
+}
+
+func (r *Runner) execute(ctx context.Context, job models.Job) {
+	logger := logger.GetLogger()
+	started := time.Now()
+
+	err := r.call(ctx, job)
+	if err == nil {
+		r.repo.Complete(ctx, job.Id)
+		logger.Info("Job finished", zap.Int64("job_id", job.Id), zap.String("type", job.Type),
+			zap.Duration("took", time.Since(started)))
+		return
+	}
+
+	jobErr := err.Error()
+	if len(jobErr) > maxErrorLength {
+		jobErr = jobErr[:maxErrorLength]
+	}
+	var retryAt *time.Time
+	if job.Attempts < job.MaxAttempts {
+		next := time.Now().Add(retryDelay(job.Attempts))
+		retryAt = &next
+		logger.Warn("Job failed, will retry", zap.Int64("job_id", job.Id), zap.String("type", job.Type),
+			zap.Int("attempt", job.Attempts), zap.Time("retry_at", next), zap.String("error", jobErr))
+	} else {
+		logger.Error("Job failed", zap.Int64("job_id", job.Id), zap.String("type", job.Type),
+			zap.Int("attempt", job.Attempts), zap.String("error", jobErr))
+	}
+	r.repo.Fail(ctx, job.Id, jobErr, retryAt)
+}
+
+// call runs the job's handler within its lease, turning a panic into an
+// error.
+func (r *Runner) call(ctx context.Context, job models.Job) (err error) {
+	handler, ok := r.handlers[job.Type]
+	if !ok {
+		return fmt.Errorf("no handler for job type %q", job.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.leaseOf(job.Queue))
+	defer cancel()
+	ctx = context.WithValue(ctx, attemptKey{}, attempt{number: job.Attempts, max: job.MaxAttempts})
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(ctx, job.Payload)
+}
+
+// retryDelay doubles the delay after every failed attempt: 10s, 20s, 40s,
+// ... up to an hour.
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// CleanupJob deletes jobs that finished more than JOBS_RETENTION ago.
+var CleanupJob = Type[NoPayload]{Name: "jobs.cleanup", Queue: MaintenanceQueue}
+
+func (r *Runner) cleanup(ctx context.Context, _ NoPayload) error {
+	retention := config.Config.JobsRetention
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+	return r.repo.DeleteFinished(ctx, time.Now().Add(-retention))
+}
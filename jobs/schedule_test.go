@@ -0,0 +1,108 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronNext(t *testing.T) {
+	// 2024-01-15 is a Monday.
+	after := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"0 4 * * *", time.Date(2024, 1, 16, 4, 0, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2024, 1, 16, 10, 30, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"5-10/5 * * * *", time.Date(2024, 1, 15, 11, 5, 0, 0, time.UTC)},
+		{"10/20 * * * *", time.Date(2024, 1, 15, 10, 50, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 1, 15, 13, 0, 0, 0, time.UTC)},
+		{"0,45 10 * * *", time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1", time.Date(2024, 1, 22, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 6 *", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either may match. The 20th comes
+		// before the next Friday (the 19th does not, it is a Friday).
+		{"0 0 20 * 5", time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 16 * 5", time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+		// A day field starting with * does not count as restricted, so the
+		// other field alone decides.
+		{"0 0 */2 * 5", time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * */2", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		schedule, err := ParseCron(test.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", test.expr, err)
+			continue
+		}
+		if got := schedule.Next(after); !got.Equal(test.want) {
+			t.Errorf("%q: Next(%v) = %v, want %v", test.expr, after, got, test.want)
+		}
+	}
+}
+
+func TestCronNextIsAfter(t *testing.T) {
+	schedule := MustParseCron("0 4 * * *")
+	at := time.Date(2024, 1, 15, 4, 0, 0, 0, time.UTC)
+	if got, want := schedule.Next(at), at.AddDate(0, 0, 1); !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", at, got, want)
+	}
+	// Seconds are truncated, the next whole matching minute is returned.
+	if got, want := schedule.Next(at.Add(-time.Second)), at; !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", at.Add(-time.Second), got, want)
+	}
+}
+
+func TestCronNextUsesUtc(t *testing.T) {
+	almaty := time.FixedZone("Asia/Almaty", 5*60*60)
+	after := time.Date(2024, 1, 15, 8, 0, 0, 0, almaty)
+	got := MustParseCron("0 4 * * *").Next(after)
+	if want := time.Date(2024, 1, 15, 4, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", after, got, want)
+	}
+}
+
+func TestCronNeverDue(t *testing.T) {
+	if got := MustParseCron("0 0 31 2 *").Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next = %v, want zero time", got)
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-a * * * *",
+		"*/x * * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestEveryNext(t *testing.T) {
+	after := time.Date(2024, 1, 15, 10, 7, 30, 0, time.UTC)
+	if got, want := Every(15*time.Minute).Next(after), time.Date(2024, 1, 15, 10, 15, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", after, got, want)
+	}
+}
@@ -9,12 +9,24 @@ import (
 	"github.com/spf13/viper"
 	swaggerfiles "github.com/swaggo/files"
 	swagger "github.com/swaggo/gin-swagger"
+	"goozinshe/charts"
 	"goozinshe/config"
 	"goozinshe/docs"
+	"goozinshe/events"
 	"goozinshe/handlers"
+	"goozinshe/inbox"
+	"goozinshe/jobs"
 	"goozinshe/logger"
 	"goozinshe/middlewares"
+	"goozinshe/models"
+	"goozinshe/notifier"
+	"goozinshe/outbox"
+	"goozinshe/recommendations"
 	"goozinshe/repositories"
+	"goozinshe/transcoder"
+	"goozinshe/watching"
+	"goozinshe/watchparty"
+	"goozinshe/webhooks"
 	"time"
 )
 
@@ -46,6 +58,7 @@ func main() {
 
 	logger := logger.GetLogger()
 	r.Use(
+		middlewares.AccessTokenMiddleware,
 		ginzap.Ginzap(logger, time.RFC3339, true),
 		ginzap.RecoveryWithZap(logger, true),
 	)
@@ -56,6 +69,7 @@ func main() {
 		AllowMethods:    []string{"*"},
 	}
 	r.Use(cors.New(corsConfig))
+	r.Use(middlewares.LanguageMiddleware)
 
 	err := loadConfig()
 	if err != nil {
@@ -67,21 +81,96 @@ func main() {
 		panic(err)
 	}
 
+	broker := events.NewBroker(config.Config.EventsHistorySize)
+	watchPartyHub := watchparty.NewHub(config.Config.WatchPartyIdleTimeout)
+
 	moviesRepository := repositories.NewMoviesRepository(conn)
 	genresRepository := repositories.NewGenresRepository(conn)
 	watchlistRepository := repositories.NewWatchlistRepository(conn)
 	usersRepository := repositories.NewUsersRepository(conn)
+	translationsRepository := repositories.NewTranslationsRepository(conn)
+	profilesRepository := repositories.NewProfilesRepository(conn)
+	tagsRepository := repositories.NewTagsRepository(conn)
+	collectionsRepository := repositories.NewCollectionsRepository(conn)
+	videosRepository := repositories.NewVideosRepository(conn)
+	progressRepository := repositories.NewProgressRepository(conn)
+	historyRepository := repositories.NewHistoryRepository(conn)
+	statsRepository := repositories.NewStatsRepository(conn)
+	recommendationsRepository := repositories.NewRecommendationsRepository(conn)
+	chartsRepository := repositories.NewChartsRepository(conn)
+	listsRepository := repositories.NewListsRepository(conn)
+	reviewsRepository := repositories.NewReviewsRepository(conn)
+	commentsRepository := repositories.NewCommentsRepository(conn)
+	socialRepository := repositories.NewSocialRepository(conn)
+	webhooksRepository := repositories.NewWebhooksRepository(conn)
+	webhookDispatcher := webhooks.NewDispatcher(webhooksRepository)
+	jobsRepository := repositories.NewJobsRepository(conn)
+	jobRunner := jobs.NewRunner(jobsRepository)
+	videoPipeline := transcoder.NewPipeline(
+		videosRepository,
+		transcoder.NewFFmpegTranscoder(config.Config.FfmpegPath, config.Config.FfprobePath, transcoder.DefaultRenditions),
+		jobRunner,
+	)
+	emailRepository := repositories.NewEmailRepository(conn)
+	emailNotifier, err := notifier.NewNotifier(emailRepository, usersRepository, moviesRepository, notifier.NewTransport(), jobRunner)
+	if err != nil {
+		panic(err)
+	}
+	notificationsRepository := repositories.NewNotificationsRepository(conn)
+	notificationsInbox := inbox.NewInbox(notificationsRepository)
+	outboxRepository := repositories.NewOutboxRepository(conn)
+	outboxRelay := outbox.NewRelay(
+		outboxRepository,
+		outbox.NewLogSink(),
+		outbox.NewWebhookSink(webhookDispatcher),
+		emailNotifier,
+		notificationsInbox,
+	)
+	similarMovies := recommendations.NewSimilarMovies(moviesRepository)
+	watchingService := watching.NewService(moviesRepository, historyRepository, socialRepository, broker)
 	moviesHandler := handlers.NewMoviesHandler(
 		moviesRepository,
 		genresRepository,
+		tagsRepository,
+		similarMovies,
+		socialRepository,
+		broker,
+		watchingService,
 	)
-	genresHandler := handlers.NewGenreHandlers(genresRepository)
+	genresHandler := handlers.NewGenreHandlers(genresRepository, similarMovies, broker)
 	imageHandler := handlers.NewImageHandlers()
-	watchlistHandlers := handlers.NewWatchlistHandler(moviesRepository, watchlistRepository)
-	userHandlers := handlers.NewUsersHandlers(usersRepository)
-	authHandlers := handlers.NewAuthHandlers(usersRepository)
+	watchlistHandlers := handlers.NewWatchlistHandler(moviesRepository, watchlistRepository, socialRepository, broker)
+	userHandlers := handlers.NewUsersHandlers(usersRepository, emailNotifier)
+	authHandlers := handlers.NewAuthHandlers(usersRepository, emailNotifier)
+	translationsHandler := handlers.NewTranslationsHandler(moviesRepository, genresRepository, translationsRepository)
+	profilesHandler := handlers.NewProfilesHandler(profilesRepository)
+	tagsHandler := handlers.NewTagsHandler(tagsRepository, similarMovies)
+	collectionsHandler := handlers.NewCollectionsHandler(collectionsRepository, moviesRepository)
+	progressHandler := handlers.NewProgressHandler(moviesRepository, progressRepository, watchingService)
+	historyHandler := handlers.NewHistoryHandler(moviesRepository, historyRepository, watchingService)
+	statsHandler := handlers.NewStatsHandler(statsRepository)
+	recommender := recommendations.NewRecommender(moviesRepository, recommendationsRepository)
+	recommendationsHandler := handlers.NewRecommendationsHandler(recommender)
+	chartsMaterializer := charts.NewMaterializer(chartsRepository)
+	chartsHandler := handlers.NewChartsHandler(chartsRepository, moviesRepository)
+	listsHandler := handlers.NewListsHandler(listsRepository, moviesRepository, socialRepository, broker)
+	reviewsHandler := handlers.NewReviewsHandler(reviewsRepository, moviesRepository, socialRepository)
+	commentsHandler := handlers.NewCommentsHandler(commentsRepository, moviesRepository)
+	socialHandler := handlers.NewSocialHandler(socialRepository, profilesRepository, moviesRepository)
+	eventsHandler := handlers.NewEventsHandler(broker)
+	watchPartiesHandler := handlers.NewWatchPartiesHandler(watchPartyHub, moviesRepository, profilesRepository)
+	webhooksHandler := handlers.NewWebhooksHandler(webhooksRepository)
+	jobsHandler := handlers.NewJobsHandler(jobsRepository)
+	outboxHandler := handlers.NewOutboxHandler(outboxRepository)
+	emailHandler := handlers.NewEmailHandler(emailRepository)
+	notificationsHandler := handlers.NewNotificationsHandler(notificationsRepository, moviesRepository)
+	videosHandler := handlers.NewVideosHandler(
+		moviesRepository,
+		videosRepository,
+		videoPipeline,
+	)
 	authorized := r.Group("")
-	authorized.Use(middlewares.AuthMiddleware)
+	authorized.Use(middlewares.AuthMiddleware, middlewares.ProfileMiddleware(profilesRepository))
 	//Movie handlers
 	authorized.POST("/movies", moviesHandler.Create)
 	authorized.GET("/movies/:id", moviesHandler.FindById)
@@ -90,16 +179,113 @@ func main() {
 	authorized.DELETE("/movies/:id", moviesHandler.Delete)
 	authorized.PATCH("/movies/:movieId/rate", moviesHandler.HandleSetRating)
 	authorized.PATCH("/movies/:movieId/setWatched", moviesHandler.HandleSetWatched)
+	authorized.GET("/movies/:id/similar", moviesHandler.HandleGetSimilar)
+	//Progress handlers
+	authorized.PUT("/movies/:id/progress", progressHandler.HandleSaveProgress)
+	authorized.GET("/movies/:id/progress", progressHandler.HandleGetProgress)
+	authorized.GET("/me/continue-watching", progressHandler.HandleContinueWatching)
+	//History handlers
+	authorized.GET("/me/history", historyHandler.HandleGetHistory)
+	authorized.POST("/me/history", historyHandler.HandleAddHistory)
+	authorized.DELETE("/me/history/:id", historyHandler.HandleDeleteHistory)
+	//Stats handlers
+	authorized.GET("/me/stats", statsHandler.HandleGetMyStats)
+	//Recommendation handlers
+	authorized.GET("/me/recommendations", recommendationsHandler.HandleGetRecommendations)
+	//Chart handlers
+	authorized.GET("/charts/trending", chartsHandler.HandleGetTrending)
+	authorized.GET("/charts/top-rated", chartsHandler.HandleGetTopRated)
+	//Video handlers
+	authorized.GET("/movies/:id/stream/*file", videosHandler.HandleStream)
+	videos := authorized.Group("")
+	videos.Use(middlewares.RoleMiddleware(usersRepository, models.RoleEditor, models.RoleAdmin))
+	videos.POST("/movies/:id/video", videosHandler.HandleUpload)
+	videos.GET("/movies/:id/video", videosHandler.HandleGetStatus)
 	//Genre handlers
 	authorized.POST("/genres", genresHandler.Create)
 	authorized.GET("/genres/:id", genresHandler.FindById)
 	authorized.GET("/genres", genresHandler.FindAll)
 	authorized.PUT("/genres/:id", genresHandler.Update)
 	authorized.DELETE("/genres/:id", genresHandler.Delete)
+	authorized.POST("/genres/:id/follow", genresHandler.HandleFollow)
+	authorized.DELETE("/genres/:id/follow", genresHandler.HandleUnfollow)
+	authorized.GET("/me/followed-genres", genresHandler.HandleGetFollowed)
+	//Tag handlers
+	authorized.POST("/tags", tagsHandler.Create)
+	authorized.GET("/tags/:id", tagsHandler.FindById)
+	authorized.GET("/tags", tagsHandler.FindAll)
+	authorized.PUT("/tags/:id", tagsHandler.Update)
+	authorized.DELETE("/tags/:id", tagsHandler.Delete)
+	authorized.POST("/tags/:id/merge", tagsHandler.HandleMerge)
+	//Collection handlers
+	authorized.POST("/collections", collectionsHandler.Create)
+	authorized.GET("/collections/:id", collectionsHandler.FindById)
+	authorized.GET("/collections", collectionsHandler.FindAll)
+	authorized.PUT("/collections/:id", collectionsHandler.Update)
+	authorized.DELETE("/collections/:id", collectionsHandler.Delete)
+	authorized.PUT("/collections/:id/movies", collectionsHandler.HandleSetMovies)
+	authorized.POST("/collections/:id/movies/:movieId", collectionsHandler.HandleAddMovie)
+	authorized.DELETE("/collections/:id/movies/:movieId", collectionsHandler.HandleRemoveMovie)
+	//Translation handlers
+	authorized.GET("/movies/:id/translations", translationsHandler.HandleGetMovieTranslations)
+	authorized.PUT("/movies/:id/translations/:lang", translationsHandler.HandleSetMovieTranslation)
+	authorized.DELETE("/movies/:id/translations/:lang", translationsHandler.HandleDeleteMovieTranslation)
+	authorized.GET("/genres/:id/translations", translationsHandler.HandleGetGenreTranslations)
+	authorized.PUT("/genres/:id/translations/:lang", translationsHandler.HandleSetGenreTranslation)
+	authorized.DELETE("/genres/:id/translations/:lang", translationsHandler.HandleDeleteGenreTranslation)
 	//Watchlist handlers
 	authorized.GET("/watchlist", watchlistHandlers.HandleGetMovies)
 	authorized.DELETE("/watchlist/:movieId", watchlistHandlers.HandleRemoveMovie)
 	authorized.POST("/watchlist/:movieId", watchlistHandlers.HandleAddMovie)
+	authorized.PATCH("/watchlist/:movieId", watchlistHandlers.HandleUpdateMovie)
+	authorized.POST("/watchlist/:movieId/move", watchlistHandlers.HandleMoveMovie)
+	authorized.GET("/me/reminders", watchlistHandlers.HandleGetDueReminders)
+	//List handlers
+	authorized.GET("/lists", listsHandler.FindAll)
+	authorized.POST("/lists", listsHandler.Create)
+	authorized.GET("/lists/:id", listsHandler.FindById)
+	authorized.PUT("/lists/:id", listsHandler.Update)
+	authorized.DELETE("/lists/:id", listsHandler.Delete)
+	authorized.POST("/lists/:id/movies/:movieId", listsHandler.HandleAddMovie)
+	authorized.PATCH("/lists/:id/movies/:movieId", listsHandler.HandleUpdateEntry)
+	authorized.POST("/lists/:id/movies/:movieId/move", listsHandler.HandleMoveMovie)
+	authorized.DELETE("/lists/:id/movies/:movieId", listsHandler.HandleRemoveMovie)
+	authorized.POST("/lists/:id/share", listsHandler.HandleShare)
+	authorized.DELETE("/lists/:id/share", listsHandler.HandleRevokeShare)
+	//Review handlers
+	authorized.GET("/movies/:id/reviews", reviewsHandler.HandleGetMovieReviews)
+	authorized.GET("/movies/:id/review", reviewsHandler.HandleGetMyReview)
+	authorized.PUT("/movies/:id/review", reviewsHandler.HandleSaveMyReview)
+	authorized.DELETE("/movies/:id/review", reviewsHandler.HandleDeleteMyReview)
+	authorized.GET("/movies/:id/review/history", reviewsHandler.HandleGetMyReviewHistory)
+	authorized.POST("/reviews/:id/helpful", reviewsHandler.HandleMarkHelpful)
+	authorized.DELETE("/reviews/:id/helpful", reviewsHandler.HandleUnmarkHelpful)
+	//Comment handlers
+	authorized.GET("/movies/:id/comments", commentsHandler.HandleGetMovieComments)
+	authorized.POST("/movies/:id/comments", commentsHandler.HandleCreateComment)
+	authorized.DELETE("/comments/:id", commentsHandler.HandleDeleteComment)
+	authorized.PUT("/comments/:id/reaction", commentsHandler.HandleSetReaction)
+	authorized.DELETE("/comments/:id/reaction", commentsHandler.HandleRemoveReaction)
+	authorized.POST("/comments/:id/report", commentsHandler.HandleReportComment)
+	//Social handlers
+	authorized.POST("/follows/:id", socialHandler.HandleFollow)
+	authorized.DELETE("/follows/:id", socialHandler.HandleUnfollow)
+	authorized.GET("/me/following", socialHandler.HandleGetFollowing)
+	authorized.GET("/me/followers", socialHandler.HandleGetFollowers)
+	authorized.GET("/me/privacy", socialHandler.HandleGetPrivacy)
+	authorized.PATCH("/me/privacy", socialHandler.HandleSetPrivacy)
+	authorized.GET("/me/feed", socialHandler.HandleGetFeed)
+	//Email handlers
+	authorized.GET("/me/email-preferences", emailHandler.HandleGetEmailPreferences)
+	authorized.PATCH("/me/email-preferences", emailHandler.HandleSetEmailPreferences)
+	//Notification handlers
+	authorized.GET("/me/notifications", notificationsHandler.HandleGetNotifications)
+	authorized.GET("/me/notifications/unread-count", notificationsHandler.HandleGetUnreadCount)
+	authorized.POST("/me/notifications/read-all", notificationsHandler.HandleMarkAllRead)
+	authorized.POST("/me/notifications/:id/read", notificationsHandler.HandleMarkRead)
+	//Watch party handlers
+	authorized.POST("/watch-parties", watchPartiesHandler.HandleCreate)
+	authorized.GET("/watch-parties/:code", watchPartiesHandler.HandleGet)
 	//Users handlers
 	authorized.POST("/users", userHandlers.Create)
 	authorized.GET("/users", userHandlers.FindAll)
@@ -107,16 +293,77 @@ func main() {
 	authorized.PUT("/users/:id", userHandlers.Update)
 	authorized.PATCH("/users/:id/changePassword", userHandlers.ChangePassword)
 	authorized.DELETE("/users/:id", userHandlers.Delete)
+	//Profile handlers
+	authorized.GET("/profiles", profilesHandler.FindAll)
+	authorized.POST("/profiles", profilesHandler.Create)
+	authorized.PUT("/profiles/:id", profilesHandler.Update)
+	authorized.DELETE("/profiles/:id", profilesHandler.Delete)
+	authorized.POST("/profiles/:id/avatar", profilesHandler.HandleUploadAvatar)
+	authorized.POST("/profiles/:id/select", profilesHandler.HandleSelect)
 	authorized.POST("/auth/signOut", authHandlers.SignOut)
 	authorized.GET("auth/userInfo", authHandlers.GetUserInfo)
+	//Admin handlers
+	admin := authorized.Group("/admin")
+	admin.Use(middlewares.RoleMiddleware(usersRepository, models.RoleAdmin))
+	admin.GET("/stats", statsHandler.HandleGetAllStats)
+	admin.PATCH("/users/:id/role", userHandlers.ChangeRole)
+	admin.GET("/webhooks", webhooksHandler.HandleGetWebhooks)
+	admin.POST("/webhooks", webhooksHandler.HandleCreateWebhook)
+	admin.PUT("/webhooks/:id", webhooksHandler.HandleUpdateWebhook)
+	admin.DELETE("/webhooks/:id", webhooksHandler.HandleDeleteWebhook)
+	admin.GET("/webhooks/:id/deliveries", webhooksHandler.HandleGetWebhookDeliveries)
+	admin.GET("/webhook-deliveries", webhooksHandler.HandleGetDeliveries)
+	admin.POST("/webhook-deliveries/:id/redeliver", webhooksHandler.HandleRedeliver)
+	admin.GET("/jobs", jobsHandler.HandleGetJobs)
+	admin.GET("/jobs/counts", jobsHandler.HandleGetJobCounts)
+	admin.POST("/jobs/:id/retry", jobsHandler.HandleRetryJob)
+	admin.GET("/outbox/dead-events", outboxHandler.HandleGetDeadEvents)
+	admin.POST("/outbox/dead-events/:id/retry", outboxHandler.HandleRetryDeadEvent)
+	//Moderation handlers
+	moderation := authorized.Group("/moderation")
+	moderation.Use(middlewares.RoleMiddleware(usersRepository, models.RoleEditor, models.RoleAdmin))
+	moderation.GET("/reviews", reviewsHandler.HandleGetModerationQueue)
+	moderation.GET("/reviews/:id/history", reviewsHandler.HandleGetReviewHistory)
+	moderation.POST("/reviews/:id/approve", reviewsHandler.HandleApprove)
+	moderation.POST("/reviews/:id/reject", reviewsHandler.HandleReject)
+	moderation.GET("/comments", commentsHandler.HandleGetFlaggedComments)
+	moderation.POST("/comments/:id/approve", commentsHandler.HandleApproveComment)
+	moderation.POST("/comments/:id/reject", commentsHandler.HandleRejectComment)
+	moderation.GET("/blocklist", commentsHandler.HandleGetBlocklist)
+	moderation.POST("/blocklist", commentsHandler.HandleAddBlockedWord)
+	moderation.DELETE("/blocklist/:id", commentsHandler.HandleDeleteBlockedWord)
+	//Event and watch party stream handlers
+	streams := r.Group("")
+	streams.Use(middlewares.StreamAuthMiddleware, middlewares.ProfileMiddleware(profilesRepository))
+	streams.GET("/events", eventsHandler.HandleStream)
+	streams.GET("/watch-parties/:code/ws", watchPartiesHandler.HandleJoin)
 	//Authorization handlers
 	unauthorized := r.Group("")
 	unauthorized.POST("/auth/signIn", authHandlers.SignIn)
+	unauthorized.GET("/auth/verify-email", authHandlers.HandleVerifyEmail)
+	unauthorized.POST("/auth/password-reset/request", authHandlers.HandleRequestPasswordReset)
+	unauthorized.GET("/auth/password-reset", authHandlers.HandleCheckPasswordReset)
+	unauthorized.POST("/auth/password-reset", authHandlers.HandleResetPassword)
 	unauthorized.GET("/images/:imageId", imageHandler.HandleGetImageById)
+	unauthorized.GET("/shared/lists/:token", listsHandler.HandleGetShared)
+	unauthorized.GET("/unsubscribe", emailHandler.HandleGetUnsubscribe)
+	unauthorized.POST("/unsubscribe", emailHandler.HandleUnsubscribe)
 
 	docs.SwaggerInfo.BasePath = "/"
 	unauthorized.GET("/swagger/*any", swagger.WrapHandler(swaggerfiles.Handler))
 
+	recommender.Register(jobRunner, config.Config.RecommendationsInterval)
+	chartsMaterializer.Register(jobRunner)
+	outboxRelay.Register(jobRunner)
+	videoPipeline.Register(jobRunner)
+	emailNotifier.Register(jobRunner)
+	notificationsInbox.Register(jobRunner)
+
+	go jobRunner.Run(context.Background())
+	go watchPartyHub.Run(context.Background())
+	go webhookDispatcher.Run(context.Background())
+	go outboxRelay.Run(context.Background())
+
 	logger.Info("Application starting...")
 
 	r.Run(config.Config.AppHost)
@@ -11,6 +11,15 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	accessTokenParam = "access_token"
+	accessTokenKey   = "accessToken"
+	// AccessTokenProtocol is the WebSocket subprotocol that carries the
+	// access token: browsers open the socket with the protocols
+	// ["access_token", token] and the server selects access_token.
+	AccessTokenProtocol = "access_token"
+)
+
 func AuthMiddleware(c *gin.Context) {
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
@@ -21,6 +30,57 @@ func AuthMiddleware(c *gin.Context) {
 
 	//tokenString := strings.Split(authHeader, "Bearer ")[1]
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	authenticate(c, tokenString)
+}
+
+// StreamAuthMiddleware authenticates the streaming endpoints. Browsers can
+// not set the Authorization header on EventSource requests and WebSocket
+// handshakes, so besides the header the token is accepted from the
+// access_token query parameter and the Sec-WebSocket-Protocol header.
+func StreamAuthMiddleware(c *gin.Context) {
+	if c.GetHeader("Authorization") != "" {
+		AuthMiddleware(c)
+		return
+	}
+
+	tokenString := c.GetString(accessTokenKey)
+	if tokenString == "" {
+		tokenString = protocolToken(c.GetHeader("Sec-WebSocket-Protocol"))
+	}
+	if tokenString == "" {
+		c.JSON(http.StatusUnauthorized, models.NewApiError("access token required"))
+		c.Abort()
+		return
+	}
+	authenticate(c, tokenString)
+}
+
+// AccessTokenMiddleware takes the access_token query parameter out of the
+// request URL, so that the token does not end up in the request log, and
+// keeps it for StreamAuthMiddleware. It has to run before the logger.
+func AccessTokenMiddleware(c *gin.Context) {
+	query := c.Request.URL.Query()
+	if token := query.Get(accessTokenParam); token != "" {
+		c.Set(accessTokenKey, token)
+		query.Del(accessTokenParam)
+		c.Request.URL.RawQuery = query.Encode()
+	}
+	c.Next()
+}
+
+// protocolToken returns the protocol that follows access_token in the
+// Sec-WebSocket-Protocol header.
+func protocolToken(header string) string {
+	protocols := strings.Split(header, ",")
+	for i := 0; i < len(protocols)-1; i++ {
+		if strings.TrimSpace(protocols[i]) == AccessTokenProtocol {
+			return strings.TrimSpace(protocols[i+1])
+		}
+	}
+	return ""
+}
+
+func authenticate(c *gin.Context, tokenString string) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		return []byte(config.Config.JwtSecretKey), nil
 	})
@@ -38,5 +98,13 @@ func AuthMiddleware(c *gin.Context) {
 	}
 	userId, _ := strconv.Atoi(subject)
 	c.Set("userId", userId)
+
+	profileId := 0
+	if claims, ok := token.Claims.(jwt.MapClaims); ok {
+		if v, ok := claims["profileId"].(float64); ok {
+			profileId = int(v)
+		}
+	}
+	c.Set("profileId", profileId)
 	c.Next()
 }
@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"goozinshe/models"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LanguageMiddleware resolves the response language from the "lang" query
+// parameter or the Accept-Language header and stores it under "lang".
+func LanguageMiddleware(c *gin.Context) {
+	lang := strings.ToLower(c.Query("lang"))
+	if !models.IsSupportedLanguage(lang) {
+		lang = parseAcceptLanguage(c.GetHeader("Accept-Language"))
+	}
+	c.Set("lang", lang)
+	c.Next()
+}
+
+type acceptedLanguage struct {
+	tag     string
+	quality float64
+}
+
+func parseAcceptLanguage(header string) string {
+	accepted := make([]acceptedLanguage, 0)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err == nil {
+					quality = q
+				}
+			}
+		}
+		accepted = append(accepted, acceptedLanguage{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+	for _, a := range accepted {
+		primary := strings.Split(a.tag, "-")[0]
+		if models.IsSupportedLanguage(primary) {
+			return primary
+		}
+	}
+	return models.SupportedLanguages[0]
+}
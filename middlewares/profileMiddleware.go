@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileMiddleware loads the profile selected in the token, or the account's
+// default profile when none is selected, and stores its id under "profileId"
+// and its parental-control limit under "maxAgeRating". It must run after AuthMiddleware.
+func ProfileMiddleware(repo *repositories.ProfilesRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.GetInt("userId")
+		profileId := c.GetInt("profileId")
+
+		var profile models.Profile
+		var err error
+		if profileId == 0 {
+			profile, err = repo.FindDefaultByUserId(c, userId)
+		} else {
+			profile, err = repo.FindById(c, profileId)
+		}
+		if err != nil || profile.UserId != userId {
+			c.JSON(http.StatusUnauthorized, models.NewApiError("invalid profile"))
+			c.Abort()
+			return
+		}
+
+		c.Set("profileId", profile.Id)
+		c.Set("maxAgeRating", profile.MaxAgeRating)
+		c.Next()
+	}
+}
@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleMiddleware lets the request through only when the signed in user has
+// one of the given roles. It must run after AuthMiddleware.
+func RoleMiddleware(repo *repositories.UsersRepository, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := repo.FindById(c, c.GetInt("userId"))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.NewApiError("user not found"))
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				c.Set("role", user.Role)
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, models.NewApiError("insufficient permissions"))
+		c.Abort()
+	}
+}
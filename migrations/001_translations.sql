@@ -0,0 +1,16 @@
+create table if not exists movie_translations
+(
+    movie_id    int         not null references movies (id) on delete cascade,
+    language    varchar(2)  not null,
+    title       text        not null,
+    description text        not null default '',
+    primary key (movie_id, language)
+);
+
+create table if not exists genre_translations
+(
+    genre_id int        not null references genres (id) on delete cascade,
+    language varchar(2) not null,
+    title    text       not null,
+    primary key (genre_id, language)
+);
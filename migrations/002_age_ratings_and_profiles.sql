@@ -0,0 +1,19 @@
+-- Movies nobody rated yet are treated as adults only, so that parental
+-- control fails closed; editors lower the rating of the existing catalog.
+alter table movies
+    add column if not exists age_rating int not null default 18;
+
+create table if not exists profiles
+(
+    id             serial primary key,
+    user_id        int  not null references users (id) on delete cascade,
+    name           text not null,
+    max_age_rating int  not null default 18,
+    pin_hash       text not null default '',
+    -- Wrong PINs in a row; after too many the profile can not be selected
+    -- until pin_locked_until.
+    pin_failures     int  not null default 0,
+    pin_locked_until timestamp
+);
+
+create index if not exists profiles_user_id_idx on profiles (user_id);
@@ -0,0 +1,57 @@
+alter table profiles
+    add column if not exists avatar_url text    not null default '',
+    add column if not exists is_kids    boolean not null default false,
+    add column if not exists is_default boolean not null default false;
+
+create unique index if not exists profiles_default_idx on profiles (user_id) where is_default;
+
+insert into profiles(user_id, name, is_default)
+select u.id, u.name, true
+from users u
+where not exists (select 1 from profiles p where p.user_id = u.id and p.is_default);
+
+create table if not exists profile_movies
+(
+    profile_id int     not null references profiles (id) on delete cascade,
+    movie_id   int     not null references movies (id) on delete cascade,
+    rating     int,
+    is_watched boolean not null default false,
+    primary key (profile_id, movie_id)
+);
+
+-- Ratings, watched flags and the watchlist used to be global: they were the
+-- activity of the household that ran the single-user installation. Hand them
+-- over to the default profile of the first account, which is that household,
+-- instead of showing them in every account.
+create temporary table legacy_owner as
+select p.id as profile_id
+from profiles p
+where p.is_default
+order by p.user_id
+limit 1;
+
+insert into profile_movies(profile_id, movie_id, rating, is_watched)
+select o.profile_id, m.id, nullif(m.rating, 0), m.is_watched
+from movies m
+cross join legacy_owner o
+where m.rating > 0 or m.is_watched;
+
+alter table movies
+    drop column if exists rating,
+    drop column if exists is_watched;
+
+alter table watchlist
+    add column if not exists profile_id int references profiles (id) on delete cascade;
+
+insert into watchlist(profile_id, movie_id, added_at)
+select o.profile_id, wl.movie_id, wl.added_at
+from watchlist wl
+cross join legacy_owner o
+where wl.profile_id is null;
+
+delete from watchlist where profile_id is null;
+
+alter table watchlist
+    alter column profile_id set not null;
+
+drop table legacy_owner;
@@ -0,0 +1,16 @@
+create table if not exists tags
+(
+    id   serial primary key,
+    name text not null
+);
+
+create unique index if not exists tags_name_idx on tags (lower(name));
+
+create table if not exists movies_tags
+(
+    movie_id int not null references movies (id) on delete cascade,
+    tag_id   int not null references tags (id) on delete cascade,
+    primary key (movie_id, tag_id)
+);
+
+create index if not exists movies_tags_tag_id_idx on movies_tags (tag_id);
@@ -0,0 +1,17 @@
+create table if not exists collections
+(
+    id          serial primary key,
+    title       text not null,
+    description text not null default '',
+    cover_url   text not null default ''
+);
+
+create table if not exists collections_movies
+(
+    collection_id int not null references collections (id) on delete cascade,
+    movie_id      int not null references movies (id) on delete cascade,
+    position      int not null,
+    primary key (collection_id, movie_id)
+);
+
+create index if not exists collections_movies_movie_id_idx on collections_movies (movie_id);
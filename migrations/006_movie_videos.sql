@@ -0,0 +1,12 @@
+-- Every upload gets the next version and is packaged into its own directory,
+-- so segment URLs never change their content. published_version is the
+-- version being streamed, 0 until the first upload is ready.
+create table if not exists movie_videos
+(
+    movie_id          int primary key references movies (id) on delete cascade,
+    status            varchar(16) not null,
+    error             text        not null default '',
+    version           int         not null default 0,
+    published_version int         not null default 0,
+    updated_at        timestamp   not null default now()
+);
@@ -0,0 +1,12 @@
+create table if not exists playback_progress
+(
+    profile_id       int       not null references profiles (id) on delete cascade,
+    movie_id         int       not null references movies (id) on delete cascade,
+    position_seconds int       not null,
+    duration_seconds int       not null,
+    completed        boolean   not null default false,
+    updated_at       timestamp not null default now(),
+    primary key (profile_id, movie_id)
+);
+
+create index if not exists playback_progress_recent_idx on playback_progress (profile_id, updated_at desc);
@@ -0,0 +1,10 @@
+create table if not exists watch_events
+(
+    id         serial primary key,
+    profile_id int       not null references profiles (id) on delete cascade,
+    movie_id   int       not null references movies (id) on delete cascade,
+    watched_at timestamp not null,
+    created_at timestamp not null default now()
+);
+
+create index if not exists watch_events_profile_idx on watch_events (profile_id, watched_at desc);
@@ -0,0 +1,9 @@
+alter table movies
+    add column if not exists runtime_minutes int not null default 0;
+
+alter table users
+    add column if not exists role varchar(16) not null default 'user';
+
+update users set role = 'admin' where email = 'admin@admin.com';
+
+create index if not exists watch_events_watched_at_idx on watch_events (watched_at);
@@ -0,0 +1,7 @@
+create table if not exists movie_neighbours
+(
+    movie_id     int              not null references movies (id) on delete cascade,
+    neighbour_id int              not null references movies (id) on delete cascade,
+    similarity   double precision not null,
+    primary key (movie_id, neighbour_id)
+);
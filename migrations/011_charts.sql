@@ -0,0 +1,31 @@
+-- Ratings given before rated_at existed have no date, stay null and never
+-- count as trending.
+alter table profile_movies
+    add column if not exists rated_at timestamp;
+
+create index if not exists profile_movies_rated_at_idx on profile_movies (rated_at) where rating is not null;
+create index if not exists watchlist_added_at_idx on watchlist (added_at);
+
+create table if not exists chart_trending
+(
+    movie_id int              not null references movies (id) on delete cascade primary key,
+    score    double precision not null,
+    position int              not null
+);
+
+create table if not exists chart_top_rated
+(
+    scope         varchar(16)      not null,
+    scope_id      int              not null,
+    movie_id      int              not null references movies (id) on delete cascade,
+    average       double precision not null,
+    ratings_count int              not null,
+    position      int              not null,
+    primary key (scope, scope_id, movie_id)
+);
+
+create table if not exists chart_refreshes
+(
+    id          int primary key default 1 check (id = 1),
+    computed_at timestamp not null
+);
@@ -0,0 +1,44 @@
+create table if not exists lists
+(
+    id         serial primary key,
+    profile_id int       not null references profiles (id) on delete cascade,
+    name       text      not null,
+    is_public  boolean   not null default false,
+    is_default boolean   not null default false,
+    created_at timestamp not null default now()
+);
+
+create unique index if not exists lists_default_idx on lists (profile_id) where is_default;
+
+create table if not exists list_movies
+(
+    list_id  int       not null references lists (id) on delete cascade,
+    movie_id int       not null references movies (id) on delete cascade,
+    note     text      not null default '',
+    position int       not null,
+    added_at timestamp not null default now(),
+    primary key (list_id, movie_id)
+);
+
+create index if not exists list_movies_movie_id_idx on list_movies (movie_id);
+create index if not exists list_movies_added_at_idx on list_movies (added_at);
+
+-- The old single watchlist becomes the default list of every profile that had one.
+insert into lists(profile_id, name, is_default)
+select distinct wl.profile_id, 'Watchlist', true
+from watchlist wl
+where wl.profile_id is not null
+  and not exists (select 1 from lists l where l.profile_id = wl.profile_id and l.is_default);
+
+insert into list_movies(list_id, movie_id, position, added_at)
+select l.id, w.movie_id, row_number() over (partition by l.id order by w.added_at, w.movie_id), w.added_at
+from (
+    select profile_id, movie_id, min(added_at) as added_at
+    from watchlist
+    where profile_id is not null
+    group by profile_id, movie_id
+) w
+join lists l on l.profile_id = w.profile_id and l.is_default
+on conflict (list_id, movie_id) do nothing;
+
+drop table if exists watchlist;
@@ -0,0 +1,2 @@
+alter table lists
+    add column if not exists share_token text unique;
@@ -0,0 +1,8 @@
+alter table list_movies
+    add column if not exists priority  smallint not null default 2,
+    add column if not exists remind_at date;
+
+create index if not exists list_movies_remind_at_idx on list_movies (remind_at) where remind_at is not null;
+
+alter table profiles
+    add column if not exists auto_remove_watched boolean not null default true;
@@ -0,0 +1,38 @@
+create table if not exists reviews
+(
+    id               serial primary key,
+    movie_id         int         not null references movies (id) on delete cascade,
+    profile_id       int         not null references profiles (id) on delete cascade,
+    title            text        not null,
+    body             text        not null,
+    is_spoiler       boolean     not null default false,
+    status           varchar(16) not null default 'pending',
+    rejection_reason text        not null default '',
+    moderated_by     int         references users (id) on delete set null,
+    moderated_at     timestamp,
+    created_at       timestamp   not null default now(),
+    updated_at       timestamp   not null default now(),
+    unique (profile_id, movie_id)
+);
+
+create index if not exists reviews_movie_status_idx on reviews (movie_id, status);
+create index if not exists reviews_pending_idx on reviews (updated_at) where status = 'pending';
+
+create table if not exists review_revisions
+(
+    id         serial primary key,
+    review_id  int       not null references reviews (id) on delete cascade,
+    title      text      not null,
+    body       text      not null,
+    is_spoiler boolean   not null,
+    edited_at  timestamp not null
+);
+
+create index if not exists review_revisions_review_id_idx on review_revisions (review_id);
+
+create table if not exists review_votes
+(
+    review_id  int not null references reviews (id) on delete cascade,
+    profile_id int not null references profiles (id) on delete cascade,
+    primary key (review_id, profile_id)
+);
@@ -0,0 +1,48 @@
+create table if not exists comments
+(
+    id            serial primary key,
+    movie_id      int         not null references movies (id) on delete cascade,
+    profile_id    int         not null references profiles (id) on delete cascade,
+    parent_id     int references comments (id) on delete cascade,
+    language      varchar(8)  not null,
+    body          text        not null,
+    status        varchar(16) not null default 'published',
+    flagged_words text[]      not null default '{}',
+    moderated_by  int         references users (id) on delete set null,
+    moderated_at  timestamp,
+    deleted_at    timestamp,
+    created_at    timestamp   not null default now()
+);
+
+create index if not exists comments_movie_id_idx on comments (movie_id, created_at) where parent_id is null;
+create index if not exists comments_parent_id_idx on comments (parent_id);
+create index if not exists comments_held_idx on comments (created_at) where status = 'held';
+
+create table if not exists comment_reactions
+(
+    comment_id int      not null references comments (id) on delete cascade,
+    profile_id int      not null references profiles (id) on delete cascade,
+    value      smallint not null check (value in (-1, 1)),
+    primary key (comment_id, profile_id)
+);
+
+create table if not exists comment_reports
+(
+    id          serial primary key,
+    comment_id  int       not null references comments (id) on delete cascade,
+    profile_id  int       not null references profiles (id) on delete cascade,
+    reason      text      not null,
+    created_at  timestamp not null default now(),
+    resolved_at timestamp,
+    unique (comment_id, profile_id)
+);
+
+create index if not exists comment_reports_open_idx on comment_reports (comment_id) where resolved_at is null;
+
+create table if not exists comment_blocklist
+(
+    id       serial primary key,
+    language varchar(8) not null,
+    word     text       not null,
+    unique (language, word)
+);
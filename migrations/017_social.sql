@@ -0,0 +1,34 @@
+create table if not exists follows
+(
+    follower_id int       not null references profiles (id) on delete cascade,
+    followee_id int       not null references profiles (id) on delete cascade,
+    created_at  timestamp not null default now(),
+    primary key (follower_id, followee_id),
+    check (follower_id <> followee_id)
+);
+
+create index if not exists follows_followee_id_idx on follows (followee_id);
+
+alter table profiles
+    add column if not exists publish_ratings boolean not null default true,
+    add column if not exists publish_watched boolean not null default true,
+    add column if not exists publish_lists   boolean not null default true,
+    add column if not exists publish_reviews boolean not null default true;
+
+create table if not exists activity_events
+(
+    id         bigserial primary key,
+    profile_id int         not null references profiles (id) on delete cascade,
+    type       varchar(16) not null,
+    movie_id   int         not null references movies (id) on delete cascade,
+    list_id    int references lists (id) on delete cascade,
+    review_id  int references reviews (id) on delete cascade,
+    rating     smallint,
+    created_at timestamp   not null default now()
+);
+
+-- Repeating an action (re-rating a movie, re-adding it to a list) moves the
+-- existing event to the top of the feed instead of adding another one.
+create unique index if not exists activity_events_action_idx
+    on activity_events (profile_id, type, movie_id, coalesce(list_id, 0));
+create index if not exists activity_events_profile_id_idx on activity_events (profile_id, created_at desc);
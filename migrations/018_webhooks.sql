@@ -0,0 +1,30 @@
+create table if not exists webhooks
+(
+    id          serial primary key,
+    url         text      not null,
+    secret      text      not null,
+    -- Event types the subscriber wants; empty means all of them.
+    events      text[]    not null default '{}',
+    description text      not null default '',
+    is_active   boolean   not null default true,
+    created_at  timestamp not null default now()
+);
+
+create table if not exists webhook_deliveries
+(
+    id               bigserial primary key,
+    webhook_id       int         not null references webhooks (id) on delete cascade,
+    event_id         uuid        not null,
+    event_type       varchar(32) not null,
+    payload          jsonb       not null,
+    status           varchar(16) not null default 'pending',
+    attempts         int         not null default 0,
+    next_attempt_at  timestamp   not null default now(),
+    last_status_code int,
+    last_error       text        not null default '',
+    created_at       timestamp   not null default now(),
+    delivered_at     timestamp
+);
+
+create index if not exists webhook_deliveries_due_idx on webhook_deliveries (next_attempt_at) where status = 'pending';
+create index if not exists webhook_deliveries_webhook_id_idx on webhook_deliveries (webhook_id, created_at desc);
@@ -0,0 +1,21 @@
+create table if not exists outbox_events
+(
+    id           bigserial primary key,
+    event_id     uuid        not null unique,
+    type         varchar(32) not null,
+    payload      jsonb       not null,
+    attempts     int         not null default 0,
+    last_error   text        not null default '',
+    created_at   timestamp   not null default now(),
+    published_at timestamp,
+    -- An event that keeps failing is parked after OUTBOX_MAX_ATTEMPTS, so
+    -- that it does not hold back the events after it.
+    dead_at      timestamp
+);
+
+create index if not exists outbox_events_pending_idx on outbox_events (id) where published_at is null and dead_at is null;
+create index if not exists outbox_events_dead_idx on outbox_events (dead_at) where dead_at is not null;
+
+-- The outbox relay delivers events at least once; a repeated event must not
+-- queue a second webhook delivery.
+create unique index if not exists webhook_deliveries_event_idx on webhook_deliveries (webhook_id, event_id);
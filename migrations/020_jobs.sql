@@ -0,0 +1,25 @@
+create table if not exists jobs
+(
+    id           bigserial primary key,
+    queue        varchar(32) not null,
+    type         varchar(64) not null,
+    payload      jsonb       not null default '{}',
+    status       varchar(16) not null default 'pending',
+    attempts     int         not null default 0,
+    max_attempts int         not null,
+    run_at       timestamp   not null default now(),
+    -- A running job whose lease has expired is considered abandoned by a
+    -- crashed worker and is picked up again.
+    locked_until timestamp,
+    last_error   text        not null default '',
+    -- Scheduled jobs are enqueued by every instance under the same key; only
+    -- the first one is kept.
+    unique_key   text,
+    created_at   timestamp   not null default now(),
+    started_at   timestamp,
+    finished_at  timestamp
+);
+
+create unique index if not exists jobs_unique_key_idx on jobs (unique_key) where unique_key is not null;
+create index if not exists jobs_due_idx on jobs (queue, run_at) where status in ('pending', 'running');
+create index if not exists jobs_created_at_idx on jobs (created_at desc);
@@ -0,0 +1,22 @@
+-- Users without a row get the defaults: the first supported language and
+-- every optional email enabled.
+create table if not exists email_preferences
+(
+    user_id       int primary key references users (id) on delete cascade,
+    language      varchar(2) not null,
+    new_releases  boolean    not null default true,
+    weekly_digest boolean    not null default true,
+    updated_at    timestamp  not null default now()
+);
+
+-- The weekly digest lists the movies added to the catalog since the last one.
+-- It is not known when the existing movies were added, so they are left
+-- without a date and never count as new.
+alter table movies
+    add column if not exists created_at timestamp;
+alter table movies
+    alter column created_at set default now();
+
+-- Set once the user opens the link of the verification email.
+alter table users
+    add column if not exists email_verified_at timestamp;
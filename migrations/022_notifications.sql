@@ -0,0 +1,30 @@
+create table if not exists notifications
+(
+    id         bigserial primary key,
+    profile_id int         not null references profiles (id) on delete cascade,
+    type       varchar(32) not null,
+    movie_id   int         not null references movies (id) on delete cascade,
+    review_id  int         references reviews (id) on delete cascade,
+    -- The outbox event the notification was produced from; a repeated event
+    -- must not notify the profile twice.
+    event_id   uuid        not null,
+    read_at    timestamp,
+    created_at timestamp   not null default now()
+);
+
+create unique index if not exists notifications_event_idx on notifications (profile_id, event_id);
+create index if not exists notifications_profile_idx on notifications (profile_id, created_at desc);
+create index if not exists notifications_unread_idx on notifications (profile_id) where read_at is null;
+create index if not exists notifications_created_at_idx on notifications (created_at);
+
+-- Genres a profile asked to hear about: a new movie in one of them shows up
+-- in the profile's notifications.
+create table if not exists genre_follows
+(
+    profile_id int       not null references profiles (id) on delete cascade,
+    genre_id   int       not null references genres (id) on delete cascade,
+    created_at timestamp not null default now(),
+    primary key (profile_id, genre_id)
+);
+
+create index if not exists genre_follows_genre_idx on genre_follows (genre_id);
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+const (
+	ChartScopeAll    = "all"
+	ChartScopeGenre  = "genre"
+	ChartScopeDecade = "decade"
+)
+
+// TrendingWeights is how much a single watchlist add, rating or view adds to
+// a movie's trending score.
+type TrendingWeights struct {
+	Watchlist float64
+	Rating    float64
+	View      float64
+}
+
+// Score is the trending score of the movie's activity.
+func (w TrendingWeights) Score(activity TrendingActivity) float64 {
+	return float64(activity.WatchlistAdds)*w.Watchlist +
+		float64(activity.Ratings)*w.Rating +
+		float64(activity.Views)*w.View
+}
+
+// TrendingActivity counts what happened to a movie within the trending window.
+type TrendingActivity struct {
+	MovieId       int
+	WatchlistAdds int
+	Ratings       int
+	Views         int
+}
+
+type TrendingScore struct {
+	MovieId int
+	Score   float64
+}
+
+type ChartEntry struct {
+	Position     int
+	Score        float64
+	RatingsCount int `json:",omitempty"`
+	MovieId      int `json:"-"`
+	Movie        Movie
+}
+
+type Chart struct {
+	ComputedAt time.Time
+	Entries    []ChartEntry
+}
@@ -0,0 +1,13 @@
+package models
+
+type Collection struct {
+	Id          int
+	Title       string
+	Description string
+	CoverUrl    string
+}
+
+type CollectionWithMovies struct {
+	Collection
+	Movies []Movie
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+const (
+	CommentStatusPublished = "published"
+	// CommentStatusHeld comments matched the language blocklist and are only
+	// visible to their author until a moderator approves them.
+	CommentStatusHeld     = "held"
+	CommentStatusRejected = "rejected"
+)
+
+const (
+	ReactionLike    = "like"
+	ReactionDislike = "dislike"
+)
+
+// Comment is a node of a movie discussion thread. Deleted and rejected
+// comments stay in the thread as placeholders without author and body so
+// that their replies keep their place.
+type Comment struct {
+	Id         int
+	MovieId    int
+	ParentId   *int
+	ProfileId  int
+	AuthorName string
+	Language   string
+	Body       string
+	Status     string
+	IsDeleted  bool
+	Likes      int
+	Dislikes   int
+	MyReaction string `json:",omitempty"`
+	CreatedAt  time.Time
+	Replies    []Comment
+}
+
+func (c Comment) IsRemoved() bool {
+	return c.IsDeleted || c.Status == CommentStatusRejected
+}
+
+type CommentReport struct {
+	Id        int
+	ProfileId int
+	Reason    string
+	CreatedAt time.Time
+}
+
+// FlaggedComment is a comment waiting for a moderator: held by the blocklist,
+// reported by viewers or both.
+type FlaggedComment struct {
+	Comment
+	FlaggedWords []string
+	Reports      []CommentReport
+}
+
+type BlockedWord struct {
+	Id       int
+	Language string
+	Word     string
+}
@@ -0,0 +1,39 @@
+package models
+
+// Email kinds. Verification and password reset emails are always sent; the
+// other kinds can be turned off in the preferences or with the unsubscribe
+// link of the email.
+const (
+	EmailVerification  = "verification"
+	EmailPasswordReset = "password_reset"
+	EmailNewRelease    = "new_release"
+	EmailWeeklyDigest  = "weekly_digest"
+)
+
+// IsOptionalEmail reports whether users may unsubscribe from the kind.
+func IsOptionalEmail(kind string) bool {
+	return kind == EmailNewRelease || kind == EmailWeeklyDigest
+}
+
+type EmailPreferences struct {
+	// Language of the emails, one of SupportedLanguages.
+	Language     string
+	NewReleases  bool
+	WeeklyDigest bool
+}
+
+// Allows reports whether the user wants emails of the kind.
+func (p EmailPreferences) Allows(kind string) bool {
+	switch kind {
+	case EmailNewRelease:
+		return p.NewReleases
+	case EmailWeeklyDigest:
+		return p.WeeklyDigest
+	default:
+		return true
+	}
+}
+
+func DefaultEmailPreferences() EmailPreferences {
+	return EmailPreferences{Language: SupportedLanguages[0], NewReleases: true, WeeklyDigest: true}
+}
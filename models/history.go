@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type WatchEvent struct {
+	Id        int
+	MovieId   int
+	WatchedAt time.Time
+}
+
+type HistoryEntry struct {
+	Id        int
+	WatchedAt time.Time
+	Movie     Movie
+}
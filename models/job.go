@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	// JobStatusFailed jobs ran out of attempts. They are kept until retried
+	// from the admin API or cleaned up.
+	JobStatusFailed = "failed"
+)
+
+type Job struct {
+	Id          int64
+	Queue       string
+	Type        string
+	Payload     json.RawMessage `swaggertype:"object"`
+	Status      string
+	Attempts    int
+	MaxAttempts int
+	RunAt       time.Time
+	LockedUntil *time.Time
+	LastError   string
+	UniqueKey   *string
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+}
+
+// JobCount is the number of jobs of a queue in a status.
+type JobCount struct {
+	Queue  string
+	Status string
+	Count  int
+}
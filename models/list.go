@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// DefaultListName is the name of the list that backs the /watchlist routes.
+const DefaultListName = "Watchlist"
+
+const (
+	PriorityLow    = 1
+	PriorityNormal = 2
+	PriorityHigh   = 3
+)
+
+func IsValidPriority(priority int) bool {
+	return priority >= PriorityLow && priority <= PriorityHigh
+}
+
+type List struct {
+	Id          int
+	ProfileId   int
+	Name        string
+	IsPublic    bool
+	IsDefault   bool
+	MoviesCount int
+	CreatedAt   time.Time
+	// ShareToken grants read-only access without an account; empty when the
+	// list is not shared. Only its owner gets to see it.
+	ShareToken string `json:",omitempty"`
+}
+
+// ListEntry is a movie's membership in a list without the movie itself.
+type ListEntry struct {
+	MovieId  int
+	Note     string
+	Position int
+	Priority int
+	RemindAt *time.Time
+	AddedAt  time.Time
+}
+
+type ListItem struct {
+	Movie    Movie
+	Note     string
+	Position int
+	Priority int
+	// RemindAt is the date the profile asked to be reminded about the movie.
+	RemindAt *time.Time
+	AddedAt  time.Time
+}
+
+// ListEntryUpdate changes the non-nil fields of a list entry. ClearReminder
+// removes the reminder and takes precedence over RemindAt.
+type ListEntryUpdate struct {
+	Note          *string
+	Priority      *int
+	RemindAt      *time.Time
+	ClearReminder bool
+}
+
+type ListWithItems struct {
+	List
+	Items []ListItem
+}
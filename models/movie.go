@@ -3,19 +3,41 @@ package models
 type MovieFilters struct {
 	SearchTerm string
 	GenreId    string
+	Tags       string
 	IsWatched  string
 	Sort       string
+	// TagIds are the distinct ids parsed from Tags; movies must carry all of them.
+	TagIds []int `swaggerignore:"true"`
 }
 
 type Movie struct {
-	Id          int
-	Title       string
-	Description string
-	ReleaseYear int
-	Director    string
-	Rating      int
-	IsWatched   bool
-	TrailerUrl  string
-	PosterUrl   string
-	Genres      []Genre
+	Id             int
+	Title          string
+	Description    string
+	ReleaseYear    int
+	AgeRating      int
+	RuntimeMinutes int
+	Director       string
+	Rating         int
+	IsWatched      bool
+	TrailerUrl     string
+	PosterUrl      string
+	Genres         []Genre
+	Tags           []Tag
+	Collections    []Collection `json:",omitempty"`
+}
+
+// AgeRatings lists the allowed minimum viewer ages, i.e. 0+, 6+, 12+, 16+ and 18+.
+var AgeRatings = []int{0, 6, 12, 16, 18}
+
+// MaxAgeRating is the limit of profiles that are not restricted by parental control.
+const MaxAgeRating = 18
+
+func IsValidAgeRating(rating int) bool {
+	for _, r := range AgeRatings {
+		if r == rating {
+			return true
+		}
+	}
+	return false
 }
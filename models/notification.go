@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Notification types.
+const (
+	// NotificationTrailerAdded is sent to profiles that have the movie on
+	// their watchlist when it gets its first trailer.
+	NotificationTrailerAdded = "trailer_added"
+	// NotificationReviewApproved is sent to the author of an approved review.
+	NotificationReviewApproved = "review_approved"
+	// NotificationNewRelease is sent to profiles that follow one of the new
+	// movie's genres.
+	NotificationNewRelease = "new_release"
+)
+
+type Notification struct {
+	Id        int64
+	Type      string
+	MovieId   int `json:"-"`
+	Movie     Movie
+	ReviewId  *int `json:",omitempty"`
+	IsRead    bool
+	CreatedAt time.Time
+}
+
+type UnreadCount struct {
+	Unread int
+}
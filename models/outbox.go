@@ -0,0 +1,23 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent is a domain event saved in the same transaction as the change
+// it describes and published afterwards by the outbox relay.
+type OutboxEvent struct {
+	Id int64
+	// EventId identifies the event across repeated publishes, so consumers
+	// can deduplicate.
+	EventId   string
+	Type      string
+	Payload   json.RawMessage `swaggertype:"object"`
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+	// DeadAt is set when the relay gave up on the event after
+	// OUTBOX_MAX_ATTEMPTS.
+	DeadAt *time.Time
+}
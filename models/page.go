@@ -0,0 +1,8 @@
+package models
+
+type Page[T any] struct {
+	Items    []T
+	Total    int
+	Page     int
+	PageSize int
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type Profile struct {
+	Id           int
+	UserId       int
+	Name         string
+	AvatarUrl    string
+	IsKids       bool
+	IsDefault    bool
+	MaxAgeRating int
+	PinHash      string
+	// AutoRemoveWatched drops movies from the watchlist once they are marked watched.
+	AutoRemoveWatched bool
+}
+
+// KidsMaxAgeRating caps the age rating available to kids profiles.
+const KidsMaxAgeRating = 6
+
+// A profile can not be selected for PinLockout after MaxPinFailures wrong PINs in a row.
+const (
+	MaxPinFailures = 5
+	PinLockout     = 15 * time.Minute
+)
+
+func (p Profile) HasPin() bool {
+	return p.PinHash != ""
+}
+
+func (p Profile) IsRestricted() bool {
+	return p.MaxAgeRating < MaxAgeRating
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type PlaybackProgress struct {
+	MovieId         int
+	PositionSeconds int
+	DurationSeconds int
+	Completed       bool
+	UpdatedAt       time.Time
+}
+
+type ContinueWatchingEntry struct {
+	Movie    Movie
+	Progress PlaybackProgress
+}
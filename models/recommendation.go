@@ -0,0 +1,37 @@
+package models
+
+const (
+	ReasonRated          = "rated"
+	ReasonPopularInGenre = "popular_in_genre"
+	ReasonPopular        = "popular"
+)
+
+type ProfileRating struct {
+	ProfileId int
+	MovieId   int
+	Rating    int
+}
+
+// MovieNeighbour is a precomputed item-item similarity between two movies.
+type MovieNeighbour struct {
+	MovieId     int
+	NeighbourId int
+	Similarity  float64
+}
+
+// PopularMovie is a cold-start candidate; GenreId is 0 when it was picked
+// from the whole catalog rather than from a favourite genre.
+type PopularMovie struct {
+	MovieId int
+	GenreId int
+}
+
+type Recommendation struct {
+	Movie Movie
+	Score float64
+	// Reason is one of rated, popular_in_genre or popular.
+	Reason           string
+	Explanation      string
+	BecauseOfMovieId int `json:",omitempty"`
+	BecauseOfGenreId int `json:",omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+type Review struct {
+	Id              int
+	MovieId         int
+	ProfileId       int
+	AuthorName      string
+	Title           string
+	Body            string
+	IsSpoiler       bool
+	Status          string
+	RejectionReason string `json:",omitempty"`
+	HelpfulCount    int
+	IsEdited        bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// ReviewRevision is a previous version of a review, saved when it was edited.
+type ReviewRevision struct {
+	Id        int
+	Title     string
+	Body      string
+	IsSpoiler bool
+	EditedAt  time.Time
+}
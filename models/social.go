@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+const (
+	ActivityRated       = "rated"
+	ActivityWatched     = "watched"
+	ActivityWatchlisted = "watchlisted"
+	ActivityListed      = "listed"
+	ActivityReviewed    = "reviewed"
+)
+
+// PrivacySettings control which activity of a profile its followers see in
+// their feeds.
+type PrivacySettings struct {
+	PublishRatings bool
+	PublishWatched bool
+	PublishLists   bool
+	PublishReviews bool
+}
+
+type ProfileSummary struct {
+	Id        int
+	Name      string
+	AvatarUrl string
+}
+
+type Follow struct {
+	Profile    ProfileSummary
+	FollowedAt time.Time
+}
+
+// ActivityEvent is something a profile did with a movie. ListId is set for
+// additions to a public list, ReviewId for approved reviews and Rating for
+// ratings.
+type ActivityEvent struct {
+	ProfileId int
+	Type      string
+	MovieId   int
+	ListId    *int
+	ReviewId  *int
+	Rating    *int
+}
+
+type FeedItem struct {
+	Id          int64
+	Type        string
+	Profile     ProfileSummary
+	MovieId     int `json:"-"`
+	Movie       Movie
+	Rating      *int   `json:",omitempty"`
+	ListId      *int   `json:",omitempty"`
+	ListName    string `json:",omitempty"`
+	ReviewId    *int   `json:",omitempty"`
+	ReviewTitle string `json:",omitempty"`
+	CreatedAt   time.Time
+}
@@ -0,0 +1,35 @@
+package models
+
+type PeriodStats struct {
+	Period  string
+	Views   int
+	Minutes int
+}
+
+type NamedCount struct {
+	Name  string
+	Count int
+}
+
+type RatingCount struct {
+	Rating int
+	Count  int
+}
+
+type ViewingStats struct {
+	TotalViews         int
+	TotalMinutes       int
+	ByYear             []PeriodStats
+	ByMonth            []PeriodStats
+	TopGenres          []NamedCount
+	TopDirectors       []NamedCount
+	AverageRating      float64
+	RatingDistribution []RatingCount
+}
+
+// StatsFilter narrows statistics down to a profile and/or a year. Zero values mean no restriction.
+type StatsFilter struct {
+	ProfileId int
+	Year      int
+	Language  string
+}
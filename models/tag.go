@@ -0,0 +1,11 @@
+package models
+
+type Tag struct {
+	Id   int
+	Name string
+}
+
+type TagUsage struct {
+	Tag
+	UsageCount int
+}
@@ -0,0 +1,48 @@
+package models
+
+const (
+	LanguageKazakh  = "kk"
+	LanguageRussian = "ru"
+	LanguageEnglish = "en"
+)
+
+// SupportedLanguages is also the fallback order used when a translation
+// for the requested language is missing.
+var SupportedLanguages = []string{LanguageKazakh, LanguageRussian, LanguageEnglish}
+
+type MovieTranslation struct {
+	MovieId     int
+	Language    string
+	Title       string
+	Description string
+}
+
+type GenreTranslation struct {
+	GenreId  int
+	Language string
+	Title    string
+}
+
+func IsSupportedLanguage(lang string) bool {
+	for _, l := range SupportedLanguages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// LanguageFallbacks returns the languages to look translations up in,
+// starting with lang and followed by the rest of SupportedLanguages.
+func LanguageFallbacks(lang string) []string {
+	langs := make([]string, 0, len(SupportedLanguages))
+	if IsSupportedLanguage(lang) {
+		langs = append(langs, lang)
+	}
+	for _, l := range SupportedLanguages {
+		if l != lang {
+			langs = append(langs, l)
+		}
+	}
+	return langs
+}
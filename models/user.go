@@ -5,4 +5,16 @@ type User struct {
 	Name         string
 	Email        string
 	PasswordHash string
+	Role         string
+}
+
+const (
+	RoleUser = "user"
+	// RoleEditor moderates user generated content and manages movie videos.
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleEditor || role == RoleAdmin
 }
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+const (
+	VideoStatusProcessing = "processing"
+	VideoStatusReady      = "ready"
+	VideoStatusFailed     = "failed"
+)
+
+type Video struct {
+	MovieId   int
+	Status    string
+	Error     string
+	UpdatedAt time.Time
+	// Version is the latest upload, PublishedVersion the one being streamed.
+	Version          int `json:"-"`
+	PublishedVersion int `json:"-"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// Viewer describes who is asking for catalog data: the account, the active
+// profile and the preferences derived from them.
+type Viewer struct {
+	UserId       int
+	ProfileId    int
+	Language     string
+	MaxAgeRating int
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	// DeliveryStatusDead deliveries ran out of attempts. They stay in the
+	// dead-letter view until redelivered.
+	DeliveryStatusDead = "dead"
+)
+
+type Webhook struct {
+	Id  int
+	Url string
+	// Secret signs the payloads; subscribers use it to verify the
+	// X-Ozinshe-Signature header.
+	Secret      string
+	Events      []string
+	Description string
+	IsActive    bool
+	CreatedAt   time.Time
+}
+
+// Subscribes reports whether the webhook wants events of the type.
+func (w Webhook) Subscribes(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDelivery struct {
+	Id             int64
+	WebhookId      int
+	EventId        string
+	EventType      string
+	Payload        json.RawMessage `swaggertype:"object"`
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode *int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+	// Url and Secret of the webhook, filled in for deliveries being sent.
+	Url    string `json:"-"`
+	Secret string `json:"-"`
+}
@@ -0,0 +1,108 @@
+package notifier
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"goozinshe/models"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	VerificationTtl  = 72 * time.Hour
+	PasswordResetTtl = time.Hour
+)
+
+var ErrInvalidLinkToken = errors.New("invalid or expired link")
+
+// VerificationUrl returns the link of the verification email. The token is
+// bound to the address, so it stops working when the user changes it.
+func VerificationUrl(secret string, user models.User) string {
+	token := linkToken(secret, models.EmailVerification, user.Id, time.Now().Add(VerificationTtl), user.Email)
+	return publicUrl("auth/verify-email?token=" + url.QueryEscape(token))
+}
+
+// PasswordResetUrl returns the link of the password reset email. The token
+// is bound to the current password hash, so it can be used only once.
+func PasswordResetUrl(secret string, user models.User) string {
+	token := linkToken(secret, models.EmailPasswordReset, user.Id, time.Now().Add(PasswordResetTtl), user.PasswordHash)
+	return publicUrl("auth/password-reset?token=" + url.QueryEscape(token))
+}
+
+// LinkTokenUserId returns the user a verification or password reset token
+// was issued for, without checking it. Load the user and pass it to
+// VerifyLinkToken before trusting the token.
+func LinkTokenUserId(token string) (int, error) {
+	userId, _, _, err := parseLinkToken(token)
+	return userId, err
+}
+
+// VerifyLinkToken checks that the token of the kind was issued for the user
+// in its current state and has not expired.
+func VerifyLinkToken(secret string, kind string, token string, user models.User) error {
+	userId, expiresAt, signature, err := parseLinkToken(token)
+	if err != nil {
+		return err
+	}
+	if userId != user.Id || time.Now().Unix() > expiresAt {
+		return ErrInvalidLinkToken
+	}
+
+	var bound string
+	switch kind {
+	case models.EmailVerification:
+		bound = user.Email
+	case models.EmailPasswordReset:
+		bound = user.PasswordHash
+	default:
+		return ErrInvalidLinkToken
+	}
+	expected := linkSignature(secret, kind, userId, expiresAt, bound)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidLinkToken
+	}
+	return nil
+}
+
+// linkToken carries the user id and the expiry time; the value it is bound
+// to is only part of the signature, so the token does not reveal it.
+func linkToken(secret string, kind string, userId int, expiresAt time.Time, bound string) string {
+	payload := strconv.Itoa(userId) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		linkSignature(secret, kind, userId, expiresAt.Unix(), bound)
+}
+
+func parseLinkToken(token string) (userId int, expiresAt int64, signature string, err error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, 0, "", ErrInvalidLinkToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, 0, "", ErrInvalidLinkToken
+	}
+	userIdStr, expiresAtStr, ok := strings.Cut(string(payload), ".")
+	if !ok {
+		return 0, 0, "", ErrInvalidLinkToken
+	}
+	userId, err = strconv.Atoi(userIdStr)
+	if err != nil {
+		return 0, 0, "", ErrInvalidLinkToken
+	}
+	expiresAt, err = strconv.ParseInt(expiresAtStr, 10, 64)
+	if err != nil {
+		return 0, 0, "", ErrInvalidLinkToken
+	}
+	return userId, expiresAt, signature, nil
+}
+
+func linkSignature(secret string, kind string, userId int, expiresAt int64, bound string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s:%d.%d:%s", kind, userId, expiresAt, bound)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
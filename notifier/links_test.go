@@ -0,0 +1,71 @@
+package notifier
+
+import (
+	"goozinshe/config"
+	"goozinshe/models"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func tokenOf(t *testing.T, link string) string {
+	t.Helper()
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Query().Get("token")
+}
+
+func TestVerifyLinkToken(t *testing.T) {
+	config.Config = &config.MapConfig{PublicUrl: "http://localhost:8081"}
+	user := models.User{Id: 7, Email: "a@example.com", PasswordHash: "hash"}
+	verification := tokenOf(t, VerificationUrl("secret", user))
+	reset := tokenOf(t, PasswordResetUrl("secret", user))
+
+	if userId, err := LinkTokenUserId(reset); err != nil || userId != user.Id {
+		t.Fatalf("LinkTokenUserId = %d, %v", userId, err)
+	}
+	if err := VerifyLinkToken("secret", models.EmailVerification, verification, user); err != nil {
+		t.Errorf("verification token rejected: %v", err)
+	}
+	if err := VerifyLinkToken("secret", models.EmailPasswordReset, reset, user); err != nil {
+		t.Errorf("password reset token rejected: %v", err)
+	}
+
+	changedEmail := user
+	changedEmail.Email = "b@example.com"
+	changedPassword := user
+	changedPassword.PasswordHash = "other"
+	otherUser := user
+	otherUser.Id = 8
+	invalid := []struct {
+		name   string
+		secret string
+		kind   string
+		token  string
+		user   models.User
+	}{
+		{"other secret", "other", models.EmailVerification, verification, user},
+		{"other kind", "secret", models.EmailPasswordReset, verification, user},
+		{"changed email", "secret", models.EmailVerification, verification, changedEmail},
+		{"used reset token", "secret", models.EmailPasswordReset, reset, changedPassword},
+		{"other user", "secret", models.EmailVerification, verification, otherUser},
+		{"tampered", "secret", models.EmailVerification, strings.Replace(verification, ".", "x.", 1), user},
+		{"garbage", "secret", models.EmailVerification, "garbage", user},
+	}
+	for _, tt := range invalid {
+		if err := VerifyLinkToken(tt.secret, tt.kind, tt.token, tt.user); err != ErrInvalidLinkToken {
+			t.Errorf("%s: err = %v, want ErrInvalidLinkToken", tt.name, err)
+		}
+	}
+}
+
+func TestVerifyLinkTokenExpired(t *testing.T) {
+	user := models.User{Id: 7, Email: "a@example.com"}
+	token := linkToken("secret", models.EmailVerification, user.Id, time.Now().Add(-time.Second), user.Email)
+	if err := VerifyLinkToken("secret", models.EmailVerification, token, user); err != ErrInvalidLinkToken {
+		t.Errorf("err = %v, want ErrInvalidLinkToken", err)
+	}
+}
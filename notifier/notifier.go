@@ -0,0 +1,334 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"goozinshe/config"
+	"goozinshe/events"
+	"goozinshe/jobs"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EmailQueue = "email"
+
+	TransportSmtp = "smtp"
+	TransportFile = "file"
+
+	defaultFrom            = "Ozinshe <no-reply@localhost>"
+	defaultDropDir         = "mail"
+	defaultSmtpPort        = 587
+	defaultDigestSchedule  = "0 9 * * 1"
+	defaultNewReleaseDelay = time.Hour
+	digestPeriod           = 7 * 24 * time.Hour
+	digestMoviesLimit      = 10
+)
+
+// SendPayload describes one email to one user. The content is rendered when
+// the email is sent, in the language the user prefers at that moment.
+type SendPayload struct {
+	UserId int
+	Kind   string
+	// Url is the link of verification and password reset emails.
+	Url string `json:",omitempty"`
+	// MovieId is the movie of a new release email.
+	MovieId int `json:",omitempty"`
+	// Since is the start of the period of a digest email.
+	Since time.Time `json:",omitempty"`
+}
+
+type NewReleasePayload struct {
+	MovieId int
+}
+
+var (
+	// SendJob renders and sends one email.
+	SendJob = jobs.Type[SendPayload]{Name: "email.send", Queue: EmailQueue, MaxAttempts: 8}
+	// NewReleaseJob emails a new movie to the users who like its genres.
+	NewReleaseJob = jobs.Type[NewReleasePayload]{Name: "email.new_release", Queue: EmailQueue}
+	// WeeklyDigestJob emails the movies added during the past week.
+	WeeklyDigestJob = jobs.Type[jobs.NoPayload]{Name: "email.weekly_digest", Queue: EmailQueue}
+)
+
+// Notifier sends emails to users. Emails go out through the job runner, so
+// a failing mail server only delays them.
+type Notifier struct {
+	emailRepo  *repositories.EmailRepository
+	usersRepo  *repositories.UsersRepository
+	moviesRepo *repositories.MoviesRepository
+	transport  Transport
+	templates  templates
+	runner     *jobs.Runner
+}
+
+func NewNotifier(
+	emailRepo *repositories.EmailRepository,
+	usersRepo *repositories.UsersRepository,
+	moviesRepo *repositories.MoviesRepository,
+	transport Transport,
+	runner *jobs.Runner) (*Notifier, error) {
+	if config.Config.EmailUnsubscribeSecret == "" {
+		return nil, errors.New("EMAIL_UNSUBSCRIBE_SECRET is not set")
+	}
+	if config.Config.EmailLinkSecret == "" {
+		return nil, errors.New("EMAIL_LINK_SECRET is not set")
+	}
+	templates, err := parseTemplates()
+	if err != nil {
+		return nil, err
+	}
+	return &Notifier{
+		emailRepo:  emailRepo,
+		usersRepo:  usersRepo,
+		moviesRepo: moviesRepo,
+		transport:  transport,
+		templates:  templates,
+		runner:     runner,
+	}, nil
+}
+
+// Register makes the runner send emails and the weekly digest, on
+// EMAIL_DIGEST_SCHEDULE.
+func (n *Notifier) Register(runner *jobs.Runner) {
+	digestSchedule, err := jobs.ParseCron(config.Config.EmailDigestSchedule)
+	if err != nil {
+		digestSchedule = jobs.MustParseCron(defaultDigestSchedule)
+	}
+	jobs.Handle(runner, SendJob, n.Send)
+	jobs.Handle(runner, NewReleaseJob, n.fanOutNewRelease)
+	jobs.Handle(runner, WeeklyDigestJob, n.fanOutWeeklyDigest)
+	jobs.Recur(runner, WeeklyDigestJob, digestSchedule, jobs.NoPayload{})
+}
+
+// NewTransport returns the transport selected by EMAIL_TRANSPORT: "smtp" or,
+// by default, "file", which drops the emails into EMAIL_DROP_DIR.
+func NewTransport() Transport {
+	if config.Config.EmailTransport == TransportSmtp {
+		port := config.Config.SmtpPort
+		if port <= 0 {
+			port = defaultSmtpPort
+		}
+		return NewSmtpTransport(config.Config.SmtpHost, port, config.Config.SmtpUsername, config.Config.SmtpPassword)
+	}
+	dir := config.Config.EmailDropDir
+	if dir == "" {
+		dir = defaultDropDir
+	}
+	return NewFileTransport(dir)
+}
+
+// Enqueue schedules the email for sending.
+func (n *Notifier) Enqueue(c context.Context, payload SendPayload, options ...jobs.EnqueueOption) error {
+	_, err := jobs.Enqueue(c, n.runner, SendJob, payload, options...)
+	return err
+}
+
+// EnqueueVerification schedules the email that asks the user to confirm the
+// address.
+func (n *Notifier) EnqueueVerification(c context.Context, user models.User) error {
+	return n.Enqueue(c, SendPayload{
+		UserId: user.Id,
+		Kind:   models.EmailVerification,
+		Url:    VerificationUrl(config.Config.EmailLinkSecret, user),
+	})
+}
+
+// EnqueuePasswordReset schedules the email with the link to choose a new
+// password.
+func (n *Notifier) EnqueuePasswordReset(c context.Context, user models.User) error {
+	return n.Enqueue(c, SendPayload{
+		UserId: user.Id,
+		Kind:   models.EmailPasswordReset,
+		Url:    PasswordResetUrl(config.Config.EmailLinkSecret, user),
+	})
+}
+
+// Send renders the email in the user's language and sends it. Emails the
+// user has turned off, and emails about movies that are gone, are skipped.
+func (n *Notifier) Send(ctx context.Context, payload SendPayload) error {
+	logger := logger.GetLogger()
+
+	user, err := n.usersRepo.FindById(ctx, payload.UserId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	preferences, err := n.emailRepo.GetPreferences(ctx, user.Id)
+	if err != nil {
+		return err
+	}
+	if !preferences.Allows(payload.Kind) {
+		return nil
+	}
+
+	data := templateData{Name: user.Name, Url: payload.Url}
+	var headers map[string]string
+	switch payload.Kind {
+	case models.EmailNewRelease, models.EmailWeeklyDigest:
+		maxAgeRating, err := n.emailRepo.FindMaxAgeRating(ctx, user.Id)
+		if err != nil {
+			return err
+		}
+		viewer := models.Viewer{UserId: user.Id, Language: preferences.Language, MaxAgeRating: maxAgeRating}
+		ok, err := n.loadMovies(ctx, payload, viewer, &data)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			logger.Info("Email skipped, nothing to tell", zap.Int("user_id", user.Id), zap.String("kind", payload.Kind))
+			return nil
+		}
+		data.UnsubscribeUrl = publicUrl("unsubscribe?token=" +
+			url.QueryEscape(UnsubscribeToken(config.Config.EmailUnsubscribeSecret, user.Id, payload.Kind)))
+		headers = map[string]string{
+			"List-Unsubscribe":      "<" + data.UnsubscribeUrl + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
+	}
+
+	subject, text, html, err := n.templates.render(preferences.Language, payload.Kind, data)
+	if err != nil {
+		return err
+	}
+	message := Message{
+		From:    sender(),
+		To:      (&mail.Address{Name: user.Name, Address: user.Email}).String(),
+		Subject: subject,
+		Text:    text,
+		Html:    html,
+		Headers: headers,
+	}
+
+	err = n.transport.Send(ctx, message)
+	if err != nil {
+		return err
+	}
+	logger.Info("Email has been sent", zap.Int("user_id", user.Id), zap.String("kind", payload.Kind))
+	return nil
+}
+
+// loadMovies fills in the movies the email is about. It reports false when
+// there are none the user may see.
+func (n *Notifier) loadMovies(ctx context.Context, payload SendPayload, viewer models.Viewer, data *templateData) (bool, error) {
+	if payload.Kind == models.EmailNewRelease {
+		movie, err := n.moviesRepo.FindById(ctx, payload.MovieId, viewer)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		movie.PosterUrl = posterUrl(movie.PosterUrl)
+		data.Movie = &movie
+		return true, nil
+	}
+
+	ids, err := n.emailRepo.FindNewMovieIds(ctx, payload.Since, viewer.MaxAgeRating, digestMoviesLimit)
+	if err != nil {
+		return false, err
+	}
+	if len(ids) == 0 {
+		return false, nil
+	}
+	movies, err := n.moviesRepo.FindAllByIds(ctx, ids, viewer)
+	if err != nil {
+		return false, err
+	}
+	for i := range movies {
+		movies[i].PosterUrl = posterUrl(movies[i].PosterUrl)
+	}
+	data.Movies = movies
+	return len(movies) > 0, nil
+}
+
+func (n *Notifier) fanOutNewRelease(ctx context.Context, payload NewReleasePayload) error {
+	userIds, err := n.emailRepo.FindNewReleaseRecipients(ctx, payload.MovieId)
+	if err != nil {
+		return err
+	}
+	for _, userId := range userIds {
+		key := fmt.Sprintf("%s:%d:%d", models.EmailNewRelease, payload.MovieId, userId)
+		err := n.Enqueue(ctx, SendPayload{UserId: userId, Kind: models.EmailNewRelease, MovieId: payload.MovieId}, jobs.UniqueKey(key))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) fanOutWeeklyDigest(ctx context.Context, _ jobs.NoPayload) error {
+	now := time.Now()
+	userIds, err := n.emailRepo.FindDigestRecipients(ctx)
+	if err != nil {
+		return err
+	}
+	year, week := now.ISOWeek()
+	for _, userId := range userIds {
+		key := fmt.Sprintf("%s:%d-%02d:%d", models.EmailWeeklyDigest, year, week, userId)
+		payload := SendPayload{UserId: userId, Kind: models.EmailWeeklyDigest, Since: now.Add(-digestPeriod)}
+		err := n.Enqueue(ctx, payload, jobs.UniqueKey(key))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Name and Publish make the notifier an outbox sink: a created movie queues
+// new release emails, after EMAIL_NEW_RELEASE_DELAY so that editors have
+// time to add translations and a poster.
+func (n *Notifier) Name() string {
+	return "email"
+}
+
+func (n *Notifier) Publish(ctx context.Context, event models.OutboxEvent) error {
+	if event.Type != events.MovieCreated {
+		return nil
+	}
+	var data struct {
+		Id int `json:"id"`
+	}
+	if err := json.Unmarshal(event.Payload, &data); err != nil {
+		return err
+	}
+
+	delay := config.Config.EmailNewReleaseDelay
+	if delay <= 0 {
+		delay = defaultNewReleaseDelay
+	}
+	_, err := jobs.Enqueue(ctx, n.runner, NewReleaseJob, NewReleasePayload{MovieId: data.Id},
+		jobs.RunAt(time.Now().Add(delay)),
+		jobs.UniqueKey(NewReleaseJob.Name+":"+strconv.Itoa(data.Id)))
+	return err
+}
+
+func sender() string {
+	if config.Config.EmailFrom == "" {
+		return defaultFrom
+	}
+	return config.Config.EmailFrom
+}
+
+func publicUrl(path string) string {
+	return strings.TrimRight(config.Config.PublicUrl, "/") + "/" + path
+}
+
+func posterUrl(poster string) string {
+	if poster == "" || strings.HasPrefix(poster, "http://") || strings.HasPrefix(poster, "https://") {
+		return poster
+	}
+	return publicUrl("images/" + poster)
+}
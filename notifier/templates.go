@@ -0,0 +1,91 @@
+package notifier
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"goozinshe/models"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// templateData is what the email templates can refer to.
+type templateData struct {
+	Name    string
+	Subject string
+	// Url is the link the email asks to open, e.g. to confirm the address.
+	Url            string
+	Movie          *models.Movie
+	Movies         []models.Movie
+	UnsubscribeUrl string
+}
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// templates holds every email kind in every supported language, keyed by
+// "<language>/<kind>".
+type templates map[string]emailTemplate
+
+var emailKinds = []string{
+	models.EmailVerification,
+	models.EmailPasswordReset,
+	models.EmailNewRelease,
+	models.EmailWeeklyDigest,
+}
+
+func parseTemplates() (templates, error) {
+	parsed := make(templates)
+	for _, language := range models.SupportedLanguages {
+		for _, kind := range emailKinds {
+			dir := "templates/" + language + "/"
+			text, err := texttemplate.ParseFS(templateFiles, dir+"layout.txt", dir+kind+".txt")
+			if err != nil {
+				return nil, err
+			}
+			html, err := htmltemplate.ParseFS(templateFiles, dir+"layout.html", dir+kind+".html")
+			if err != nil {
+				return nil, err
+			}
+			parsed[language+"/"+kind] = emailTemplate{text: text, html: html}
+		}
+	}
+	return parsed, nil
+}
+
+// render returns the subject, plain text and HTML bodies of the email,
+// falling back to the other languages when the preferred one has no
+// template for the kind.
+func (t templates) render(language string, kind string, data templateData) (subject string, text string, html string, err error) {
+	for _, lang := range models.LanguageFallbacks(language) {
+		tmpl, ok := t[lang+"/"+kind]
+		if !ok {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.text.ExecuteTemplate(&buf, "subject", data); err != nil {
+			return "", "", "", err
+		}
+		data.Subject = strings.TrimSpace(buf.String())
+
+		buf.Reset()
+		if err := tmpl.text.ExecuteTemplate(&buf, "text", data); err != nil {
+			return "", "", "", err
+		}
+		text = buf.String()
+
+		buf.Reset()
+		if err := tmpl.html.ExecuteTemplate(&buf, "html", data); err != nil {
+			return "", "", "", err
+		}
+		return data.Subject, text, buf.String(), nil
+	}
+	return "", "", "", fmt.Errorf("no email template for %q", kind)
+}
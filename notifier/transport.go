@@ -0,0 +1,162 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	Html    string
+	Headers map[string]string
+}
+
+// Bytes renders the message as a multipart/alternative MIME message.
+func (m Message) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+
+	headers := map[string]string{
+		"From":         m.From,
+		"To":           m.To,
+		"Subject":      mime.QEncoding.Encode("utf-8", m.Subject),
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"Message-ID":   fmt.Sprintf("<%s@%s>", uuid.NewString(), domainOf(m.From)),
+		"MIME-Version": "1.0",
+		"Content-Type": fmt.Sprintf("multipart/alternative; boundary=%q", body.Boundary()),
+	}
+	for name, value := range m.Headers {
+		headers[name] = value
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var message bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&message, "%s: %s\r\n", name, headers[name])
+	}
+	message.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.Html},
+	} {
+		w, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	message.Write(buf.Bytes())
+	return message.Bytes(), nil
+}
+
+func domainOf(address string) string {
+	address = strings.TrimRight(address, "> ")
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
+
+// Transport hands rendered messages over for delivery.
+type Transport interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// SmtpTransport sends messages through an SMTP server, upgrading the
+// connection with STARTTLS when the server offers it.
+type SmtpTransport struct {
+	host     string
+	port     int
+	username string
+	password string
+}
+
+func NewSmtpTransport(host string, port int, username string, password string) *SmtpTransport {
+	return &SmtpTransport{host: host, port: port, username: username, password: password}
+}
+
+func (t *SmtpTransport) Send(ctx context.Context, message Message) error {
+	data, err := message.Bytes()
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if t.username != "" {
+		auth = smtp.PlainAuth("", t.username, t.password, t.host)
+	}
+	from, err := envelopeAddress(message.From)
+	if err != nil {
+		return err
+	}
+	to, err := envelopeAddress(message.To)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(net.JoinHostPort(t.host, strconv.Itoa(t.port)), auth, from, []string{to}, data)
+}
+
+func envelopeAddress(address string) (string, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	return parsed.Address, nil
+}
+
+// FileTransport writes every message into a directory as an .eml file
+// instead of sending it. It is meant for local development and tests.
+type FileTransport struct {
+	dir string
+}
+
+func NewFileTransport(dir string) *FileTransport {
+	return &FileTransport{dir: dir}
+}
+
+func (t *FileTransport) Send(ctx context.Context, message Message) error {
+	data, err := message.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.NewString())
+	return os.WriteFile(filepath.Join(t.dir, name), data, 0644)
+}
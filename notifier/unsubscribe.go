@@ -0,0 +1,54 @@
+package notifier
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"goozinshe/models"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+// UnsubscribeToken returns a token that lets the user turn off emails of the
+// kind without signing in. It is signed with the secret and does not
+// expire, so links in old emails keep working.
+func UnsubscribeToken(secret string, userId int, kind string) string {
+	payload := strconv.Itoa(userId) + "." + kind
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + unsubscribeSignature(secret, payload)
+}
+
+// ParseUnsubscribeToken verifies the token and returns the user and the
+// email kind it was issued for.
+func ParseUnsubscribeToken(secret string, token string) (userId int, kind string, err error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	if !hmac.Equal([]byte(signature), []byte(unsubscribeSignature(secret, string(payload)))) {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+
+	userIdStr, kind, ok := strings.Cut(string(payload), ".")
+	if !ok || !models.IsOptionalEmail(kind) {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	userId, err = strconv.Atoi(userIdStr)
+	if err != nil {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	return userId, kind, nil
+}
+
+func unsubscribeSignature(secret string, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "unsubscribe:%s", payload)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
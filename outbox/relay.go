@@ -0,0 +1,101 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"goozinshe/config"
+	"goozinshe/jobs"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"goozinshe/schedule"
+	"time"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultRetention    = 7 * 24 * time.Hour
+	defaultMaxAttempts  = 10
+	batchSize           = 100
+)
+
+// Sink receives the published events. An event is handed to a sink at least
+// once: it is repeated when the relay stops before marking it published or
+// when another sink fails on it, so sinks should be idempotent or tolerate
+// duplicates (Event.EventId stays the same).
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event models.OutboxEvent) error
+}
+
+// Relay publishes the events saved in the outbox to the sinks, in the order
+// they were saved.
+type Relay struct {
+	repo  *repositories.OutboxRepository
+	sinks []Sink
+}
+
+func NewRelay(repo *repositories.OutboxRepository, sinks ...Sink) *Relay {
+	return &Relay{repo: repo, sinks: sinks}
+}
+
+// PublishPending publishes every pending event. An event that a sink fails
+// on is retried on the next run, together with the events after it, until
+// it has failed OUTBOX_MAX_ATTEMPTS times; then it is moved to the dead
+// letters, where an admin can look at it and retry it.
+func (r *Relay) PublishPending(ctx context.Context) error {
+	maxAttempts := config.Config.OutboxMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	for {
+		published, err := r.repo.ProcessPending(ctx, batchSize, maxAttempts, func(event models.OutboxEvent) error {
+			return r.publish(ctx, event)
+		})
+		if err != nil {
+			return err
+		}
+		if published < batchSize {
+			return nil
+		}
+	}
+}
+
+// CleanupJob deletes events published more than OUTBOX_RETENTION ago.
+var CleanupJob = jobs.Type[jobs.NoPayload]{Name: "outbox.cleanup", Queue: jobs.MaintenanceQueue}
+
+// Register makes the runner clean up published events.
+func (r *Relay) Register(runner *jobs.Runner) {
+	jobs.Handle(runner, CleanupJob, func(ctx context.Context, _ jobs.NoPayload) error {
+		retention := config.Config.OutboxRetention
+		if retention <= 0 {
+			retention = defaultRetention
+		}
+		return r.repo.DeletePublished(ctx, time.Now().Add(-retention))
+	})
+	jobs.Recur(runner, CleanupJob, runner.CleanupSchedule, jobs.NoPayload{})
+}
+
+func (r *Relay) publish(ctx context.Context, event models.OutboxEvent) error {
+	for _, sink := range r.sinks {
+		err := sink.Publish(ctx, event)
+		if err != nil {
+			logger := logger.GetLogger()
+			logger.Warn("Outbox event has not been published",
+				zap.String("sink", sink.Name()), zap.Int64("event_id", event.Id), zap.String("type", event.Type), zap.Error(err))
+			return fmt.Errorf("%s: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Run publishes pending events every OUTBOX_POLL_INTERVAL until ctx is
+// cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	interval := config.Config.OutboxPollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	schedule.Every(ctx, "outbox", interval, r.PublishPending)
+}
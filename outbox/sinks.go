@@ -0,0 +1,77 @@
+package outbox
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/webhooks"
+)
+
+// LogSink writes every event to the application log.
+type LogSink struct{}
+
+func NewLogSink() *LogSink {
+	return &LogSink{}
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	logger := logger.GetLogger()
+	logger.Info("Domain event",
+		zap.String("event_id", event.EventId), zap.String("type", event.Type), zap.ByteString("payload", event.Payload))
+	return nil
+}
+
+// WebhookSink queues the events webhooks can subscribe to for delivery. The
+// outbox event id becomes the delivery id, so a repeated event is not
+// delivered twice.
+type WebhookSink struct {
+	dispatcher *webhooks.Dispatcher
+}
+
+func NewWebhookSink(dispatcher *webhooks.Dispatcher) *WebhookSink {
+	return &WebhookSink{dispatcher: dispatcher}
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhooks"
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	return s.dispatcher.Enqueue(ctx, webhooks.Envelope{
+		Id:         event.EventId,
+		Type:       event.Type,
+		OccurredAt: event.CreatedAt.UTC(),
+		Data:       event.Payload,
+	})
+}
+
+// ChannelSink passes the events to a channel, for tests and in-process
+// consumers. Publish waits for room in the channel, so the reader has to
+// keep up or the relay stalls.
+type ChannelSink struct {
+	Events <-chan models.OutboxEvent
+	events chan models.OutboxEvent
+}
+
+func NewChannelSink(buffer int) *ChannelSink {
+	events := make(chan models.OutboxEvent, buffer)
+	return &ChannelSink{Events: events, events: events}
+}
+
+func (s *ChannelSink) Name() string {
+	return "channel"
+}
+
+func (s *ChannelSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	select {
+	case s.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
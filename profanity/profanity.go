@@ -0,0 +1,33 @@
+package profanity
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Words splits text into lower-cased words, treating everything except
+// letters and digits as a separator.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Find returns the blocklisted words that occur in text, each once and in
+// the order they first appear. Blocklist entries are expected to be single
+// lower-cased words as produced by Words.
+func Find(text string, blocklist []string) []string {
+	blocked := make(map[string]bool, len(blocklist))
+	for _, word := range blocklist {
+		blocked[word] = true
+	}
+
+	found := make([]string, 0)
+	for _, word := range Words(text) {
+		if blocked[word] {
+			found = append(found, word)
+			delete(blocked, word)
+		}
+	}
+	return found
+}
@@ -0,0 +1,50 @@
+package profanity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"Hello, World!", []string{"hello", "world"}},
+		{"  spaced\tout\nwords ", []string{"spaced", "out", "words"}},
+		{"Сәлем, ДОСЫМ! Привет-мир", []string{"сәлем", "досым", "привет", "мир"}},
+		{"top10 films", []string{"top10", "films"}},
+	}
+	for _, tt := range tests {
+		got := Words(tt.text)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Words(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	blocklist := []string{"badword", "жаман", "плохо"}
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"A perfectly fine comment", []string{}},
+		{"What a BADWORD!", []string{"badword"}},
+		{"badword, badword and Жаман", []string{"badword", "жаман"}},
+		{"плохо-плохо", []string{"плохо"}},
+		// Only whole words match.
+		{"badwords are not badword-ish", []string{"badword"}},
+		{"notbadword", []string{}},
+	}
+	for _, tt := range tests {
+		got := Find(tt.text, blocklist)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Find(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package recommendations
+
+import (
+	"context"
+	"goozinshe/jobs"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// maxNeighbours is how many most similar movies are stored per movie.
+	maxNeighbours = 20
+	// minCoRaters is how many profiles must have rated both movies before
+	// their similarity is trusted.
+	minCoRaters = 2
+	// shrinkage damps similarities backed by few co-raters.
+	shrinkage = 5.0
+	// likedRating is the lowest predicted rating worth recommending.
+	likedRating = 3.0
+	// defaultRecomputeInterval is used when no positive interval is configured.
+	defaultRecomputeInterval = time.Hour
+)
+
+// Recommender suggests movies with item-item collaborative filtering over
+// profile ratings. Similarities are precomputed by Recompute, which runs as a
+// recurring job; profiles without useful ratings get popular movies from
+// their favourite genres instead.
+type Recommender struct {
+	moviesRepo *repositories.MoviesRepository
+	repo       *repositories.RecommendationsRepository
+}
+
+func NewRecommender(
+	moviesRepo *repositories.MoviesRepository,
+	repo *repositories.RecommendationsRepository) *Recommender {
+	return &Recommender{
+		moviesRepo: moviesRepo,
+		repo:       repo,
+	}
+}
+
+// RecomputeJob runs Recompute in the background.
+var RecomputeJob = jobs.Type[jobs.NoPayload]{Name: "recommendations.recompute", Queue: jobs.MaintenanceQueue}
+
+// Register makes the runner recompute similarities every interval.
+func (r *Recommender) Register(runner *jobs.Runner, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultRecomputeInterval
+	}
+	jobs.Handle(runner, RecomputeJob, func(ctx context.Context, _ jobs.NoPayload) error {
+		return r.Recompute(ctx)
+	})
+	jobs.Recur(runner, RecomputeJob, jobs.Every(interval), jobs.NoPayload{})
+}
+
+// Recompute rebuilds the movie neighbour table from the current ratings.
+func (r *Recommender) Recompute(c context.Context) error {
+	ratings, err := r.repo.FindAllRatings(c)
+	if err != nil {
+		return err
+	}
+
+	return r.repo.ReplaceNeighbours(c, computeNeighbours(ratings))
+}
+
+type pairStats struct {
+	dot, squaresA, squaresB float64
+	coRaters                int
+}
+
+// computeNeighbours scores every pair of movies with the adjusted cosine
+// similarity: ratings are centred on each profile's mean so that generous
+// and strict raters are comparable.
+func computeNeighbours(ratings []models.ProfileRating) []models.MovieNeighbour {
+	byProfile := make(map[int][]models.ProfileRating)
+	for _, rating := range ratings {
+		byProfile[rating.ProfileId] = append(byProfile[rating.ProfileId], rating)
+	}
+
+	pairs := make(map[[2]int]*pairStats)
+	for _, profileRatings := range byProfile {
+		if len(profileRatings) < 2 {
+			continue
+		}
+
+		mean := 0.0
+		for _, rating := range profileRatings {
+			mean += float64(rating.Rating)
+		}
+		mean /= float64(len(profileRatings))
+
+		for i, a := range profileRatings {
+			deviationA := float64(a.Rating) - mean
+			for _, b := range profileRatings[i+1:] {
+				deviationB := float64(b.Rating) - mean
+				key, da, db := [2]int{a.MovieId, b.MovieId}, deviationA, deviationB
+				if a.MovieId > b.MovieId {
+					key, da, db = [2]int{b.MovieId, a.MovieId}, deviationB, deviationA
+				}
+
+				stats, ok := pairs[key]
+				if !ok {
+					stats = &pairStats{}
+					pairs[key] = stats
+				}
+				stats.dot += da * db
+				stats.squaresA += da * da
+				stats.squaresB += db * db
+				stats.coRaters++
+			}
+		}
+	}
+
+	byMovie := make(map[int][]models.MovieNeighbour)
+	for key, stats := range pairs {
+		if stats.coRaters < minCoRaters || stats.squaresA == 0 || stats.squaresB == 0 {
+			continue
+		}
+		similarity := stats.dot / math.Sqrt(stats.squaresA*stats.squaresB)
+		similarity *= float64(stats.coRaters) / (float64(stats.coRaters) + shrinkage)
+		if similarity <= 0 {
+			continue
+		}
+
+		byMovie[key[0]] = append(byMovie[key[0]], models.MovieNeighbour{MovieId: key[0], NeighbourId: key[1], Similarity: similarity})
+		byMovie[key[1]] = append(byMovie[key[1]], models.MovieNeighbour{MovieId: key[1], NeighbourId: key[0], Similarity: similarity})
+	}
+
+	neighbours := make([]models.MovieNeighbour, 0)
+	for _, movieNeighbours := range byMovie {
+		sort.Slice(movieNeighbours, func(i, j int) bool {
+			if movieNeighbours[i].Similarity != movieNeighbours[j].Similarity {
+				return movieNeighbours[i].Similarity > movieNeighbours[j].Similarity
+			}
+			return movieNeighbours[i].NeighbourId < movieNeighbours[j].NeighbourId
+		})
+		if len(movieNeighbours) > maxNeighbours {
+			movieNeighbours = movieNeighbours[:maxNeighbours]
+		}
+		neighbours = append(neighbours, movieNeighbours...)
+	}
+	return neighbours
+}
+
+type prediction struct {
+	movieId          int
+	score            float64
+	becauseOfMovieId int
+}
+
+// ForViewer returns up to limit recommendations for the viewer's profile,
+// topping collaborative suggestions up with popular movies when there are
+// not enough of them.
+func (r *Recommender) ForViewer(c context.Context, viewer models.Viewer, limit int) ([]models.Recommendation, error) {
+	predictions, err := r.predict(c, viewer.ProfileId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(predictions)*2)
+	for _, p := range predictions {
+		ids = append(ids, p.movieId, p.becauseOfMovieId)
+	}
+	movies, err := r.moviesRepo.FindAllByIds(c, ids, viewer)
+	if err != nil {
+		return nil, err
+	}
+	byId := make(map[int]models.Movie, len(movies))
+	for _, movie := range movies {
+		byId[movie.Id] = movie
+	}
+
+	recommendations := make([]models.Recommendation, 0, limit)
+	included := make(map[int]bool)
+	for _, p := range predictions {
+		if len(recommendations) == limit {
+			break
+		}
+		movie, ok := byId[p.movieId]
+		if !ok || movie.IsWatched {
+			continue
+		}
+		recommendation := models.Recommendation{
+			Movie:            movie,
+			Score:            p.score,
+			Reason:           models.ReasonRated,
+			Explanation:      explain(viewer.Language, explanationRatedSimilar),
+			BecauseOfMovieId: p.becauseOfMovieId,
+		}
+		if source, ok := byId[p.becauseOfMovieId]; ok {
+			recommendation.Explanation = explain(viewer.Language, explanationRatedMovie, source.Title)
+		}
+		recommendations = append(recommendations, recommendation)
+		included[movie.Id] = true
+	}
+
+	if len(recommendations) < limit {
+		fallback, err := r.popular(c, viewer, limit-len(recommendations), included)
+		if err != nil {
+			return nil, err
+		}
+		recommendations = append(recommendations, fallback...)
+	}
+
+	return recommendations, nil
+}
+
+// predict estimates the profile's rating of every neighbour of the movies
+// it rated as the similarity-weighted average of its own ratings.
+func (r *Recommender) predict(c context.Context, profileId int) ([]prediction, error) {
+	ratings, err := r.repo.FindRatingsByProfileId(c, profileId)
+	if err != nil {
+		return nil, err
+	}
+	if len(ratings) == 0 {
+		return []prediction{}, nil
+	}
+
+	rated := make(map[int]int, len(ratings))
+	ratedIds := make([]int, 0, len(ratings))
+	for _, rating := range ratings {
+		rated[rating.MovieId] = rating.Rating
+		ratedIds = append(ratedIds, rating.MovieId)
+	}
+
+	neighbours, err := r.repo.FindNeighbours(c, ratedIds)
+	if err != nil {
+		return nil, err
+	}
+
+	type accumulator struct {
+		weighted, weights, bestContribution float64
+		becauseOfMovieId                    int
+	}
+	candidates := make(map[int]*accumulator)
+	for _, n := range neighbours {
+		if _, ok := rated[n.NeighbourId]; ok {
+			continue
+		}
+		acc, ok := candidates[n.NeighbourId]
+		if !ok {
+			acc = &accumulator{}
+			candidates[n.NeighbourId] = acc
+		}
+		contribution := n.Similarity * float64(rated[n.MovieId])
+		acc.weighted += contribution
+		acc.weights += n.Similarity
+		if contribution > acc.bestContribution {
+			acc.bestContribution = contribution
+			acc.becauseOfMovieId = n.MovieId
+		}
+	}
+
+	predictions := make([]prediction, 0, len(candidates))
+	for movieId, acc := range candidates {
+		score := acc.weighted / acc.weights
+		if score < likedRating {
+			continue
+		}
+		predictions = append(predictions, prediction{movieId: movieId, score: score, becauseOfMovieId: acc.becauseOfMovieId})
+	}
+	sort.Slice(predictions, func(i, j int) bool {
+		if predictions[i].score != predictions[j].score {
+			return predictions[i].score > predictions[j].score
+		}
+		return predictions[i].movieId < predictions[j].movieId
+	})
+	return predictions, nil
+}
+
+func (r *Recommender) popular(c context.Context, viewer models.Viewer, limit int, excluded map[int]bool) ([]models.Recommendation, error) {
+	popular, err := r.repo.FindPopularInFavouriteGenres(c, viewer, limit+len(excluded))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(popular))
+	for _, p := range popular {
+		ids = append(ids, p.MovieId)
+	}
+	movies, err := r.moviesRepo.FindAllByIds(c, ids, viewer)
+	if err != nil {
+		return nil, err
+	}
+	byId := make(map[int]models.Movie, len(movies))
+	for _, movie := range movies {
+		byId[movie.Id] = movie
+	}
+
+	recommendations := make([]models.Recommendation, 0, limit)
+	for _, p := range popular {
+		if len(recommendations) == limit {
+			break
+		}
+		movie, ok := byId[p.MovieId]
+		if !ok || excluded[p.MovieId] {
+			continue
+		}
+
+		recommendation := models.Recommendation{
+			Movie:       movie,
+			Reason:      models.ReasonPopular,
+			Explanation: explain(viewer.Language, explanationPopular),
+		}
+		if p.GenreId != 0 {
+			recommendation.Reason = models.ReasonPopularInGenre
+			recommendation.BecauseOfGenreId = p.GenreId
+			for _, genre := range movie.Genres {
+				if genre.Id == p.GenreId {
+					recommendation.Explanation = explain(viewer.Language, explanationPopularInGenre, genre.Title)
+				}
+			}
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	return recommendations, nil
+}
@@ -0,0 +1,48 @@
+package recommendations
+
+import (
+	"fmt"
+	"goozinshe/models"
+)
+
+const (
+	explanationRatedMovie     = "rated_movie"
+	explanationRatedSimilar   = "rated_similar"
+	explanationPopularInGenre = "popular_in_genre"
+	explanationPopular        = "popular"
+)
+
+// explanations holds the explanation formats per language. rated_movie and
+// popular_in_genre take the title of the movie or genre the recommendation
+// is based on.
+var explanations = map[string]map[string]string{
+	models.LanguageKazakh: {
+		explanationRatedMovie:     "Сіз «%s» фильміне баға бергендіктен",
+		explanationRatedSimilar:   "Сіз ұқсас фильмге баға бергендіктен",
+		explanationPopularInGenre: "«%s» жанрында танымал",
+		explanationPopular:        "Ozinshe-де танымал",
+	},
+	models.LanguageRussian: {
+		explanationRatedMovie:     "Потому что вы оценили «%s»",
+		explanationRatedSimilar:   "Потому что вы оценили похожий фильм",
+		explanationPopularInGenre: "Популярно в жанре «%s»",
+		explanationPopular:        "Популярно на Ozinshe",
+	},
+	models.LanguageEnglish: {
+		explanationRatedMovie:     "Because you rated %s",
+		explanationRatedSimilar:   "Because you rated a similar movie",
+		explanationPopularInGenre: "Popular in %s",
+		explanationPopular:        "Popular on Ozinshe",
+	},
+}
+
+// explain formats the explanation in the viewer's language, falling back to
+// the other supported languages.
+func explain(language string, explanation string, args ...any) string {
+	for _, lang := range models.LanguageFallbacks(language) {
+		if format, ok := explanations[lang][explanation]; ok {
+			return fmt.Sprintf(format, args...)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,196 @@
+package recommendations
+
+import (
+	"context"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxSimilarCandidates caps how many ranked neighbours are kept per movie.
+const maxSimilarCandidates = 100
+
+const (
+	genreWeight    = 3.0
+	tagWeight      = 2.0
+	directorWeight = 2.0
+	eraWeight      = 1.0
+	// eraSpanYears is the release year distance at which era proximity stops counting.
+	eraSpanYears = 10.0
+)
+
+// ScoredMovie is a neighbour of a movie together with its similarity score.
+type ScoredMovie struct {
+	MovieId int
+	Score   float64
+}
+
+// SimilarMovies ranks movies by shared genres, tags, director and release era.
+// The catalog snapshot and the rankings computed from it are cached until
+// Invalidate is called.
+type SimilarMovies struct {
+	moviesRepo *repositories.MoviesRepository
+
+	mu         sync.Mutex
+	generation int
+	catalog    map[int]models.Movie
+	ranked     map[int][]ScoredMovie
+}
+
+func NewSimilarMovies(moviesRepo *repositories.MoviesRepository) *SimilarMovies {
+	return &SimilarMovies{moviesRepo: moviesRepo}
+}
+
+// Invalidate drops everything cached. Call it whenever movies, their genres
+// or their tags change.
+func (s *SimilarMovies) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+	s.catalog = nil
+	s.ranked = nil
+}
+
+// Find returns the movies most similar to the given one, best first. The
+// result ignores viewer restrictions; callers filter it per viewer.
+func (s *SimilarMovies) Find(c context.Context, movieId int) ([]ScoredMovie, error) {
+	s.mu.Lock()
+	if ranked, ok := s.ranked[movieId]; ok {
+		s.mu.Unlock()
+		return ranked, nil
+	}
+	catalog := s.catalog
+	generation := s.generation
+	s.mu.Unlock()
+
+	if catalog == nil {
+		var err error
+		catalog, err = s.loadCatalog(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ranked := rankSimilar(catalog, movieId)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation == generation {
+		s.catalog = catalog
+		if s.ranked == nil {
+			s.ranked = make(map[int][]ScoredMovie)
+		}
+		s.ranked[movieId] = ranked
+	}
+	return ranked, nil
+}
+
+func (s *SimilarMovies) loadCatalog(c context.Context) (map[int]models.Movie, error) {
+	movies, err := s.moviesRepo.FindAll(c, models.MovieFilters{}, models.Viewer{MaxAgeRating: models.MaxAgeRating})
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := make(map[int]models.Movie, len(movies))
+	for _, movie := range movies {
+		catalog[movie.Id] = movie
+	}
+	return catalog, nil
+}
+
+func rankSimilar(catalog map[int]models.Movie, movieId int) []ScoredMovie {
+	movie, ok := catalog[movieId]
+	if !ok {
+		return []ScoredMovie{}
+	}
+
+	ranked := make([]ScoredMovie, 0)
+	for id, other := range catalog {
+		if id == movieId {
+			continue
+		}
+		score := Similarity(movie, other)
+		if score > 0 {
+			ranked = append(ranked, ScoredMovie{MovieId: id, Score: score})
+		}
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].MovieId < ranked[j].MovieId
+	})
+	if len(ranked) > maxSimilarCandidates {
+		ranked = ranked[:maxSimilarCandidates]
+	}
+	return ranked
+}
+
+// Similarity scores two movies: Jaccard overlap of genres and tags, an exact
+// director match and how close their release years are.
+func Similarity(a, b models.Movie) float64 {
+	genresA := make([]int, 0, len(a.Genres))
+	for _, g := range a.Genres {
+		genresA = append(genresA, g.Id)
+	}
+	genresB := make([]int, 0, len(b.Genres))
+	for _, g := range b.Genres {
+		genresB = append(genresB, g.Id)
+	}
+	tagsA := make([]int, 0, len(a.Tags))
+	for _, t := range a.Tags {
+		tagsA = append(tagsA, t.Id)
+	}
+	tagsB := make([]int, 0, len(b.Tags))
+	for _, t := range b.Tags {
+		tagsB = append(tagsB, t.Id)
+	}
+
+	score := genreWeight*jaccard(genresA, genresB) + tagWeight*jaccard(tagsA, tagsB)
+
+	directorA := strings.TrimSpace(a.Director)
+	if directorA != "" && strings.EqualFold(directorA, strings.TrimSpace(b.Director)) {
+		score += directorWeight
+	}
+
+	if a.ReleaseYear > 0 && b.ReleaseYear > 0 {
+		distance := float64(a.ReleaseYear - b.ReleaseYear)
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance < eraSpanYears {
+			score += eraWeight * (1 - distance/eraSpanYears)
+		}
+	}
+
+	return score
+}
+
+func jaccard(a, b []int) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	set := make(map[int]bool, len(a))
+	for _, v := range a {
+		set[v] = true
+	}
+	union := len(set)
+	intersection := 0
+	seen := make(map[int]bool, len(b))
+	for _, v := range b {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		if set[v] {
+			intersection++
+		} else {
+			union++
+		}
+	}
+	return float64(intersection) / float64(union)
+}
@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"time"
+)
+
+// chartSize is how many movies are materialized per chart.
+const chartSize = 100
+
+type ChartsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewChartsRepository(conn *pgxpool.Pool) *ChartsRepository {
+	return &ChartsRepository{db: conn}
+}
+
+// FindTrendingActivity counts additions to lists, ratings and views of every
+// movie since the given moment.
+func (r *ChartsRepository) FindTrendingActivity(c context.Context, since time.Time) ([]models.TrendingActivity, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c,
+		`
+select movie_id, count(*) filter (where kind = 'watchlist'), count(*) filter (where kind = 'rating'), count(*) filter (where kind = 'view')
+from (
+	select movie_id, 'watchlist' as kind from list_movies where added_at >= $1
+	union all
+	select movie_id, 'rating' from profile_movies where rating is not null and rated_at >= $1
+	union all
+	select movie_id, 'view' from watch_events where watched_at >= $1
+) events
+group by movie_id
+	`,
+		since)
+	if err != nil {
+		logger.Error("Could not find trending activity", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := make([]models.TrendingActivity, 0)
+	for rows.Next() {
+		var a models.TrendingActivity
+		err = rows.Scan(&a.MovieId, &a.WatchlistAdds, &a.Ratings, &a.Views)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		activity = append(activity, a)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return activity, nil
+}
+
+// Refresh replaces every chart in a single transaction. Trending keeps the
+// first chartSize of the given scores, best first; top-rated charts only
+// include movies rated at least minRatings times.
+func (r *ChartsRepository) Refresh(c context.Context, trending []models.TrendingScore, minRatings int) error {
+	logger := logger.GetLogger()
+	if len(trending) > chartSize {
+		trending = trending[:chartSize]
+	}
+	movieIds := make([]int, 0, len(trending))
+	scores := make([]float64, 0, len(trending))
+	for _, t := range trending {
+		movieIds = append(movieIds, t.MovieId)
+		scores = append(scores, t.Score)
+	}
+
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c, "delete from chart_trending")
+	if err != nil {
+		logger.Error("Could not clear trending chart", zap.String("db_msg", err.Error()))
+		return err
+	}
+	_, err = tx.Exec(c,
+		`
+insert into chart_trending(movie_id, score, position)
+select u.movie_id, u.score, row_number() over (order by u.ordinality)
+from unnest($1::int[], $2::float8[]) with ordinality as u(movie_id, score, ordinality)
+join movies m on m.id = u.movie_id
+	`,
+		movieIds, scores)
+	if err != nil {
+		logger.Error("Could not save trending chart", zap.String("db_msg", err.Error()))
+		return err
+	}
+
+	_, err = tx.Exec(c, "delete from chart_top_rated")
+	if err != nil {
+		logger.Error("Could not clear top rated charts", zap.String("db_msg", err.Error()))
+		return err
+	}
+	_, err = tx.Exec(c,
+		`
+with stats as (
+	select movie_id, avg(rating)::float8 as average, count(*) as ratings_count
+	from profile_movies
+	where rating is not null
+	group by movie_id
+	having count(*) >= @minRatings
+),
+scoped as (
+	select 'all' as scope, 0 as scope_id, s.* from stats s
+	union all
+	select 'genre', mg.genre_id, s.* from stats s join movies_genres mg on mg.movie_id = s.movie_id
+	union all
+	select 'decade', m.release_year / 10 * 10, s.* from stats s join movies m on m.id = s.movie_id
+),
+ranked as (
+	select *, row_number() over (partition by scope, scope_id order by average desc, ratings_count desc, movie_id) as position
+	from scoped
+)
+insert into chart_top_rated(scope, scope_id, movie_id, average, ratings_count, position)
+select scope, scope_id, movie_id, average, ratings_count, position
+from ranked
+where position <= @limit
+	`,
+		pgx.NamedArgs{
+			"minRatings": minRatings,
+			"limit":      chartSize,
+		})
+	if err != nil {
+		logger.Error("Could not compute top rated charts", zap.String("db_msg", err.Error()))
+		return err
+	}
+
+	_, err = tx.Exec(c,
+		`
+insert into chart_refreshes(id, computed_at) values(1, now())
+on conflict (id) do update set computed_at = excluded.computed_at
+	`)
+	if err != nil {
+		logger.Error("Could not save chart refresh time", zap.String("db_msg", err.Error()))
+		return err
+	}
+
+	return tx.Commit(c)
+}
+
+// FindTrending returns the materialized trending chart; movies are not loaded.
+func (r *ChartsRepository) FindTrending(c context.Context) (models.Chart, error) {
+	return r.findChart(c, "select position, score, 0, movie_id from chart_trending order by position")
+}
+
+// FindTopRated returns a materialized top-rated chart. Scope is one of the
+// models.ChartScope constants and scopeId the genre id or decade; movies are
+// not loaded.
+func (r *ChartsRepository) FindTopRated(c context.Context, scope string, scopeId int) (models.Chart, error) {
+	return r.findChart(c,
+		"select position, average, ratings_count, movie_id from chart_top_rated where scope = $1 and scope_id = $2 order by position",
+		scope, scopeId)
+}
+
+func (r *ChartsRepository) findChart(c context.Context, sql string, args ...any) (models.Chart, error) {
+	logger := logger.GetLogger()
+	chart := models.Chart{Entries: make([]models.ChartEntry, 0)}
+
+	err := r.db.QueryRow(c, "select computed_at from chart_refreshes where id = 1").Scan(&chart.ComputedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		logger.Error("Could not find chart refresh time", zap.String("db_msg", err.Error()))
+		return models.Chart{}, err
+	}
+
+	rows, err := r.db.Query(c, sql, args...)
+	if err != nil {
+		logger.Error("Could not find chart", zap.String("db_msg", err.Error()))
+		return models.Chart{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.ChartEntry
+		err = rows.Scan(&entry.Position, &entry.Score, &entry.RatingsCount, &entry.MovieId)
+		if err != nil {
+			logger.Error(err.Error())
+			return models.Chart{}, err
+		}
+		chart.Entries = append(chart.Entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return models.Chart{}, err
+	}
+
+	return chart, nil
+}
@@ -0,0 +1,281 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+)
+
+type CollectionsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCollectionsRepository(conn *pgxpool.Pool) *CollectionsRepository {
+	return &CollectionsRepository{db: conn}
+}
+
+func (r *CollectionsRepository) FindAll(c context.Context) ([]models.Collection, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, "select id, title, description, cover_url from collections order by title")
+	if err != nil {
+		logger.Error("Could not find all collections", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := make([]models.Collection, 0)
+	for rows.Next() {
+		var collection models.Collection
+		err = rows.Scan(&collection.Id, &collection.Title, &collection.Description, &collection.CoverUrl)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+func (r *CollectionsRepository) FindById(c context.Context, id int) (models.Collection, error) {
+	logger := logger.GetLogger()
+	var collection models.Collection
+	row := r.db.QueryRow(c, "select id, title, description, cover_url from collections where id = $1", id)
+	err := row.Scan(&collection.Id, &collection.Title, &collection.Description, &collection.CoverUrl)
+	if err != nil {
+		logger.Error("Could not find collection", zap.String("db_msg", err.Error()))
+		return models.Collection{}, err
+	}
+	return collection, nil
+}
+
+// FindMovieIds returns ids of the collection movies in their collection order.
+func (r *CollectionsRepository) FindMovieIds(c context.Context, id int) ([]int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, "select movie_id from collections_movies where collection_id = $1 order by position", id)
+	if err != nil {
+		logger.Error("Could not find collection movies", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var movieId int
+		err = rows.Scan(&movieId)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		ids = append(ids, movieId)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *CollectionsRepository) Create(c context.Context, collection models.Collection) (int, error) {
+	logger := logger.GetLogger()
+	var id int
+	err := r.db.QueryRow(c,
+		"insert into collections(title, description, cover_url) values($1, $2, $3) returning id",
+		collection.Title, collection.Description, collection.CoverUrl).Scan(&id)
+	if err != nil {
+		logger.Error("Could not insert collection", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *CollectionsRepository) Update(c context.Context, id int, collection models.Collection) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c,
+		"update collections set title = $1, description = $2, cover_url = $3 where id = $4",
+		collection.Title, collection.Description, collection.CoverUrl, id)
+	if err != nil {
+		logger.Error("Could not update collection", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (r *CollectionsRepository) Delete(c context.Context, id int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from collections where id = $1", id)
+	if err != nil {
+		logger.Error("Could not delete collection", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// SetMovies replaces the collection membership with movieIds in the given order.
+func (r *CollectionsRepository) SetMovies(c context.Context, id int, movieIds []int) error {
+	logger := logger.GetLogger()
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c, "delete from collections_movies where collection_id = $1", id)
+	if err != nil {
+		logger.Error("Could not clear collection movies", zap.String("db_msg", err.Error()))
+		return err
+	}
+	for i, movieId := range movieIds {
+		_, err = tx.Exec(c, "insert into collections_movies(collection_id, movie_id, position) values($1, $2, $3)", id, movieId, i+1)
+		if err != nil {
+			logger.Error("Could not add collection movie", zap.String("db_msg", err.Error()))
+			return err
+		}
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+// AddMovie inserts the movie at position (1-based), shifting the following
+// movies down. A position of 0 or past the end appends the movie.
+func (r *CollectionsRepository) AddMovie(c context.Context, id int, movieId int, position int) error {
+	logger := logger.GetLogger()
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(c)
+
+	var count int
+	err = tx.QueryRow(c, "select count(*) from collections_movies where collection_id = $1 and movie_id <> $2", id, movieId).Scan(&count)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	if position <= 0 || position > count+1 {
+		position = count + 1
+	}
+
+	_, err = tx.Exec(c, "delete from collections_movies where collection_id = $1 and movie_id = $2", id, movieId)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	err = renumberCollection(c, tx, id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(c, "update collections_movies set position = position + 1 where collection_id = $1 and position >= $2", id, position)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	_, err = tx.Exec(c, "insert into collections_movies(collection_id, movie_id, position) values($1, $2, $3)", id, movieId, position)
+	if err != nil {
+		logger.Error("Could not add collection movie", zap.String("db_msg", err.Error()))
+		return err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r *CollectionsRepository) RemoveMovie(c context.Context, id int, movieId int) error {
+	logger := logger.GetLogger()
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c, "delete from collections_movies where collection_id = $1 and movie_id = $2", id, movieId)
+	if err != nil {
+		logger.Error("Could not remove collection movie", zap.String("db_msg", err.Error()))
+		return err
+	}
+	err = renumberCollection(c, tx, id)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+// renumberCollection closes gaps in positions left by removed movies.
+func renumberCollection(c context.Context, tx pgx.Tx, id int) error {
+	logger := logger.GetLogger()
+	_, err := tx.Exec(c,
+		`
+update collections_movies cm
+set position = o.position
+from (
+	select movie_id, row_number() over (order by position) as position
+	from collections_movies
+	where collection_id = $1
+) o
+where cm.collection_id = $1 and cm.movie_id = o.movie_id
+	`,
+		id)
+	if err != nil {
+		logger.Error("Could not renumber collection", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func findCollectionsByMovieId(c context.Context, db *pgxpool.Pool, movieId int) ([]models.Collection, error) {
+	logger := logger.GetLogger()
+	rows, err := db.Query(c,
+		`
+select col.id, col.title, col.description, col.cover_url
+from collections col
+join collections_movies cm on cm.collection_id = col.id
+where cm.movie_id = $1
+order by col.title
+	`,
+		movieId)
+	if err != nil {
+		logger.Error("Could not find movie collections", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := make([]models.Collection, 0)
+	for rows.Next() {
+		var collection models.Collection
+		err = rows.Scan(&collection.Id, &collection.Title, &collection.Description, &collection.CoverUrl)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return collections, nil
+}
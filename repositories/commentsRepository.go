@@ -0,0 +1,388 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+)
+
+type CommentsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCommentsRepository(conn *pgxpool.Pool) *CommentsRepository {
+	return &CommentsRepository{db: conn}
+}
+
+// commentColumns expects the viewing profile id as $2.
+const commentColumns = `
+c.id, c.movie_id, c.parent_id, c.profile_id, p.name, c.language, c.body, c.status, c.deleted_at is not null,
+(select count(*) from comment_reactions cr where cr.comment_id = c.id and cr.value = 1),
+(select count(*) from comment_reactions cr where cr.comment_id = c.id and cr.value = -1),
+coalesce((select cr.value from comment_reactions cr where cr.comment_id = c.id and cr.profile_id = $2), 0),
+c.created_at
+`
+
+// commentVisible hides held comments from everyone but their author ($2) and
+// drops removed comments that nobody has replied to.
+const commentVisible = `
+(c.status <> 'held' or c.profile_id = $2)
+and ((c.deleted_at is null and c.status <> 'rejected') or exists(select 1 from comments r where r.parent_id = c.id))
+`
+
+func scanComment(row pgx.Row, extra ...any) (models.Comment, error) {
+	var comment models.Comment
+	var reaction int
+	dest := []any{&comment.Id, &comment.MovieId, &comment.ParentId, &comment.ProfileId, &comment.AuthorName,
+		&comment.Language, &comment.Body, &comment.Status, &comment.IsDeleted, &comment.Likes, &comment.Dislikes,
+		&reaction, &comment.CreatedAt}
+	err := row.Scan(append(dest, extra...)...)
+
+	switch reaction {
+	case 1:
+		comment.MyReaction = models.ReactionLike
+	case -1:
+		comment.MyReaction = models.ReactionDislike
+	}
+	if comment.IsRemoved() {
+		comment.ProfileId = 0
+		comment.AuthorName = ""
+		comment.Body = ""
+	}
+	return comment, err
+}
+
+// FindThreads returns a page of the movie's top-level comments, newest first,
+// each with its whole reply tree in chronological order.
+func (r *CommentsRepository) FindThreads(c context.Context, movieId int, profileId int, limit int, offset int) ([]models.Comment, int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, `
+with recursive top as (
+    select c.id, count(*) over () as total
+    from comments c
+    where c.movie_id = $1 and c.parent_id is null and `+commentVisible+`
+    order by c.created_at desc, c.id desc
+    limit $3 offset $4
+),
+thread as (
+    select id from top
+    union all
+    select c.id
+    from comments c
+    join thread t on c.parent_id = t.id
+    where `+commentVisible+`
+)
+select `+commentColumns+`, (select total from top limit 1)
+from thread
+join comments c on c.id = thread.id
+join profiles p on p.id = c.profile_id
+order by c.created_at, c.id
+	`, movieId, profileId, limit, offset)
+	if err != nil {
+		logger.Error("Could not find comments", zap.String("db_msg", err.Error()))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	total := 0
+	comments := make([]models.Comment, 0)
+	for rows.Next() {
+		comment, err := scanComment(rows, &total)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, 0, err
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, 0, err
+	}
+
+	return buildCommentTree(comments), total, nil
+}
+
+// buildCommentTree nests replies under their parents. comments must be in
+// chronological order; roots are returned newest first.
+func buildCommentTree(comments []models.Comment) []models.Comment {
+	children := make(map[int][]models.Comment)
+	roots := make([]models.Comment, 0)
+	for _, comment := range comments {
+		if comment.ParentId == nil {
+			roots = append([]models.Comment{comment}, roots...)
+		} else {
+			children[*comment.ParentId] = append(children[*comment.ParentId], comment)
+		}
+	}
+
+	var attach func(comment *models.Comment)
+	attach = func(comment *models.Comment) {
+		comment.Replies = children[comment.Id]
+		if comment.Replies == nil {
+			comment.Replies = make([]models.Comment, 0)
+		}
+		for i := range comment.Replies {
+			attach(&comment.Replies[i])
+		}
+	}
+	for i := range roots {
+		attach(&roots[i])
+	}
+	return roots
+}
+
+// FindById returns the comment as the profile sees it, without replies.
+func (r *CommentsRepository) FindById(c context.Context, id int, profileId int) (models.Comment, error) {
+	logger := logger.GetLogger()
+	comment, err := scanComment(r.db.QueryRow(c,
+		"select "+commentColumns+" from comments c join profiles p on p.id = c.profile_id where c.id = $1",
+		id, profileId))
+	if err != nil {
+		logger.Error("Could not find comment", zap.String("db_msg", err.Error()))
+		return models.Comment{}, err
+	}
+	return comment, nil
+}
+
+// Create stores the comment. A comment with flagged words is held for review.
+func (r *CommentsRepository) Create(c context.Context, comment models.Comment, flaggedWords []string) (int, error) {
+	logger := logger.GetLogger()
+	status := models.CommentStatusPublished
+	if len(flaggedWords) > 0 {
+		status = models.CommentStatusHeld
+	}
+
+	var id int
+	err := r.db.QueryRow(c, `
+insert into comments(movie_id, profile_id, parent_id, language, body, status, flagged_words)
+values(@movieId, @profileId, @parentId, @language, @body, @status, @flaggedWords)
+returning id
+	`, pgx.NamedArgs{
+		"movieId":      comment.MovieId,
+		"profileId":    comment.ProfileId,
+		"parentId":     comment.ParentId,
+		"language":     comment.Language,
+		"body":         comment.Body,
+		"status":       status,
+		"flaggedWords": flaggedWords,
+	}).Scan(&id)
+	if err != nil {
+		logger.Error("Could not insert comment", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return id, nil
+}
+
+// Delete marks the comment deleted; replies stay attached to it.
+func (r *CommentsRepository) Delete(c context.Context, id int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "update comments set deleted_at = now() where id = $1 and deleted_at is null", id)
+	if err != nil {
+		logger.Error("Could not delete comment", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// SetReaction records a like (1) or dislike (-1) of the profile. Zero
+// removes the reaction.
+func (r *CommentsRepository) SetReaction(c context.Context, id int, profileId int, value int) error {
+	logger := logger.GetLogger()
+	var err error
+	if value == 0 {
+		_, err = r.db.Exec(c, "delete from comment_reactions where comment_id = $1 and profile_id = $2", id, profileId)
+	} else {
+		_, err = r.db.Exec(c, `
+insert into comment_reactions(comment_id, profile_id, value) values($1, $2, $3)
+on conflict (comment_id, profile_id) do update set value = excluded.value
+		`, id, profileId, value)
+	}
+	if err != nil {
+		logger.Error("Could not set comment reaction", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// Report files the profile's report on the comment. Reporting again replaces
+// the reason and reopens a report that was already resolved.
+func (r *CommentsRepository) Report(c context.Context, id int, profileId int, reason string) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, `
+insert into comment_reports(comment_id, profile_id, reason) values($1, $2, $3)
+on conflict (comment_id, profile_id) do update set reason = excluded.reason, created_at = now(), resolved_at = null
+	`, id, profileId, reason)
+	if err != nil {
+		logger.Error("Could not report comment", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// FindFlagged returns the moderation queue: held comments and comments with
+// open reports, oldest first.
+func (r *CommentsRepository) FindFlagged(c context.Context, limit int, offset int) ([]models.FlaggedComment, int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, `
+select `+commentColumns+`, c.flagged_words, count(*) over ()
+from comments c
+join profiles p on p.id = c.profile_id
+where c.deleted_at is null
+  and (c.status = $1 or exists(select 1 from comment_reports cr where cr.comment_id = c.id and cr.resolved_at is null))
+order by c.created_at, c.id
+limit $3 offset $4
+	`, models.CommentStatusHeld, 0, limit, offset)
+	if err != nil {
+		logger.Error("Could not find flagged comments", zap.String("db_msg", err.Error()))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	total := 0
+	comments := make([]models.FlaggedComment, 0)
+	ids := make([]int, 0)
+	for rows.Next() {
+		var comment models.FlaggedComment
+		comment.Comment, err = scanComment(rows, &comment.FlaggedWords, &total)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, 0, err
+		}
+		comment.Reports = make([]models.CommentReport, 0)
+		comments = append(comments, comment)
+		ids = append(ids, comment.Id)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, 0, err
+	}
+	rows.Close()
+
+	reports, err := r.findOpenReports(c, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range comments {
+		if found, ok := reports[comments[i].Id]; ok {
+			comments[i].Reports = found
+		}
+	}
+	return comments, total, nil
+}
+
+func (r *CommentsRepository) findOpenReports(c context.Context, commentIds []int) (map[int][]models.CommentReport, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, `
+select comment_id, id, profile_id, reason, created_at
+from comment_reports
+where comment_id = any($1) and resolved_at is null
+order by created_at, id
+	`, commentIds)
+	if err != nil {
+		logger.Error("Could not find comment reports", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := make(map[int][]models.CommentReport)
+	for rows.Next() {
+		var commentId int
+		var report models.CommentReport
+		err = rows.Scan(&commentId, &report.Id, &report.ProfileId, &report.Reason, &report.CreatedAt)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		reports[commentId] = append(reports[commentId], report)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+	return reports, nil
+}
+
+// Moderate publishes or rejects the comment on behalf of the moderator and
+// resolves its open reports.
+func (r *CommentsRepository) Moderate(c context.Context, id int, status string, moderatorId int) error {
+	logger := logger.GetLogger()
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c,
+		"update comments set status = $1, moderated_by = $2, moderated_at = now() where id = $3",
+		status, moderatorId, id)
+	if err != nil {
+		logger.Error("Could not moderate comment", zap.String("db_msg", err.Error()))
+		return err
+	}
+	_, err = tx.Exec(c,
+		"update comment_reports set resolved_at = now() where comment_id = $1 and resolved_at is null", id)
+	if err != nil {
+		logger.Error("Could not resolve comment reports", zap.String("db_msg", err.Error()))
+		return err
+	}
+
+	return tx.Commit(c)
+}
+
+// FindBlocklist returns the blocked words of the language, or of every
+// language when language is empty.
+func (r *CommentsRepository) FindBlocklist(c context.Context, language string) ([]models.BlockedWord, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c,
+		"select id, language, word from comment_blocklist where $1 = '' or language = $1 order by language, word",
+		language)
+	if err != nil {
+		logger.Error("Could not find blocklist", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	words := make([]models.BlockedWord, 0)
+	for rows.Next() {
+		var word models.BlockedWord
+		err = rows.Scan(&word.Id, &word.Language, &word.Word)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		words = append(words, word)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+	return words, nil
+}
+
+func (r *CommentsRepository) AddBlockedWord(c context.Context, word models.BlockedWord) (int, error) {
+	logger := logger.GetLogger()
+	var id int
+	err := r.db.QueryRow(c, `
+insert into comment_blocklist(language, word) values($1, $2)
+on conflict (language, word) do update set word = excluded.word
+returning id
+	`, word.Language, word.Word).Scan(&id)
+	if err != nil {
+		logger.Error("Could not add blocked word", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *CommentsRepository) DeleteBlockedWord(c context.Context, id int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from comment_blocklist where id = $1", id)
+	if err != nil {
+		logger.Error("Could not delete blocked word", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
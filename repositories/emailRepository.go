@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"time"
+)
+
+type EmailRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEmailRepository(conn *pgxpool.Pool) *EmailRepository {
+	return &EmailRepository{db: conn}
+}
+
+// GetPreferences returns the user's email preferences, or the defaults when
+// the user has not changed them.
+func (r *EmailRepository) GetPreferences(c context.Context, userId int) (models.EmailPreferences, error) {
+	logger := logger.GetLogger()
+	var preferences models.EmailPreferences
+	err := r.db.QueryRow(c, "select language, new_releases, weekly_digest from email_preferences where user_id = $1", userId).
+		Scan(&preferences.Language, &preferences.NewReleases, &preferences.WeeklyDigest)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.DefaultEmailPreferences(), nil
+	}
+	if err != nil {
+		logger.Error("Could not find email preferences", zap.String("db_msg", err.Error()))
+		return models.EmailPreferences{}, err
+	}
+	return preferences, nil
+}
+
+func (r *EmailRepository) SetPreferences(c context.Context, userId int, preferences models.EmailPreferences) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, `
+insert into email_preferences(user_id, language, new_releases, weekly_digest)
+values(@userId, @language, @newReleases, @weeklyDigest)
+on conflict (user_id) do update
+set language = excluded.language,
+    new_releases = excluded.new_releases,
+    weekly_digest = excluded.weekly_digest,
+    updated_at = now()
+	`, pgx.NamedArgs{
+		"userId":       userId,
+		"language":     preferences.Language,
+		"newReleases":  preferences.NewReleases,
+		"weeklyDigest": preferences.WeeklyDigest,
+	})
+	if err != nil {
+		logger.Error("Could not save email preferences", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// FindMaxAgeRating returns the highest age rating any of the user's profiles
+// may watch; emails do not mention movies above it.
+func (r *EmailRepository) FindMaxAgeRating(c context.Context, userId int) (int, error) {
+	logger := logger.GetLogger()
+	var maxAgeRating int
+	err := r.db.QueryRow(c, "select coalesce(max(max_age_rating), 0) from profiles where user_id = $1", userId).Scan(&maxAgeRating)
+	if err != nil {
+		logger.Error("Could not find max age rating", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return maxAgeRating, nil
+}
+
+// FindNewReleaseRecipients returns the users who want new release emails and
+// have one of the movie's genres among the favourite genres of a profile
+// allowed to watch it.
+func (r *EmailRepository) FindNewReleaseRecipients(c context.Context, movieId int) ([]int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, `
+with `+favouriteGenresCte+`
+select distinct p.user_id
+from favourite_genres f
+join profiles p on p.id = f.profile_id
+join movies m on m.id = $1
+left join email_preferences ep on ep.user_id = p.user_id
+where f.position <= $2
+  and f.genre_id in (select genre_id from movies_genres where movie_id = $1)
+  and p.max_age_rating >= m.age_rating
+  and coalesce(ep.new_releases, true)
+	`, movieId, favouriteGenresLimit)
+	if err != nil {
+		logger.Error("Could not find new release recipients", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	return collectIds(rows)
+}
+
+// FindDigestRecipients returns the users who want the weekly digest.
+func (r *EmailRepository) FindDigestRecipients(c context.Context) ([]int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, `
+select u.id
+from users u
+left join email_preferences ep on ep.user_id = u.id
+where coalesce(ep.weekly_digest, true)
+order by u.id
+	`)
+	if err != nil {
+		logger.Error("Could not find digest recipients", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	return collectIds(rows)
+}
+
+// FindNewMovieIds returns the movies added since the given time, newest
+// first. Movies added before the catalog recorded the date have none and are
+// never returned.
+func (r *EmailRepository) FindNewMovieIds(c context.Context, since time.Time, maxAgeRating int, limit int) ([]int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, `
+select id
+from movies
+where created_at >= $1 and age_rating <= $2
+order by created_at desc, id desc
+limit $3
+	`, since, maxAgeRating, limit)
+	if err != nil {
+		logger.Error("Could not find new movies", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	return collectIds(rows)
+}
+
+func collectIds(rows pgx.Rows) ([]int, error) {
+	logger := logger.GetLogger()
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+	return ids, nil
+}
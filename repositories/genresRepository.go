@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"go.uber.org/zap"
+	"goozinshe/events"
 	"goozinshe/logger"
 	"goozinshe/models"
 
@@ -17,10 +18,21 @@ func NewGenresRepository(conn *pgxpool.Pool) *GenresRepository {
 	return &GenresRepository{db: conn}
 }
 
-func (r *GenresRepository) FindById(c context.Context, id int) (models.Genre, error) {
+const localizedGenreSql = `
+select g.id, coalesce(nullif(gt.title, ''), g.title)
+from genres g
+left join lateral (
+	select t.title from genre_translations t
+	where t.genre_id = g.id and t.language = any($1::text[])
+	order by array_position($1::text[], t.language)
+	limit 1
+) gt on true
+`
+
+func (r *GenresRepository) FindById(c context.Context, id int, lang string) (models.Genre, error) {
 	var genre models.Genre
 	logger := logger.GetLogger()
-	row := r.db.QueryRow(c, "select id, title from genres where id = $1", id)
+	row := r.db.QueryRow(c, localizedGenreSql+"where g.id = $2", models.LanguageFallbacks(lang), id)
 	err := row.Scan(&genre.Id, &genre.Title)
 	if err != nil {
 		logger.Error("Could not find genre", zap.String("db_msg", err.Error()))
@@ -30,9 +42,9 @@ func (r *GenresRepository) FindById(c context.Context, id int) (models.Genre, er
 	return genre, nil
 }
 
-func (r *GenresRepository) FindAll(c context.Context) ([]models.Genre, error) {
+func (r *GenresRepository) FindAll(c context.Context, lang string) ([]models.Genre, error) {
 	logger := logger.GetLogger()
-	rows, err := r.db.Query(c, "select id, title from genres")
+	rows, err := r.db.Query(c, localizedGenreSql, models.LanguageFallbacks(lang))
 	defer rows.Close()
 	if err != nil {
 		logger.Error("Could not find all genres", zap.String("db_msg", err.Error()))
@@ -83,11 +95,29 @@ func (r *GenresRepository) FindAllByIds(c context.Context, ids []int) ([]models.
 func (r *GenresRepository) Create(c context.Context, genre models.Genre) (int, error) {
 	var id int
 	logger := logger.GetLogger()
-	row := r.db.QueryRow(c, "insert into genres (title) values ($1) returning id", genre.Title)
-	err := row.Scan(&id)
+	tx, err := r.db.Begin(c)
 	if err != nil {
 		logger.Error(err.Error())
-		return 0, nil
+		return 0, err
+	}
+	defer tx.Rollback(c)
+
+	row := tx.QueryRow(c, "insert into genres (title) values ($1) returning id", genre.Title)
+	err = row.Scan(&id)
+	if err != nil {
+		logger.Error(err.Error())
+		return 0, err
+	}
+
+	err = addOutboxEvent(c, tx, events.GenreCreated, map[string]any{"id": id})
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return 0, err
 	}
 
 	return id, nil
@@ -95,22 +125,114 @@ func (r *GenresRepository) Create(c context.Context, genre models.Genre) (int, e
 
 func (r *GenresRepository) Update(c context.Context, id int, genre models.Genre) error {
 	logger := logger.GetLogger()
-	_, err := r.db.Exec(c, "update genres set title = $1 where id = $2", genre.Title, genre.Id)
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c, "update genres set title = $1 where id = $2", genre.Title, id)
 	if err != nil {
 		logger.Error("Could not update genre", zap.String("db_msg", err.Error()))
 		return err
 	}
 
+	err = addOutboxEvent(c, tx, events.GenreUpdated, map[string]any{"id": id})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+
 	return nil
 }
 
 func (r *GenresRepository) Delete(c context.Context, id int) error {
 	logger := logger.GetLogger()
-	_, err := r.db.Exec(c, "delete from genres where id = $1", id)
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c, "delete from genres where id = $1", id)
 	if err != nil {
 		logger.Error("Could not delete genre", zap.String("db_msg", err.Error()))
 		return err
 	}
 
+	err = addOutboxEvent(c, tx, events.GenreDeleted, map[string]any{"id": id})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Follow subscribes the profile to new movies in the genre. Following a
+// genre twice is not an error.
+func (r *GenresRepository) Follow(c context.Context, profileId int, genreId int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c,
+		"insert into genre_follows(profile_id, genre_id) values($1, $2) on conflict do nothing",
+		profileId, genreId)
+	if err != nil {
+		logger.Error("Could not follow genre", zap.String("db_msg", err.Error()))
+		return err
+	}
 	return nil
 }
+
+func (r *GenresRepository) Unfollow(c context.Context, profileId int, genreId int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from genre_follows where profile_id = $1 and genre_id = $2", profileId, genreId)
+	if err != nil {
+		logger.Error("Could not unfollow genre", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// FindFollowed returns the genres the profile follows, most recently
+// followed first.
+func (r *GenresRepository) FindFollowed(c context.Context, profileId int, lang string) ([]models.Genre, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, localizedGenreSql+`
+join genre_follows f on f.genre_id = g.id
+where f.profile_id = $2
+order by f.created_at desc, g.id
+	`, models.LanguageFallbacks(lang), profileId)
+	if err != nil {
+		logger.Error("Could not find followed genres", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	genres := make([]models.Genre, 0)
+	for rows.Next() {
+		var genre models.Genre
+		err = rows.Scan(&genre.Id, &genre.Title)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+	return genres, nil
+}
@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"time"
+)
+
+type HistoryRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewHistoryRepository(conn *pgxpool.Pool) *HistoryRepository {
+	return &HistoryRepository{db: conn}
+}
+
+// FindPage returns watch events of the viewer's profile, newest first, and
+// the total number of events in the range. Events of movies above the
+// viewer's age rating are left out. Zero from and to leave the range open.
+func (r *HistoryRepository) FindPage(c context.Context, viewer models.Viewer, from time.Time, to time.Time, limit int, offset int) ([]models.WatchEvent, int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c,
+		`
+select e.id, e.movie_id, e.watched_at, count(*) over ()
+from watch_events e
+join movies m on m.id = e.movie_id
+where e.profile_id = $1
+  and m.age_rating <= $2
+  and ($3::timestamp is null or e.watched_at >= $3)
+  and ($4::timestamp is null or e.watched_at < $4)
+order by e.watched_at desc, e.id desc
+limit $5 offset $6
+	`,
+		viewer.ProfileId, viewer.MaxAgeRating, nullableTime(from), nullableTime(to), limit, offset)
+	if err != nil {
+		logger.Error("Could not find watch history", zap.String("db_msg", err.Error()))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	total := 0
+	events := make([]models.WatchEvent, 0)
+	for rows.Next() {
+		var e models.WatchEvent
+		err = rows.Scan(&e.Id, &e.MovieId, &e.WatchedAt, &total)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, 0, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, 0, err
+	}
+
+	if len(events) == 0 && offset > 0 {
+		err = r.db.QueryRow(c,
+			`
+select count(*)
+from watch_events e
+join movies m on m.id = e.movie_id
+where e.profile_id = $1
+  and m.age_rating <= $2
+  and ($3::timestamp is null or e.watched_at >= $3)
+  and ($4::timestamp is null or e.watched_at < $4)
+	`,
+			viewer.ProfileId, viewer.MaxAgeRating, nullableTime(from), nullableTime(to)).Scan(&total)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, 0, err
+		}
+	}
+
+	return events, total, nil
+}
+
+// Add logs a (re)watch at watchedAt and marks the movie as watched the same
+// way MoviesRepository.SetWatched does.
+func (r *HistoryRepository) Add(c context.Context, profileId int, movieId int, watchedAt time.Time) (int, error) {
+	logger := logger.GetLogger()
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(c)
+
+	var id int
+	err = tx.QueryRow(c,
+		"insert into watch_events(profile_id, movie_id, watched_at) values($1, $2, $3) returning id",
+		profileId, movieId, watchedAt).Scan(&id)
+	if err != nil {
+		logger.Error("Could not add watch event", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+
+	_, err = setWatched(c, tx, profileId, movieId, true)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return 0, err
+	}
+	return id, nil
+}
+
+// Delete removes a single watch event. When it was the last event of the
+// movie, the movie is no longer considered watched. It reports whether the
+// event belonged to the profile.
+func (r *HistoryRepository) Delete(c context.Context, profileId int, id int) (bool, error) {
+	logger := logger.GetLogger()
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(c)
+
+	var movieId int
+	err = tx.QueryRow(c, "delete from watch_events where id = $1 and profile_id = $2 returning movie_id", id, profileId).Scan(&movieId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		logger.Error("Could not delete watch event", zap.String("db_msg", err.Error()))
+		return false, err
+	}
+
+	_, err = tx.Exec(c,
+		`
+update profile_movies set is_watched = false
+where profile_id = $1 and movie_id = $2
+  and not exists (select 1 from watch_events where profile_id = $1 and movie_id = $2)
+	`,
+		profileId, movieId)
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+	return true, nil
+}
+
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,247 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"time"
+)
+
+type JobsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewJobsRepository(conn *pgxpool.Pool) *JobsRepository {
+	return &JobsRepository{db: conn}
+}
+
+const jobColumns = `
+id, queue, type, payload, status, attempts, max_attempts, run_at, locked_until, last_error, unique_key,
+created_at, started_at, finished_at
+`
+
+func scanJob(row pgx.Row, extra ...any) (models.Job, error) {
+	var job models.Job
+	dest := []any{&job.Id, &job.Queue, &job.Type, &job.Payload, &job.Status, &job.Attempts, &job.MaxAttempts,
+		&job.RunAt, &job.LockedUntil, &job.LastError, &job.UniqueKey, &job.CreatedAt, &job.StartedAt, &job.FinishedAt}
+	err := row.Scan(append(dest, extra...)...)
+	return job, err
+}
+
+// Enqueue adds the job to its queue. When a job with the same unique key
+// already exists nothing is added and created is false.
+func (r *JobsRepository) Enqueue(c context.Context, job models.Job) (id int64, created bool, err error) {
+	logger := logger.GetLogger()
+	err = r.db.QueryRow(c, `
+insert into jobs(queue, type, payload, max_attempts, run_at, unique_key)
+values(@queue, @type, @payload::jsonb, @maxAttempts, @runAt, @uniqueKey)
+on conflict (unique_key) where unique_key is not null do nothing
+returning id
+	`, pgx.NamedArgs{
+		"queue":       job.Queue,
+		"type":        job.Type,
+		"payload":     []byte(job.Payload),
+		"maxAttempts": job.MaxAttempts,
+		"runAt":       job.RunAt,
+		"uniqueKey":   job.UniqueKey,
+	}).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		logger.Error("Could not enqueue job", zap.String("type", job.Type), zap.String("db_msg", err.Error()))
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+// Claim starts up to limit due jobs of the queue and leases them for the
+// given duration. Jobs locked by another worker are skipped; running jobs
+// whose lease has expired are claimed again.
+func (r *JobsRepository) Claim(c context.Context, queue string, limit int, lease time.Duration) ([]models.Job, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, `
+update jobs
+set status = @running,
+    attempts = attempts + 1,
+    locked_until = now() + @lease::float8 * interval '1 second',
+    started_at = now()
+where id in (
+    select id
+    from jobs
+    where queue = @queue
+      and ((status = @pending and run_at <= now()) or (status = @running and locked_until < now()))
+    order by run_at, id
+    limit @limit
+    for update skip locked
+)
+returning `+jobColumns, pgx.NamedArgs{
+		"queue":   queue,
+		"limit":   limit,
+		"lease":   lease.Seconds(),
+		"pending": models.JobStatusPending,
+		"running": models.JobStatusRunning,
+	})
+	if err != nil {
+		logger.Error("Could not claim jobs", zap.String("queue", queue), zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]models.Job, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *JobsRepository) Complete(c context.Context, id int64) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c,
+		"update jobs set status = $1, locked_until = null, last_error = '', finished_at = now() where id = $2",
+		models.JobStatusSucceeded, id)
+	if err != nil {
+		logger.Error("Could not complete job", zap.Int64("job_id", id), zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// Fail records the error of an attempt. The job is retried at retryAt or,
+// when it is nil, marked as failed for good.
+func (r *JobsRepository) Fail(c context.Context, id int64, jobErr string, retryAt *time.Time) error {
+	logger := logger.GetLogger()
+	status := models.JobStatusFailed
+	if retryAt != nil {
+		status = models.JobStatusPending
+	}
+
+	_, err := r.db.Exec(c, `
+update jobs
+set status = @status,
+    locked_until = null,
+    last_error = @error,
+    run_at = coalesce(@retryAt, run_at),
+    finished_at = case when @retryAt::timestamp is null then now() end
+where id = @id
+	`, pgx.NamedArgs{
+		"id":      id,
+		"status":  status,
+		"error":   jobErr,
+		"retryAt": retryAt,
+	})
+	if err != nil {
+		logger.Error("Could not record job failure", zap.Int64("job_id", id), zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// FindAll returns a page of jobs, newest first, optionally narrowed down by
+// queue, type and status.
+func (r *JobsRepository) FindAll(c context.Context, queue string, jobType string, status string, limit int, offset int) ([]models.Job, int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, `
+select `+jobColumns+`, count(*) over ()
+from jobs
+where (@queue::text = '' or queue = @queue)
+  and (@type::text = '' or type = @type)
+  and (@status::text = '' or status = @status)
+order by created_at desc, id desc
+limit @limit offset @offset
+	`, pgx.NamedArgs{
+		"queue":  queue,
+		"type":   jobType,
+		"status": status,
+		"limit":  limit,
+		"offset": offset,
+	})
+	if err != nil {
+		logger.Error("Could not find jobs", zap.String("db_msg", err.Error()))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	total := 0
+	jobs := make([]models.Job, 0)
+	for rows.Next() {
+		job, err := scanJob(rows, &total)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, 0, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+// CountByStatus returns the number of jobs per queue and status.
+func (r *JobsRepository) CountByStatus(c context.Context) ([]models.JobCount, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, "select queue, status, count(*) from jobs group by queue, status order by queue, status")
+	if err != nil {
+		logger.Error("Could not count jobs", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]models.JobCount, 0)
+	for rows.Next() {
+		var count models.JobCount
+		err := rows.Scan(&count.Queue, &count.Status, &count.Count)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+	return counts, nil
+}
+
+// Retry puts a failed job back in its queue with a fresh set of attempts.
+// It reports false when there is no failed job with the id.
+func (r *JobsRepository) Retry(c context.Context, id int64) (bool, error) {
+	logger := logger.GetLogger()
+	tag, err := r.db.Exec(c,
+		"update jobs set status = $1, attempts = 0, run_at = now(), finished_at = null where id = $2 and status = $3",
+		models.JobStatusPending, id, models.JobStatusFailed)
+	if err != nil {
+		logger.Error("Could not retry job", zap.Int64("job_id", id), zap.String("db_msg", err.Error()))
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteFinished removes succeeded and failed jobs that finished before the
+// given time.
+func (r *JobsRepository) DeleteFinished(c context.Context, before time.Time) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from jobs where status in ($1, $2) and finished_at < $3",
+		models.JobStatusSucceeded, models.JobStatusFailed, before)
+	if err != nil {
+		logger.Error("Could not delete finished jobs", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,424 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+)
+
+type ListsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewListsRepository(conn *pgxpool.Pool) *ListsRepository {
+	return &ListsRepository{db: conn}
+}
+
+const listColumns = `
+l.id, l.profile_id, l.name, l.is_public, l.is_default,
+(select count(*) from list_movies lm where lm.list_id = l.id),
+l.created_at,
+coalesce(l.share_token, '')
+`
+
+func scanList(row pgx.Row) (models.List, error) {
+	var list models.List
+	err := row.Scan(&list.Id, &list.ProfileId, &list.Name, &list.IsPublic, &list.IsDefault, &list.MoviesCount, &list.CreatedAt, &list.ShareToken)
+	return list, err
+}
+
+// FindAllByProfileId returns the profile's lists, the default one first. The
+// default list is created on first use.
+func (r *ListsRepository) FindAllByProfileId(c context.Context, profileId int) ([]models.List, error) {
+	logger := logger.GetLogger()
+	_, err := defaultListId(c, r.db, profileId)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(c, "select "+listColumns+" from lists l where l.profile_id = $1 order by l.is_default desc, l.created_at, l.id", profileId)
+	if err != nil {
+		logger.Error("Could not find lists", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := make([]models.List, 0)
+	for rows.Next() {
+		list, err := scanList(rows)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return lists, nil
+}
+
+func (r *ListsRepository) FindById(c context.Context, id int) (models.List, error) {
+	logger := logger.GetLogger()
+	list, err := scanList(r.db.QueryRow(c, "select "+listColumns+" from lists l where l.id = $1", id))
+	if err != nil {
+		logger.Error("Could not find list", zap.String("db_msg", err.Error()))
+		return models.List{}, err
+	}
+	return list, nil
+}
+
+// FindEntries returns the list's movie ids with their notes in list order.
+func (r *ListsRepository) FindEntries(c context.Context, id int) ([]models.ListEntry, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, "select "+listEntryColumns+" from list_movies where list_id = $1 order by position", id)
+	if err != nil {
+		logger.Error("Could not find list movies", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	return scanListEntries(rows)
+}
+
+func (r *ListsRepository) FindByShareToken(c context.Context, token string) (models.List, error) {
+	logger := logger.GetLogger()
+	list, err := scanList(r.db.QueryRow(c, "select "+listColumns+" from lists l where l.share_token = $1", token))
+	if err != nil {
+		logger.Error("Could not find shared list", zap.String("db_msg", err.Error()))
+		return models.List{}, err
+	}
+	return list, nil
+}
+
+// SetShareToken replaces the list's share token; an empty token revokes sharing.
+func (r *ListsRepository) SetShareToken(c context.Context, id int, token string) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "update lists set share_token = nullif($1, '') where id = $2", token, id)
+	if err != nil {
+		logger.Error("Could not set list share token", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (r *ListsRepository) Create(c context.Context, list models.List) (int, error) {
+	logger := logger.GetLogger()
+	var id int
+	err := r.db.QueryRow(c,
+		"insert into lists(profile_id, name, is_public) values($1, $2, $3) returning id",
+		list.ProfileId, list.Name, list.IsPublic).Scan(&id)
+	if err != nil {
+		logger.Error("Could not insert list", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *ListsRepository) Update(c context.Context, id int, list models.List) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "update lists set name = $1, is_public = $2 where id = $3", list.Name, list.IsPublic, id)
+	if err != nil {
+		logger.Error("Could not update list", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (r *ListsRepository) Delete(c context.Context, id int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from lists where id = $1", id)
+	if err != nil {
+		logger.Error("Could not delete list", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// AddMovie inserts the movie at position (1-based), shifting the following
+// movies down. A position of 0 or past the end appends the movie. It reports
+// false without changing anything when the movie is already in the list.
+func (r *ListsRepository) AddMovie(c context.Context, id int, movieId int, note string, position int) (bool, error) {
+	return addListMovie(c, r.db, id, movieId, note, position)
+}
+
+// UpdateEntry changes the note, priority or reminder of a list movie. It
+// reports false when the movie is not in the list.
+func (r *ListsRepository) UpdateEntry(c context.Context, id int, movieId int, update models.ListEntryUpdate) (bool, error) {
+	return updateListEntry(c, r.db, id, movieId, update)
+}
+
+// MoveMovie moves the movie to position (1-based, clamped to the list
+// length), shifting the movies in between. It reports false when the movie is
+// not in the list.
+func (r *ListsRepository) MoveMovie(c context.Context, id int, movieId int, position int) (bool, error) {
+	return moveListMovie(c, r.db, id, movieId, position)
+}
+
+func (r *ListsRepository) RemoveMovie(c context.Context, id int, movieId int) error {
+	return removeListMovie(c, r.db, id, movieId)
+}
+
+// defaultListId returns the id of the profile's default list, creating it
+// when the profile has none yet.
+func defaultListId(c context.Context, db *pgxpool.Pool, profileId int) (int, error) {
+	logger := logger.GetLogger()
+	_, err := db.Exec(c,
+		`
+insert into lists(profile_id, name, is_default) values($1, $2, true)
+on conflict (profile_id) where is_default do nothing
+	`,
+		profileId, models.DefaultListName)
+	if err != nil {
+		logger.Error("Could not create default list", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+
+	var id int
+	err = db.QueryRow(c, "select id from lists where profile_id = $1 and is_default", profileId).Scan(&id)
+	if err != nil {
+		logger.Error("Could not find default list", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return id, nil
+}
+
+func addListMovie(c context.Context, db *pgxpool.Pool, id int, movieId int, note string, position int) (bool, error) {
+	logger := logger.GetLogger()
+	tx, err := db.Begin(c)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(c)
+
+	// Serializes concurrent additions so that positions stay unique.
+	_, err = tx.Exec(c, "select 1 from lists where id = $1 for update", id)
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	var exists bool
+	var count int
+	err = tx.QueryRow(c,
+		"select coalesce(bool_or(movie_id = $2), false), count(*) from list_movies where list_id = $1",
+		id, movieId).Scan(&exists, &count)
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+	if position <= 0 || position > count+1 {
+		position = count + 1
+	}
+
+	_, err = tx.Exec(c, "update list_movies set position = position + 1 where list_id = $1 and position >= $2", id, position)
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+	_, err = tx.Exec(c,
+		"insert into list_movies(list_id, movie_id, note, position) values($1, $2, $3, $4)",
+		id, movieId, note, position)
+	if err != nil {
+		logger.Error("Could not add list movie", zap.String("db_msg", err.Error()))
+		return false, err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+	return true, nil
+}
+
+func removeListMovie(c context.Context, db *pgxpool.Pool, id int, movieId int) error {
+	logger := logger.GetLogger()
+	tx, err := db.Begin(c)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(c)
+
+	err = deleteListMovie(c, tx, id, movieId)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+// removeWatchedFromWatchlist takes the movie off the profile's default list
+// if the profile has auto removal of watched movies enabled.
+func removeWatchedFromWatchlist(c context.Context, tx pgx.Tx, profileId int, movieId int) error {
+	logger := logger.GetLogger()
+	var listId int
+	err := tx.QueryRow(c,
+		`
+select l.id
+from lists l
+join profiles p on p.id = l.profile_id
+join list_movies lm on lm.list_id = l.id
+where l.profile_id = $1 and l.is_default and p.auto_remove_watched and lm.movie_id = $2
+for update of l
+	`,
+		profileId, movieId).Scan(&listId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		logger.Error("Could not find watchlist", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return deleteListMovie(c, tx, listId, movieId)
+}
+
+// deleteListMovie removes the movie from the list and closes the gap in positions.
+func deleteListMovie(c context.Context, tx pgx.Tx, id int, movieId int) error {
+	logger := logger.GetLogger()
+	_, err := tx.Exec(c, "delete from list_movies where list_id = $1 and movie_id = $2", id, movieId)
+	if err != nil {
+		logger.Error("Could not remove list movie", zap.String("db_msg", err.Error()))
+		return err
+	}
+	_, err = tx.Exec(c,
+		`
+update list_movies lm
+set position = o.position
+from (
+	select movie_id, row_number() over (order by position) as position
+	from list_movies
+	where list_id = $1
+) o
+where lm.list_id = $1 and lm.movie_id = o.movie_id
+	`,
+		id)
+	if err != nil {
+		logger.Error("Could not renumber list", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// moveListMovie moves the movie to position (1-based, clamped to the list
+// length), shifting the movies in between. It reports false when the movie is
+// not in the list.
+func moveListMovie(c context.Context, db *pgxpool.Pool, id int, movieId int, position int) (bool, error) {
+	logger := logger.GetLogger()
+	tx, err := db.Begin(c)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c, "select 1 from lists where id = $1 for update", id)
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	var current, count int
+	err = tx.QueryRow(c,
+		`
+select lm.position, (select count(*) from list_movies where list_id = $1)
+from list_movies lm
+where lm.list_id = $1 and lm.movie_id = $2
+	`,
+		id, movieId).Scan(&current, &count)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+	if position < 1 {
+		position = 1
+	}
+	if position > count {
+		position = count
+	}
+
+	if position < current {
+		_, err = tx.Exec(c, "update list_movies set position = position + 1 where list_id = $1 and position >= $2 and position < $3", id, position, current)
+	} else {
+		_, err = tx.Exec(c, "update list_movies set position = position - 1 where list_id = $1 and position > $2 and position <= $3", id, current, position)
+	}
+	if err != nil {
+		logger.Error("Could not shift list movies", zap.String("db_msg", err.Error()))
+		return false, err
+	}
+	_, err = tx.Exec(c, "update list_movies set position = $1 where list_id = $2 and movie_id = $3", position, id, movieId)
+	if err != nil {
+		logger.Error("Could not move list movie", zap.String("db_msg", err.Error()))
+		return false, err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+	return true, nil
+}
+
+func updateListEntry(c context.Context, db *pgxpool.Pool, id int, movieId int, update models.ListEntryUpdate) (bool, error) {
+	logger := logger.GetLogger()
+	tag, err := db.Exec(c,
+		`
+update list_movies
+set note = coalesce(@note, note),
+    priority = coalesce(@priority, priority),
+    remind_at = case when @clearReminder then null else coalesce(@remindAt::date, remind_at) end
+where list_id = @id and movie_id = @movieId
+	`,
+		pgx.NamedArgs{
+			"id":            id,
+			"movieId":       movieId,
+			"note":          update.Note,
+			"priority":      update.Priority,
+			"remindAt":      update.RemindAt,
+			"clearReminder": update.ClearReminder,
+		})
+	if err != nil {
+		logger.Error("Could not update list movie", zap.String("db_msg", err.Error()))
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+const listEntryColumns = "movie_id, note, position, priority, remind_at, added_at"
+
+func scanListEntries(rows pgx.Rows) ([]models.ListEntry, error) {
+	logger := logger.GetLogger()
+	defer rows.Close()
+
+	entries := make([]models.ListEntry, 0)
+	for rows.Next() {
+		var entry models.ListEntry
+		err := rows.Scan(&entry.MovieId, &entry.Note, &entry.Position, &entry.Priority, &entry.RemindAt, &entry.AddedAt)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
+	"goozinshe/events"
 	"goozinshe/logger"
 	"goozinshe/models"
 	"strconv"
@@ -20,30 +21,45 @@ func NewMoviesRepository(conn *pgxpool.Pool) *MoviesRepository {
 	return &MoviesRepository{db: conn}
 }
 
-func (r *MoviesRepository) FindById(c context.Context, id int) (models.Movie, error) {
+func (r *MoviesRepository) FindById(c context.Context, id int, viewer models.Viewer) (models.Movie, error) {
 	sql :=
 		`
 select 
 m.id,
-m.title,
-m.description,
+coalesce(nullif(mt.title, ''), m.title),
+coalesce(nullif(mt.description, ''), m.description),
 m.release_year,
+m.age_rating,
+m.runtime_minutes,
 m.director,
-m.rating,
-m.is_watched,
+coalesce(pm.rating, 0),
+coalesce(pm.is_watched, false),
 m.trailer_url,
 m.poster_url,
 g.id,
-g.title
+coalesce(nullif(gt.title, ''), g.title)
 from movies m
 join movies_genres mg on mg.movie_id = m.id
 join genres g on mg.genre_id  = g.id
-where m.id = $1
+left join profile_movies pm on pm.movie_id = m.id and pm.profile_id = $4
+left join lateral (
+	select t.title, t.description from movie_translations t
+	where t.movie_id = m.id and t.language = any($2::text[])
+	order by array_position($2::text[], t.language)
+	limit 1
+) mt on true
+left join lateral (
+	select t.title from genre_translations t
+	where t.genre_id = g.id and t.language = any($2::text[])
+	order by array_position($2::text[], t.language)
+	limit 1
+) gt on true
+where m.id = $1 and m.age_rating <= $3
 	`
 
 	logger := logger.GetLogger()
 
-	rows, err := r.db.Query(c, sql, id)
+	rows, err := r.db.Query(c, sql, id, models.LanguageFallbacks(viewer.Language), viewer.MaxAgeRating, viewer.ProfileId)
 	defer rows.Close()
 	if err != nil {
 		logger.Error("Could not query database", zap.String("db_msg", err.Error()))
@@ -61,6 +77,8 @@ where m.id = $1
 			&m.Title,
 			&m.Description,
 			&m.ReleaseYear,
+			&m.AgeRating,
+			&m.RuntimeMinutes,
 			&m.Director,
 			&m.Rating,
 			&m.IsWatched,
@@ -87,47 +105,124 @@ where m.id = $1
 		logger.Error(err.Error())
 		return models.Movie{}, err
 	}
+	if movie == nil {
+		return models.Movie{}, pgx.ErrNoRows
+	}
+
+	tags, err := findTagsByMovieIds(c, r.db, []int{movie.Id})
+	if err != nil {
+		return models.Movie{}, err
+	}
+	movie.Tags = tags[movie.Id]
+
+	movie.Collections, err = findCollectionsByMovieId(c, r.db, movie.Id)
+	if err != nil {
+		return models.Movie{}, err
+	}
+
 	return *movie, nil
 }
 
-func (r *MoviesRepository) FindAll(c context.Context, filters models.MovieFilters) ([]models.Movie, error) {
+// Exists is a cheap visibility check for endpoints that do not need the movie itself.
+func (r *MoviesRepository) Exists(c context.Context, id int, viewer models.Viewer) (bool, error) {
+	logger := logger.GetLogger()
+	var exists bool
+	err := r.db.QueryRow(c, "select exists(select 1 from movies where id = $1 and age_rating <= $2)", id, viewer.MaxAgeRating).Scan(&exists)
+	if err != nil {
+		logger.Error("Could not query database", zap.String("db_msg", err.Error()))
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *MoviesRepository) FindAll(c context.Context, filters models.MovieFilters, viewer models.Viewer) ([]models.Movie, error) {
+	return r.findAll(c, filters, viewer, nil)
+}
+
+// FindAllByIds returns the movies with the given ids that are visible to the viewer, in no particular order.
+func (r *MoviesRepository) FindAllByIds(c context.Context, ids []int, viewer models.Viewer) ([]models.Movie, error) {
+	if len(ids) == 0 {
+		return make([]models.Movie, 0), nil
+	}
+	return r.findAll(c, models.MovieFilters{}, viewer, ids)
+}
+
+func (r *MoviesRepository) findAll(c context.Context, filters models.MovieFilters, viewer models.Viewer, ids []int) ([]models.Movie, error) {
 	sql :=
 		`
 select 
 m.id,
-m.title,
-m.description,
+coalesce(nullif(mt.title, ''), m.title),
+coalesce(nullif(mt.description, ''), m.description),
 m.release_year,
+m.age_rating,
+m.runtime_minutes,
 m.director,
-m.rating,
-m.is_watched,
+coalesce(pm.rating, 0),
+coalesce(pm.is_watched, false),
 m.trailer_url,
 m.poster_url,
 g.id,
-g.title
+coalesce(nullif(gt.title, ''), g.title)
 from movies m
 join movies_genres mg on mg.movie_id = m.id
 join genres g on mg.genre_id  = g.id
-where 1 = 1
+left join profile_movies pm on pm.movie_id = m.id and pm.profile_id = @profileId
+left join lateral (
+	select t.title, t.description from movie_translations t
+	where t.movie_id = m.id and t.language = any(@langs::text[])
+	order by array_position(@langs::text[], t.language)
+	limit 1
+) mt on true
+left join lateral (
+	select t.title from genre_translations t
+	where t.genre_id = g.id and t.language = any(@langs::text[])
+	order by array_position(@langs::text[], t.language)
+	limit 1
+) gt on true
+where m.age_rating <= @maxAgeRating
 `
 
 	logger := logger.GetLogger()
 
-	params := pgx.NamedArgs{}
+	params := pgx.NamedArgs{
+		"langs":        models.LanguageFallbacks(viewer.Language),
+		"maxAgeRating": viewer.MaxAgeRating,
+		"profileId":    viewer.ProfileId,
+	}
+	if ids != nil {
+		sql = fmt.Sprintf("%s and m.id = any(@ids)", sql)
+		params["ids"] = ids
+	}
 	if filters.SearchTerm != "" {
-		sql = fmt.Sprintf("%s and m.title ilike @s", sql)
+		sql = fmt.Sprintf(`%s and (
+	m.title ilike @s
+	or exists (select 1 from movie_translations st where st.movie_id = m.id and st.title ilike @s)
+	or exists (select 1 from movies_tags smt join tags stg on stg.id = smt.tag_id where smt.movie_id = m.id and stg.name ilike @s)
+)`, sql)
 		params["s"] = fmt.Sprintf("%%%s%%", filters.SearchTerm)
 	}
 	if filters.IsWatched != "" {
 		isWatched, _ := strconv.ParseBool(filters.IsWatched)
-		sql = fmt.Sprintf("%s and m.is_watched = @isWatched", sql)
+		sql = fmt.Sprintf("%s and coalesce(pm.is_watched, false) = @isWatched", sql)
 		params["isWatched"] = isWatched
 	}
 	if filters.GenreId != "" {
 		sql = fmt.Sprintf("%s and g.id = @genreId", sql)
 		params["genreId"] = filters.GenreId
 	}
-	if filters.Sort != "" {
+	if len(filters.TagIds) > 0 {
+		sql = fmt.Sprintf("%s and (select count(distinct ftm.tag_id) from movies_tags ftm where ftm.movie_id = m.id and ftm.tag_id = any(@tagIds)) = @tagCount", sql)
+		params["tagIds"] = filters.TagIds
+		params["tagCount"] = len(filters.TagIds)
+	}
+	switch filters.Sort {
+	case "":
+	case "rating":
+		sql = fmt.Sprintf("%s order by coalesce(pm.rating, 0)", sql)
+	case "is_watched":
+		sql = fmt.Sprintf("%s order by coalesce(pm.is_watched, false)", sql)
+	default:
 		identifier := pgx.Identifier{filters.Sort}.Sanitize()
 		sql = fmt.Sprintf("%s order by m.%s", sql, identifier)
 	}
@@ -149,6 +244,8 @@ where 1 = 1
 			&m.Title,
 			&m.Description,
 			&m.ReleaseYear,
+			&m.AgeRating,
+			&m.RuntimeMinutes,
 			&m.Director,
 			&m.Rating,
 			&m.IsWatched,
@@ -175,8 +272,18 @@ where 1 = 1
 		return nil, err
 	}
 
+	movieIds := make([]int, 0, len(movies))
+	for _, v := range movies {
+		movieIds = append(movieIds, v.Id)
+	}
+	tags, err := findTagsByMovieIds(c, r.db, movieIds)
+	if err != nil {
+		return nil, err
+	}
+
 	concreteMovies := make([]models.Movie, 0, len(movies))
 	for _, v := range movies {
+		v.Tags = tags[v.Id]
 		concreteMovies = append(concreteMovies, *v)
 	}
 
@@ -190,16 +297,19 @@ func (r *MoviesRepository) Create(c context.Context, movie models.Movie) (int, e
 	if err != nil {
 		return 0, nil
 	}
+	defer tx.Rollback(c)
 
 	row := tx.QueryRow(c,
 		`
-insert into movies(title, description, release_year, director, trailer_url, poster_url)
-values($1, $2, $3, $4, $5, $6)
+insert into movies(title, description, release_year, age_rating, runtime_minutes, director, trailer_url, poster_url)
+values($1, $2, $3, $4, $5, $6, $7, $8)
 returning id
 	`,
 		movie.Title,
 		movie.Description,
 		movie.ReleaseYear,
+		movie.AgeRating,
+		movie.RuntimeMinutes,
 		movie.Director,
 		movie.TrailerUrl,
 		movie.PosterUrl,
@@ -219,6 +329,18 @@ returning id
 			return 0, err
 		}
 	}
+	for _, tag := range movie.Tags {
+		_, err = tx.Exec(c, "insert into movies_tags(movie_id, tag_id) values($1, $2)", id, tag.Id)
+		if err != nil {
+			logger.Error(err.Error())
+			return 0, err
+		}
+	}
+
+	err = addOutboxEvent(c, tx, events.MovieCreated, map[string]any{"id": id})
+	if err != nil {
+		return 0, err
+	}
 
 	err = tx.Commit(c)
 	if err != nil {
@@ -234,6 +356,15 @@ func (r *MoviesRepository) Update(c context.Context, id int, updatedMovie models
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(c)
+	logger := logger.GetLogger()
+
+	var previousTrailerUrl string
+	err = tx.QueryRow(c, "select trailer_url from movies where id = $1 for update", id).Scan(&previousTrailerUrl)
+	if err != nil {
+		logger.Error("Could not find movie", zap.String("db_msg", err.Error()))
+		return err
+	}
 
 	_, err = tx.Exec(
 		c,
@@ -243,19 +374,22 @@ set
 title = $1,
 description = $2,
 release_year = $3,
-director = $4,
-trailer_url = $5,
-poster_url = $6
-where id = $7
+age_rating = $4,
+runtime_minutes = $5,
+director = $6,
+trailer_url = $7,
+poster_url = $8
+where id = $9
 	`,
 		updatedMovie.Title,
 		updatedMovie.Description,
 		updatedMovie.ReleaseYear,
+		updatedMovie.AgeRating,
+		updatedMovie.RuntimeMinutes,
 		updatedMovie.Director,
 		updatedMovie.TrailerUrl,
 		updatedMovie.PosterUrl,
 		id)
-	logger := logger.GetLogger()
 	if err != nil {
 		logger.Error("Could not update database", zap.String("db_msg", err.Error()))
 		return err
@@ -274,6 +408,30 @@ where id = $7
 		}
 	}
 
+	_, err = tx.Exec(c, "delete from movies_tags where movie_id = $1", id)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	for _, tag := range updatedMovie.Tags {
+		_, err = tx.Exec(c, "insert into movies_tags(movie_id, tag_id) values($1, $2)", id, tag.Id)
+		if err != nil {
+			logger.Error(err.Error())
+			return err
+		}
+	}
+
+	err = addOutboxEvent(c, tx, events.MovieUpdated, map[string]any{"id": id})
+	if err != nil {
+		return err
+	}
+	if previousTrailerUrl == "" && updatedMovie.TrailerUrl != "" {
+		err = addOutboxEvent(c, tx, events.TrailerAdded, map[string]any{"id": id})
+		if err != nil {
+			return err
+		}
+	}
+
 	err = tx.Commit(c)
 	if err != nil {
 		logger.Error(err.Error())
@@ -288,6 +446,7 @@ func (r *MoviesRepository) Delete(c context.Context, id int) error {
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(c)
 	logger := logger.GetLogger()
 	_, err = tx.Exec(c, "delete from movies_genres where movie_id = $1", id)
 	if err != nil {
@@ -301,6 +460,11 @@ func (r *MoviesRepository) Delete(c context.Context, id int) error {
 		return err
 	}
 
+	err = addOutboxEvent(c, tx, events.MovieDeleted, map[string]any{"id": id})
+	if err != nil {
+		return err
+	}
+
 	err = tx.Commit(c)
 	if err != nil {
 		logger.Error(err.Error())
@@ -310,9 +474,16 @@ func (r *MoviesRepository) Delete(c context.Context, id int) error {
 	return nil
 }
 
-func (r *MoviesRepository) SetRating(c context.Context, id int, rating int) error {
+func (r *MoviesRepository) SetRating(c context.Context, profileId int, id int, rating int) error {
 	logger := logger.GetLogger()
-	_, err := r.db.Exec(c, "update movies set rating = $1 where id = $2", rating, id)
+	_, err := r.db.Exec(c,
+		`
+insert into profile_movies(profile_id, movie_id, rating, rated_at)
+values($1, $2, $3, now())
+on conflict (profile_id, movie_id) do update
+set rating = excluded.rating, rated_at = excluded.rated_at
+	`,
+		profileId, id, rating)
 	if err != nil {
 		logger.Error("Could not set rating", zap.String("db_msg", err.Error()))
 		return err
@@ -321,13 +492,66 @@ func (r *MoviesRepository) SetRating(c context.Context, id int, rating int) erro
 	return nil
 }
 
-func (r *MoviesRepository) SetWatched(c context.Context, id int, isWatched bool) error {
+// SetWatched marks the movie (un)watched for the profile. A movie that becomes
+// watched is logged in the watch history and, when the profile asked for it,
+// taken off its watchlist.
+func (r *MoviesRepository) SetWatched(c context.Context, profileId int, id int, isWatched bool) error {
 	logger := logger.GetLogger()
-	_, err := r.db.Exec(c, "update movies set is_watched = $1 where id = $2", isWatched, id)
+	tx, err := r.db.Begin(c)
 	if err != nil {
-		logger.Error("Could not set isWatched", zap.String("db_msg", err.Error()))
+		return err
+	}
+	defer tx.Rollback(c)
+
+	becameWatched, err := setWatched(c, tx, profileId, id, isWatched)
+	if err != nil {
+		return err
+	}
+	if becameWatched {
+		_, err = tx.Exec(c, "insert into watch_events(profile_id, movie_id, watched_at) values($1, $2, now())", profileId, id)
+		if err != nil {
+			logger.Error("Could not record watch event", zap.String("db_msg", err.Error()))
+			return err
+		}
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
 		return err
 	}
 
 	return nil
 }
+
+// setWatched stores the watched flag and, when the movie becomes watched,
+// takes it off the watchlist of a profile that asked for it. It reports
+// whether the movie became watched.
+func setWatched(c context.Context, tx pgx.Tx, profileId int, id int, isWatched bool) (bool, error) {
+	logger := logger.GetLogger()
+	var becameWatched bool
+	err := tx.QueryRow(c,
+		`
+with previous as (
+	select is_watched from profile_movies where profile_id = $1 and movie_id = $2
+)
+insert into profile_movies(profile_id, movie_id, is_watched)
+values($1, $2, $3)
+on conflict (profile_id, movie_id) do update
+set is_watched = excluded.is_watched
+returning is_watched and not coalesce((select is_watched from previous), false)
+	`,
+		profileId, id, isWatched).Scan(&becameWatched)
+	if err != nil {
+		logger.Error("Could not set isWatched", zap.String("db_msg", err.Error()))
+		return false, err
+	}
+
+	if becameWatched {
+		err = removeWatchedFromWatchlist(c, tx, profileId, id)
+		if err != nil {
+			return false, err
+		}
+	}
+	return becameWatched, nil
+}
@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"time"
+)
+
+type NotificationsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationsRepository(conn *pgxpool.Pool) *NotificationsRepository {
+	return &NotificationsRepository{db: conn}
+}
+
+// AddTrailerAdded notifies the profiles that have the movie on their
+// watchlist and may watch it. It returns the number of notified profiles;
+// a repeated event notifies nobody.
+func (r *NotificationsRepository) AddTrailerAdded(c context.Context, eventId string, movieId int) (int64, error) {
+	logger := logger.GetLogger()
+	tag, err := r.db.Exec(c, `
+insert into notifications(profile_id, type, movie_id, event_id)
+select distinct l.profile_id, $1::text, m.id, $2::uuid
+from movies m
+join list_movies lm on lm.movie_id = m.id
+join lists l on l.id = lm.list_id and l.is_default
+join profiles p on p.id = l.profile_id
+where m.id = $3 and p.max_age_rating >= m.age_rating
+on conflict (profile_id, event_id) do nothing
+	`, models.NotificationTrailerAdded, eventId, movieId)
+	if err != nil {
+		logger.Error("Could not add trailer notifications", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// AddReviewApproved notifies the author of the review, if it is still
+// approved.
+func (r *NotificationsRepository) AddReviewApproved(c context.Context, eventId string, reviewId int) (int64, error) {
+	logger := logger.GetLogger()
+	tag, err := r.db.Exec(c, `
+insert into notifications(profile_id, type, movie_id, review_id, event_id)
+select rv.profile_id, $1::text, rv.movie_id, rv.id, $2::uuid
+from reviews rv
+where rv.id = $3 and rv.status = $4
+on conflict (profile_id, event_id) do nothing
+	`, models.NotificationReviewApproved, eventId, reviewId, models.ReviewStatusApproved)
+	if err != nil {
+		logger.Error("Could not add review notification", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// AddNewRelease notifies the profiles that follow one of the movie's genres
+// and may watch it.
+func (r *NotificationsRepository) AddNewRelease(c context.Context, eventId string, movieId int) (int64, error) {
+	logger := logger.GetLogger()
+	tag, err := r.db.Exec(c, `
+insert into notifications(profile_id, type, movie_id, event_id)
+select distinct f.profile_id, $1::text, m.id, $2::uuid
+from movies m
+join movies_genres mg on mg.movie_id = m.id
+join genre_follows f on f.genre_id = mg.genre_id
+join profiles p on p.id = f.profile_id
+where m.id = $3 and p.max_age_rating >= m.age_rating
+on conflict (profile_id, event_id) do nothing
+	`, models.NotificationNewRelease, eventId, movieId)
+	if err != nil {
+		logger.Error("Could not add new release notifications", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// FindAll returns a page of the profile's notifications, newest first.
+// Notifications about movies the viewer may no longer see are left out.
+func (r *NotificationsRepository) FindAll(c context.Context, viewer models.Viewer, unreadOnly bool, limit int, offset int) ([]models.Notification, int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, `
+select n.id, n.type, n.movie_id, n.review_id, n.read_at is not null, n.created_at, count(*) over ()
+from notifications n
+join movies m on m.id = n.movie_id
+where n.profile_id = @profileId
+  and m.age_rating <= @maxAgeRating
+  and (not @unreadOnly::boolean or n.read_at is null)
+order by n.created_at desc, n.id desc
+limit @limit offset @offset
+	`, pgx.NamedArgs{
+		"profileId":    viewer.ProfileId,
+		"maxAgeRating": viewer.MaxAgeRating,
+		"unreadOnly":   unreadOnly,
+		"limit":        limit,
+		"offset":       offset,
+	})
+	if err != nil {
+		logger.Error("Could not find notifications", zap.String("db_msg", err.Error()))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	total := 0
+	notifications := make([]models.Notification, 0)
+	for rows.Next() {
+		var notification models.Notification
+		err = rows.Scan(&notification.Id, &notification.Type, &notification.MovieId, &notification.ReviewId,
+			&notification.IsRead, &notification.CreatedAt, &total)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, 0, err
+		}
+		notifications = append(notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, 0, err
+	}
+
+	return notifications, total, nil
+}
+
+func (r *NotificationsRepository) CountUnread(c context.Context, viewer models.Viewer) (int, error) {
+	logger := logger.GetLogger()
+	var count int
+	err := r.db.QueryRow(c, `
+select count(*)
+from notifications n
+join movies m on m.id = n.movie_id
+where n.profile_id = $1 and n.read_at is null and m.age_rating <= $2
+	`, viewer.ProfileId, viewer.MaxAgeRating).Scan(&count)
+	if err != nil {
+		logger.Error("Could not count unread notifications", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkRead marks the profile's notification as read. It reports false when
+// the profile has no such notification.
+func (r *NotificationsRepository) MarkRead(c context.Context, id int64, profileId int) (bool, error) {
+	logger := logger.GetLogger()
+	tag, err := r.db.Exec(c,
+		"update notifications set read_at = coalesce(read_at, now()) where id = $1 and profile_id = $2",
+		id, profileId)
+	if err != nil {
+		logger.Error("Could not mark notification as read", zap.String("db_msg", err.Error()))
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *NotificationsRepository) MarkAllRead(c context.Context, profileId int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "update notifications set read_at = now() where profile_id = $1 and read_at is null", profileId)
+	if err != nil {
+		logger.Error("Could not mark notifications as read", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// DeleteOlderThan removes notifications created before the given time, read
+// or not.
+func (r *NotificationsRepository) DeleteOlderThan(c context.Context, before time.Time) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from notifications where created_at < $1", before)
+	if err != nil {
+		logger.Error("Could not delete old notifications", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"time"
+)
+
+type OutboxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepository(conn *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{db: conn}
+}
+
+// addOutboxEvent saves the event in tx, so that it is published if and only
+// if the change it describes is committed.
+func addOutboxEvent(c context.Context, tx pgx.Tx, eventType string, data any) error {
+	logger := logger.GetLogger()
+	payload, err := json.Marshal(data)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+
+	_, err = tx.Exec(c, "insert into outbox_events(event_id, type, payload) values($1::uuid, $2, $3::jsonb)",
+		uuid.NewString(), eventType, payload)
+	if err != nil {
+		logger.Error("Could not insert outbox event", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// ProcessPending hands up to limit unpublished events to publish, oldest
+// first, and marks the ones it accepted as published. It stops at the first
+// event publish fails on, so that the events keep their order, and records
+// the error on it. An event that failed maxAttempts times is moved to the
+// dead letters instead and the batch goes on. The events stay locked until
+// the batch is done, so several relays can run side by side; an event is
+// published again only if the relay dies before marking it.
+func (r *OutboxRepository) ProcessPending(c context.Context, limit int, maxAttempts int, publish func(event models.OutboxEvent) error) (int, error) {
+	logger := logger.GetLogger()
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return 0, err
+	}
+	defer tx.Rollback(c)
+
+	rows, err := tx.Query(c, `
+select id, event_id::text, type, payload, attempts, last_error, created_at, dead_at
+from outbox_events
+where published_at is null and dead_at is null
+order by id
+limit $1
+for update skip locked
+	`, limit)
+	if err != nil {
+		logger.Error("Could not find outbox events", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	pending := make([]models.OutboxEvent, 0)
+	for rows.Next() {
+		event, err := scanOutboxEvent(rows)
+		if err != nil {
+			rows.Close()
+			logger.Error(err.Error())
+			return 0, err
+		}
+		pending = append(pending, event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return 0, err
+	}
+
+	published, failures := publishOutboxBatch(pending, maxAttempts, publish)
+	for _, failure := range failures {
+		_, err = tx.Exec(c, `
+update outbox_events
+set attempts = attempts + 1, last_error = $1, dead_at = case when $2::boolean then now() end
+where id = $3
+		`, failure.err.Error(), failure.dead, failure.event.Id)
+		if err != nil {
+			logger.Error("Could not record outbox event failure", zap.String("db_msg", err.Error()))
+			return 0, err
+		}
+		if failure.dead {
+			logger.Error("Outbox event moved to dead letters",
+				zap.Int64("event_id", failure.event.Id), zap.String("type", failure.event.Type), zap.String("error", failure.err.Error()))
+		}
+	}
+
+	_, err = tx.Exec(c, "update outbox_events set published_at = now() where id = any($1)", published)
+	if err != nil {
+		logger.Error("Could not mark outbox events as published", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return 0, err
+	}
+	return len(published), nil
+}
+
+type outboxFailure struct {
+	event models.OutboxEvent
+	err   error
+	// dead is set when the event has run out of attempts.
+	dead bool
+}
+
+// publishOutboxBatch publishes the events in order and returns the ids of
+// the published ones. It stops at the first failure, so that events stay in
+// order, unless the failed event has run out of attempts and goes to the
+// dead letters instead of holding the rest back.
+func publishOutboxBatch(pending []models.OutboxEvent, maxAttempts int, publish func(event models.OutboxEvent) error) ([]int64, []outboxFailure) {
+	published := make([]int64, 0, len(pending))
+	failures := make([]outboxFailure, 0)
+	for _, event := range pending {
+		err := publish(event)
+		if err == nil {
+			published = append(published, event.Id)
+			continue
+		}
+
+		dead := event.Attempts+1 >= maxAttempts
+		failures = append(failures, outboxFailure{event: event, err: err, dead: dead})
+		if !dead {
+			break
+		}
+	}
+	return published, failures
+}
+
+// FindDead returns a page of the events the relay gave up on, the most
+// recently failed first.
+func (r *OutboxRepository) FindDead(c context.Context, limit int, offset int) ([]models.OutboxEvent, int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, `
+select id, event_id::text, type, payload, attempts, last_error, created_at, dead_at, count(*) over ()
+from outbox_events
+where dead_at is not null
+order by dead_at desc, id desc
+limit $1 offset $2
+	`, limit, offset)
+	if err != nil {
+		logger.Error("Could not find dead outbox events", zap.String("db_msg", err.Error()))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	total := 0
+	events := make([]models.OutboxEvent, 0)
+	for rows.Next() {
+		event, err := scanOutboxEvent(rows, &total)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, 0, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// Retry hands a dead event back to the relay with a fresh set of attempts.
+// It is published after the events that are pending by then. It reports
+// false when there is no such dead event.
+func (r *OutboxRepository) Retry(c context.Context, id int64) (bool, error) {
+	logger := logger.GetLogger()
+	tag, err := r.db.Exec(c,
+		"update outbox_events set dead_at = null, attempts = 0 where id = $1 and dead_at is not null", id)
+	if err != nil {
+		logger.Error("Could not retry outbox event", zap.Int64("event_id", id), zap.String("db_msg", err.Error()))
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func scanOutboxEvent(row pgx.Row, extra ...any) (models.OutboxEvent, error) {
+	var event models.OutboxEvent
+	dest := []any{&event.Id, &event.EventId, &event.Type, &event.Payload, &event.Attempts, &event.LastError,
+		&event.CreatedAt, &event.DeadAt}
+	err := row.Scan(append(dest, extra...)...)
+	return event, err
+}
+
+// DeletePublished removes events published before the given time.
+func (r *OutboxRepository) DeletePublished(c context.Context, before time.Time) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from outbox_events where published_at < $1", before)
+	if err != nil {
+		logger.Error("Could not delete published outbox events", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
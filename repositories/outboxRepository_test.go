@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"errors"
+	"goozinshe/models"
+	"reflect"
+	"testing"
+)
+
+func TestPublishOutboxBatch(t *testing.T) {
+	const maxAttempts = 3
+	failing := map[int64]bool{2: true, 4: true}
+	publish := func(event models.OutboxEvent) error {
+		if failing[event.Id] {
+			return errors.New("sink is down")
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name          string
+		pending       []models.OutboxEvent
+		wantPublished []int64
+		wantFailed    []int64
+		wantDead      []bool
+	}{
+		{
+			name:          "all published",
+			pending:       []models.OutboxEvent{{Id: 1}, {Id: 3}},
+			wantPublished: []int64{1, 3},
+		},
+		{
+			name:          "failure holds back the rest",
+			pending:       []models.OutboxEvent{{Id: 1}, {Id: 2, Attempts: 1}, {Id: 3}},
+			wantPublished: []int64{1},
+			wantFailed:    []int64{2},
+			wantDead:      []bool{false},
+		},
+		{
+			name:          "last attempt goes to dead letters",
+			pending:       []models.OutboxEvent{{Id: 1}, {Id: 2, Attempts: 2}, {Id: 3}, {Id: 4}, {Id: 5}},
+			wantPublished: []int64{1, 3},
+			wantFailed:    []int64{2, 4},
+			wantDead:      []bool{true, false},
+		},
+	}
+	for _, tt := range tests {
+		published, failures := publishOutboxBatch(tt.pending, maxAttempts, publish)
+		if !reflect.DeepEqual(published, append([]int64{}, tt.wantPublished...)) {
+			t.Errorf("%s: published = %v, want %v", tt.name, published, tt.wantPublished)
+		}
+		failed := make([]int64, 0)
+		dead := make([]bool, 0)
+		for _, failure := range failures {
+			failed = append(failed, failure.event.Id)
+			dead = append(dead, failure.dead)
+		}
+		if !reflect.DeepEqual(failed, append([]int64{}, tt.wantFailed...)) || !reflect.DeepEqual(dead, append([]bool{}, tt.wantDead...)) {
+			t.Errorf("%s: failed = %v dead = %v, want %v %v", tt.name, failed, dead, tt.wantFailed, tt.wantDead)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"time"
+)
+
+type ProfilesRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewProfilesRepository(conn *pgxpool.Pool) *ProfilesRepository {
+	return &ProfilesRepository{db: conn}
+}
+
+func (r *ProfilesRepository) FindAllByUserId(c context.Context, userId int) ([]models.Profile, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, "select id, user_id, name, avatar_url, is_kids, is_default, max_age_rating, pin_hash, auto_remove_watched from profiles where user_id = $1 order by is_default desc, id", userId)
+	if err != nil {
+		logger.Error("Could not find profiles", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := make([]models.Profile, 0)
+	for rows.Next() {
+		var p models.Profile
+		err = rows.Scan(&p.Id, &p.UserId, &p.Name, &p.AvatarUrl, &p.IsKids, &p.IsDefault, &p.MaxAgeRating, &p.PinHash, &p.AutoRemoveWatched)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+func (r *ProfilesRepository) FindById(c context.Context, id int) (models.Profile, error) {
+	logger := logger.GetLogger()
+	var p models.Profile
+	row := r.db.QueryRow(c, "select id, user_id, name, avatar_url, is_kids, is_default, max_age_rating, pin_hash, auto_remove_watched from profiles where id = $1", id)
+	err := row.Scan(&p.Id, &p.UserId, &p.Name, &p.AvatarUrl, &p.IsKids, &p.IsDefault, &p.MaxAgeRating, &p.PinHash, &p.AutoRemoveWatched)
+	if err != nil {
+		logger.Error("Could not find profile", zap.String("db_msg", err.Error()))
+		return models.Profile{}, err
+	}
+	return p, nil
+}
+
+func (r *ProfilesRepository) FindDefaultByUserId(c context.Context, userId int) (models.Profile, error) {
+	logger := logger.GetLogger()
+	var p models.Profile
+	row := r.db.QueryRow(c, "select id, user_id, name, avatar_url, is_kids, is_default, max_age_rating, pin_hash, auto_remove_watched from profiles where user_id = $1 and is_default", userId)
+	err := row.Scan(&p.Id, &p.UserId, &p.Name, &p.AvatarUrl, &p.IsKids, &p.IsDefault, &p.MaxAgeRating, &p.PinHash, &p.AutoRemoveWatched)
+	if err != nil {
+		logger.Error("Could not find default profile", zap.String("db_msg", err.Error()))
+		return models.Profile{}, err
+	}
+	return p, nil
+}
+
+func (r *ProfilesRepository) Create(c context.Context, profile models.Profile) (int, error) {
+	logger := logger.GetLogger()
+	var id int
+	err := r.db.QueryRow(c,
+		"insert into profiles(user_id, name, is_kids, max_age_rating, pin_hash) values($1, $2, $3, $4, $5) returning id",
+		profile.UserId, profile.Name, profile.IsKids, profile.MaxAgeRating, profile.PinHash).Scan(&id)
+	if err != nil {
+		logger.Error("Could not insert profile", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *ProfilesRepository) Update(c context.Context, id int, profile models.Profile) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c,
+		"update profiles set name = $1, is_kids = $2, max_age_rating = $3, pin_hash = $4, auto_remove_watched = $5 where id = $6",
+		profile.Name, profile.IsKids, profile.MaxAgeRating, profile.PinHash, profile.AutoRemoveWatched, id)
+	if err != nil {
+		logger.Error("Could not update profile", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (r *ProfilesRepository) SetAvatar(c context.Context, id int, avatarUrl string) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "update profiles set avatar_url = $1 where id = $2", avatarUrl, id)
+	if err != nil {
+		logger.Error("Could not set profile avatar", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// PinLockRemaining returns how long the profile stays locked after too many wrong PINs.
+func (r *ProfilesRepository) PinLockRemaining(c context.Context, id int) (time.Duration, error) {
+	logger := logger.GetLogger()
+	var seconds float64
+	err := r.db.QueryRow(c,
+		"select coalesce(greatest(extract(epoch from pin_locked_until - now()), 0), 0)::float8 from profiles where id = $1",
+		id).Scan(&seconds)
+	if err != nil {
+		logger.Error("Could not get profile PIN lock", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// RecordPinFailure counts a wrong PIN and locks the profile once the failures
+// reach models.MaxPinFailures. It returns how long the profile is locked for.
+func (r *ProfilesRepository) RecordPinFailure(c context.Context, id int) (time.Duration, error) {
+	logger := logger.GetLogger()
+	sql := `
+update profiles
+set pin_failures     = case when pin_failures + 1 >= @maxFailures then 0 else pin_failures + 1 end,
+    pin_locked_until = case
+                           when pin_failures + 1 >= @maxFailures then now() + @lockout::float8 * interval '1 second'
+                           else pin_locked_until end
+where id = @id
+returning coalesce(greatest(extract(epoch from pin_locked_until - now()), 0), 0)::float8
+`
+	var seconds float64
+	err := r.db.QueryRow(c, sql, pgx.NamedArgs{
+		"id":          id,
+		"maxFailures": models.MaxPinFailures,
+		"lockout":     models.PinLockout.Seconds(),
+	}).Scan(&seconds)
+	if err != nil {
+		logger.Error("Could not record profile PIN failure", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+func (r *ProfilesRepository) ResetPinFailures(c context.Context, id int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "update profiles set pin_failures = 0, pin_locked_until = null where id = $1", id)
+	if err != nil {
+		logger.Error("Could not reset profile PIN failures", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (r *ProfilesRepository) Delete(c context.Context, id int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from profiles where id = $1", id)
+	if err != nil {
+		logger.Error("Could not delete profile", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
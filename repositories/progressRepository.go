@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+)
+
+type ProgressRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewProgressRepository(conn *pgxpool.Pool) *ProgressRepository {
+	return &ProgressRepository{db: conn}
+}
+
+// Save stores the latest playback position. It reports whether this update
+// is the one that crossed the completion threshold, so repeated heartbeats
+// past the threshold report it only once.
+func (r *ProgressRepository) Save(c context.Context, profileId int, progress models.PlaybackProgress) (bool, error) {
+	logger := logger.GetLogger()
+	var justCompleted bool
+	err := r.db.QueryRow(c,
+		`
+with previous as (
+	select completed from playback_progress where profile_id = $1 and movie_id = $2
+)
+insert into playback_progress(profile_id, movie_id, position_seconds, duration_seconds, completed, updated_at)
+values($1, $2, $3, $4, $5, now())
+on conflict (profile_id, movie_id) do update
+set position_seconds = excluded.position_seconds,
+    duration_seconds = excluded.duration_seconds,
+    completed = excluded.completed,
+    updated_at = excluded.updated_at
+returning completed and not coalesce((select completed from previous), false)
+	`,
+		profileId, progress.MovieId, progress.PositionSeconds, progress.DurationSeconds, progress.Completed).Scan(&justCompleted)
+	if err != nil {
+		logger.Error("Could not save playback progress", zap.String("db_msg", err.Error()))
+		return false, err
+	}
+	return justCompleted, nil
+}
+
+func (r *ProgressRepository) FindByMovieId(c context.Context, profileId int, movieId int) (models.PlaybackProgress, error) {
+	logger := logger.GetLogger()
+	var p models.PlaybackProgress
+	row := r.db.QueryRow(c,
+		"select movie_id, position_seconds, duration_seconds, completed, updated_at from playback_progress where profile_id = $1 and movie_id = $2",
+		profileId, movieId)
+	err := row.Scan(&p.MovieId, &p.PositionSeconds, &p.DurationSeconds, &p.Completed, &p.UpdatedAt)
+	if err != nil {
+		logger.Error("Could not find playback progress", zap.String("db_msg", err.Error()))
+		return models.PlaybackProgress{}, err
+	}
+	return p, nil
+}
+
+// FindUnfinished returns started but not completed movies, most recently watched first.
+func (r *ProgressRepository) FindUnfinished(c context.Context, profileId int, limit int) ([]models.PlaybackProgress, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c,
+		`
+select movie_id, position_seconds, duration_seconds, completed, updated_at
+from playback_progress
+where profile_id = $1 and not completed and position_seconds > 0
+order by updated_at desc
+limit $2
+	`,
+		profileId, limit)
+	if err != nil {
+		logger.Error("Could not find unfinished movies", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := make([]models.PlaybackProgress, 0)
+	for rows.Next() {
+		var p models.PlaybackProgress
+		err = rows.Scan(&p.MovieId, &p.PositionSeconds, &p.DurationSeconds, &p.Completed, &p.UpdatedAt)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		progress = append(progress, p)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return progress, nil
+}
@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+)
+
+// favouriteGenresLimit is how many of the profile's most watched genres are
+// used for cold-start suggestions.
+const favouriteGenresLimit = 3
+
+// favouriteGenresCte ranks the genres of every profile by how many movies of
+// the genre the profile watched or liked; favourite genres are those with
+// position <= favouriteGenresLimit.
+const favouriteGenresCte = `
+genre_weights as (
+	select pm.profile_id, mg.genre_id, count(*) as weight
+	from profile_movies pm
+	join movies_genres mg on mg.movie_id = pm.movie_id
+	where pm.is_watched or pm.rating >= 4
+	group by pm.profile_id, mg.genre_id
+),
+favourite_genres as (
+	select profile_id, genre_id, rank() over (partition by profile_id order by weight desc) as position
+	from genre_weights
+)`
+
+type RecommendationsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRecommendationsRepository(conn *pgxpool.Pool) *RecommendationsRepository {
+	return &RecommendationsRepository{db: conn}
+}
+
+// FindAllRatings returns the whole ratings matrix in sparse form.
+func (r *RecommendationsRepository) FindAllRatings(c context.Context) ([]models.ProfileRating, error) {
+	return r.findRatings(c, "select profile_id, movie_id, rating from profile_movies where rating is not null")
+}
+
+func (r *RecommendationsRepository) FindRatingsByProfileId(c context.Context, profileId int) ([]models.ProfileRating, error) {
+	return r.findRatings(c, "select profile_id, movie_id, rating from profile_movies where rating is not null and profile_id = $1", profileId)
+}
+
+func (r *RecommendationsRepository) findRatings(c context.Context, sql string, args ...any) ([]models.ProfileRating, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, sql, args...)
+	if err != nil {
+		logger.Error("Could not find ratings", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := make([]models.ProfileRating, 0)
+	for rows.Next() {
+		var rating models.ProfileRating
+		err = rows.Scan(&rating.ProfileId, &rating.MovieId, &rating.Rating)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		ratings = append(ratings, rating)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+// ReplaceNeighbours swaps the stored item-item similarities for a freshly
+// computed set. Pairs whose movies were deleted meanwhile are skipped.
+func (r *RecommendationsRepository) ReplaceNeighbours(c context.Context, neighbours []models.MovieNeighbour) error {
+	logger := logger.GetLogger()
+	movieIds := make([]int, 0, len(neighbours))
+	neighbourIds := make([]int, 0, len(neighbours))
+	similarities := make([]float64, 0, len(neighbours))
+	for _, n := range neighbours {
+		movieIds = append(movieIds, n.MovieId)
+		neighbourIds = append(neighbourIds, n.NeighbourId)
+		similarities = append(similarities, n.Similarity)
+	}
+
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c, "delete from movie_neighbours")
+	if err != nil {
+		logger.Error("Could not clear movie neighbours", zap.String("db_msg", err.Error()))
+		return err
+	}
+
+	_, err = tx.Exec(c,
+		`
+insert into movie_neighbours(movie_id, neighbour_id, similarity)
+select u.movie_id, u.neighbour_id, u.similarity
+from unnest($1::int[], $2::int[], $3::float8[]) as u(movie_id, neighbour_id, similarity)
+join movies m on m.id = u.movie_id
+join movies n on n.id = u.neighbour_id
+	`,
+		movieIds, neighbourIds, similarities)
+	if err != nil {
+		logger.Error("Could not save movie neighbours", zap.String("db_msg", err.Error()))
+		return err
+	}
+
+	return tx.Commit(c)
+}
+
+// FindNeighbours returns the stored neighbours of the given movies.
+func (r *RecommendationsRepository) FindNeighbours(c context.Context, movieIds []int) ([]models.MovieNeighbour, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c,
+		"select movie_id, neighbour_id, similarity from movie_neighbours where movie_id = any($1)",
+		movieIds)
+	if err != nil {
+		logger.Error("Could not find movie neighbours", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	neighbours := make([]models.MovieNeighbour, 0)
+	for rows.Next() {
+		var n models.MovieNeighbour
+		err = rows.Scan(&n.MovieId, &n.NeighbourId, &n.Similarity)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		neighbours = append(neighbours, n)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return neighbours, nil
+}
+
+// FindPopularInFavouriteGenres returns movies visible to the viewer that the
+// profile has neither rated nor watched, ordered by how many profiles rated, watched or listed them.
+// Only the profile's favourite genres are considered; a profile without
+// history gets the most popular movies of the whole catalog.
+func (r *RecommendationsRepository) FindPopularInFavouriteGenres(c context.Context, viewer models.Viewer, limit int) ([]models.PopularMovie, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c,
+		`
+with favourite_genres as (
+	select mg.genre_id, count(*) as weight
+	from profile_movies pm
+	join movies_genres mg on mg.movie_id = pm.movie_id
+	where pm.profile_id = $1 and (pm.is_watched or pm.rating >= 4)
+	group by mg.genre_id
+	order by weight desc
+	limit $2
+),
+popularity as (
+	select movie_id, count(distinct profile_id) as score
+	from (
+		select profile_id, movie_id from profile_movies where is_watched or rating is not null
+		union all
+		select l.profile_id, lm.movie_id from list_movies lm join lists l on l.id = lm.list_id
+	) interactions
+	group by movie_id
+),
+candidates as (
+	select m.id as movie_id, coalesce(fg.genre_id, 0) as genre_id, coalesce(fg.weight, 0) as weight, coalesce(p.score, 0) as score
+	from movies m
+	left join popularity p on p.movie_id = m.id
+	left join lateral (
+		select f.genre_id, f.weight from favourite_genres f
+		join movies_genres mg on mg.genre_id = f.genre_id and mg.movie_id = m.id
+		order by f.weight desc
+		limit 1
+	) fg on true
+	where m.age_rating <= $4 and not exists (
+		select 1 from profile_movies own
+		where own.profile_id = $1 and own.movie_id = m.id and (own.is_watched or own.rating is not null)
+	)
+)
+select movie_id, genre_id
+from candidates
+where genre_id <> 0 or not exists (select 1 from favourite_genres)
+order by score desc, weight desc, movie_id
+limit $3
+	`,
+		viewer.ProfileId, favouriteGenresLimit, limit, viewer.MaxAgeRating)
+	if err != nil {
+		logger.Error("Could not find popular movies", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	popular := make([]models.PopularMovie, 0)
+	for rows.Next() {
+		var p models.PopularMovie
+		err = rows.Scan(&p.MovieId, &p.GenreId)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		popular = append(popular, p)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return popular, nil
+}
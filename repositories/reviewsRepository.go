@@ -0,0 +1,260 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/events"
+	"goozinshe/logger"
+	"goozinshe/models"
+)
+
+type ReviewsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReviewsRepository(conn *pgxpool.Pool) *ReviewsRepository {
+	return &ReviewsRepository{db: conn}
+}
+
+const reviewColumns = `
+r.id, r.movie_id, r.profile_id, p.name, r.title, r.body, r.is_spoiler, r.status, r.rejection_reason,
+(select count(*) from review_votes rv where rv.review_id = r.id),
+exists(select 1 from review_revisions rr where rr.review_id = r.id),
+r.created_at, r.updated_at
+`
+
+const reviewFrom = `
+from reviews r
+join profiles p on p.id = r.profile_id
+`
+
+func scanReview(row pgx.Row, extra ...any) (models.Review, error) {
+	var review models.Review
+	dest := []any{&review.Id, &review.MovieId, &review.ProfileId, &review.AuthorName, &review.Title, &review.Body,
+		&review.IsSpoiler, &review.Status, &review.RejectionReason, &review.HelpfulCount, &review.IsEdited,
+		&review.CreatedAt, &review.UpdatedAt}
+	err := row.Scan(append(dest, extra...)...)
+	return review, err
+}
+
+func (r *ReviewsRepository) findPage(c context.Context, sql string, args ...any) ([]models.Review, int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, sql, args...)
+	if err != nil {
+		logger.Error("Could not find reviews", zap.String("db_msg", err.Error()))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	total := 0
+	reviews := make([]models.Review, 0)
+	for rows.Next() {
+		review, err := scanReview(rows, &total)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, 0, err
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+// FindApprovedByMovieId returns a page of the movie's approved reviews,
+// newest first or, when sort is "helpful", most helpful first.
+func (r *ReviewsRepository) FindApprovedByMovieId(c context.Context, movieId int, sort string, limit int, offset int) ([]models.Review, int, error) {
+	order := "r.created_at desc, r.id desc"
+	if sort == "helpful" {
+		order = "(select count(*) from review_votes rv where rv.review_id = r.id) desc, r.created_at desc, r.id desc"
+	}
+	return r.findPage(c,
+		"select "+reviewColumns+", count(*) over ()"+reviewFrom+
+			"where r.movie_id = $1 and r.status = $2 order by "+order+" limit $3 offset $4",
+		movieId, models.ReviewStatusApproved, limit, offset)
+}
+
+// FindPending returns the moderation queue, oldest changes first.
+func (r *ReviewsRepository) FindPending(c context.Context, limit int, offset int) ([]models.Review, int, error) {
+	return r.findPage(c,
+		"select "+reviewColumns+", count(*) over ()"+reviewFrom+
+			"where r.status = $1 order by r.updated_at, r.id limit $2 offset $3",
+		models.ReviewStatusPending, limit, offset)
+}
+
+func (r *ReviewsRepository) FindById(c context.Context, id int) (models.Review, error) {
+	logger := logger.GetLogger()
+	review, err := scanReview(r.db.QueryRow(c, "select "+reviewColumns+reviewFrom+"where r.id = $1", id))
+	if err != nil {
+		logger.Error("Could not find review", zap.String("db_msg", err.Error()))
+		return models.Review{}, err
+	}
+	return review, nil
+}
+
+func (r *ReviewsRepository) FindByProfileAndMovie(c context.Context, profileId int, movieId int) (models.Review, error) {
+	logger := logger.GetLogger()
+	review, err := scanReview(r.db.QueryRow(c,
+		"select "+reviewColumns+reviewFrom+"where r.profile_id = $1 and r.movie_id = $2",
+		profileId, movieId))
+	if err != nil {
+		logger.Error("Could not find review", zap.String("db_msg", err.Error()))
+		return models.Review{}, err
+	}
+	return review, nil
+}
+
+// Save creates the profile's review of the movie or edits the existing one.
+// An edit keeps the previous text in the revision history. Either way the
+// review goes back to the moderation queue.
+func (r *ReviewsRepository) Save(c context.Context, review models.Review) (int, error) {
+	logger := logger.GetLogger()
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(c)
+
+	var id int
+	var previous models.ReviewRevision
+	err = tx.QueryRow(c,
+		"select id, title, body, is_spoiler, updated_at from reviews where profile_id = $1 and movie_id = $2 for update",
+		review.ProfileId, review.MovieId).Scan(&id, &previous.Title, &previous.Body, &previous.IsSpoiler, &previous.EditedAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		err = tx.QueryRow(c,
+			"insert into reviews(movie_id, profile_id, title, body, is_spoiler) values($1, $2, $3, $4, $5) returning id",
+			review.MovieId, review.ProfileId, review.Title, review.Body, review.IsSpoiler).Scan(&id)
+		if err != nil {
+			logger.Error("Could not insert review", zap.String("db_msg", err.Error()))
+			return 0, err
+		}
+	case err != nil:
+		logger.Error(err.Error())
+		return 0, err
+	default:
+		if previous.Title == review.Title && previous.Body == review.Body && previous.IsSpoiler == review.IsSpoiler {
+			return id, nil
+		}
+		_, err = tx.Exec(c,
+			"insert into review_revisions(review_id, title, body, is_spoiler, edited_at) values($1, $2, $3, $4, $5)",
+			id, previous.Title, previous.Body, previous.IsSpoiler, previous.EditedAt)
+		if err != nil {
+			logger.Error("Could not save review revision", zap.String("db_msg", err.Error()))
+			return 0, err
+		}
+		_, err = tx.Exec(c,
+			`
+update reviews
+set title = $1, body = $2, is_spoiler = $3, status = $4, rejection_reason = '',
+    moderated_by = null, moderated_at = null, updated_at = now()
+where id = $5
+	`,
+			review.Title, review.Body, review.IsSpoiler, models.ReviewStatusPending, id)
+		if err != nil {
+			logger.Error("Could not update review", zap.String("db_msg", err.Error()))
+			return 0, err
+		}
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *ReviewsRepository) Delete(c context.Context, id int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from reviews where id = $1", id)
+	if err != nil {
+		logger.Error("Could not delete review", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// FindRevisions returns previous versions of the review, newest first.
+func (r *ReviewsRepository) FindRevisions(c context.Context, id int) ([]models.ReviewRevision, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c,
+		"select id, title, body, is_spoiler, edited_at from review_revisions where review_id = $1 order by edited_at desc, id desc",
+		id)
+	if err != nil {
+		logger.Error("Could not find review revisions", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]models.ReviewRevision, 0)
+	for rows.Next() {
+		var revision models.ReviewRevision
+		err = rows.Scan(&revision.Id, &revision.Title, &revision.Body, &revision.IsSpoiler, &revision.EditedAt)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// Moderate approves or rejects the review on behalf of the moderator.
+func (r *ReviewsRepository) Moderate(c context.Context, id int, status string, reason string, moderatorId int) error {
+	logger := logger.GetLogger()
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c,
+		"update reviews set status = $1, rejection_reason = $2, moderated_by = $3, moderated_at = now() where id = $4",
+		status, reason, moderatorId, id)
+	if err != nil {
+		logger.Error("Could not moderate review", zap.String("db_msg", err.Error()))
+		return err
+	}
+
+	if status == models.ReviewStatusApproved {
+		err = addOutboxEvent(c, tx, events.ReviewApproved, map[string]any{"id": id})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+// SetHelpful records or withdraws the profile's "helpful" vote.
+func (r *ReviewsRepository) SetHelpful(c context.Context, id int, profileId int, helpful bool) error {
+	logger := logger.GetLogger()
+	var err error
+	if helpful {
+		_, err = r.db.Exec(c, "insert into review_votes(review_id, profile_id) values($1, $2) on conflict do nothing", id, profileId)
+	} else {
+		_, err = r.db.Exec(c, "delete from review_votes where review_id = $1 and profile_id = $2", id, profileId)
+	}
+	if err != nil {
+		logger.Error("Could not set review vote", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+)
+
+type SocialRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSocialRepository(conn *pgxpool.Pool) *SocialRepository {
+	return &SocialRepository{db: conn}
+}
+
+func (r *SocialRepository) Follow(c context.Context, followerId int, followeeId int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c,
+		"insert into follows(follower_id, followee_id) values($1, $2) on conflict do nothing",
+		followerId, followeeId)
+	if err != nil {
+		logger.Error("Could not follow profile", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (r *SocialRepository) Unfollow(c context.Context, followerId int, followeeId int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from follows where follower_id = $1 and followee_id = $2", followerId, followeeId)
+	if err != nil {
+		logger.Error("Could not unfollow profile", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// FindFollowing returns a page of the profiles the profile follows, most
+// recently followed first.
+func (r *SocialRepository) FindFollowing(c context.Context, profileId int, limit int, offset int) ([]models.Follow, int, error) {
+	return r.findFollows(c, `
+select p.id, p.name, p.avatar_url, f.created_at, count(*) over ()
+from follows f
+join profiles p on p.id = f.followee_id
+where f.follower_id = $1
+order by f.created_at desc, p.id
+limit $2 offset $3
+	`, profileId, limit, offset)
+}
+
+// FindFollowers returns a page of the profiles following the profile, most
+// recent followers first.
+func (r *SocialRepository) FindFollowers(c context.Context, profileId int, limit int, offset int) ([]models.Follow, int, error) {
+	return r.findFollows(c, `
+select p.id, p.name, p.avatar_url, f.created_at, count(*) over ()
+from follows f
+join profiles p on p.id = f.follower_id
+where f.followee_id = $1
+order by f.created_at desc, p.id
+limit $2 offset $3
+	`, profileId, limit, offset)
+}
+
+func (r *SocialRepository) findFollows(c context.Context, sql string, args ...any) ([]models.Follow, int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, sql, args...)
+	if err != nil {
+		logger.Error("Could not find follows", zap.String("db_msg", err.Error()))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	total := 0
+	follows := make([]models.Follow, 0)
+	for rows.Next() {
+		var follow models.Follow
+		err = rows.Scan(&follow.Profile.Id, &follow.Profile.Name, &follow.Profile.AvatarUrl, &follow.FollowedAt, &total)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, 0, err
+		}
+		follows = append(follows, follow)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, 0, err
+	}
+
+	return follows, total, nil
+}
+
+func (r *SocialRepository) GetPrivacy(c context.Context, profileId int) (models.PrivacySettings, error) {
+	logger := logger.GetLogger()
+	var settings models.PrivacySettings
+	err := r.db.QueryRow(c,
+		"select publish_ratings, publish_watched, publish_lists, publish_reviews from profiles where id = $1",
+		profileId).Scan(&settings.PublishRatings, &settings.PublishWatched, &settings.PublishLists, &settings.PublishReviews)
+	if err != nil {
+		logger.Error("Could not find privacy settings", zap.String("db_msg", err.Error()))
+		return models.PrivacySettings{}, err
+	}
+	return settings, nil
+}
+
+func (r *SocialRepository) SetPrivacy(c context.Context, profileId int, settings models.PrivacySettings) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, `
+update profiles
+set publish_ratings = @publishRatings, publish_watched = @publishWatched,
+    publish_lists = @publishLists, publish_reviews = @publishReviews
+where id = @id
+	`, pgx.NamedArgs{
+		"id":             profileId,
+		"publishRatings": settings.PublishRatings,
+		"publishWatched": settings.PublishWatched,
+		"publishLists":   settings.PublishLists,
+		"publishReviews": settings.PublishReviews,
+	})
+	if err != nil {
+		logger.Error("Could not update privacy settings", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// RecordActivity stores the event. Repeating an action refreshes the
+// existing event instead of adding a new one.
+func (r *SocialRepository) RecordActivity(c context.Context, event models.ActivityEvent) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, `
+insert into activity_events(profile_id, type, movie_id, list_id, review_id, rating)
+values(@profileId, @type, @movieId, @listId, @reviewId, @rating)
+on conflict (profile_id, type, movie_id, coalesce(list_id, 0))
+do update set review_id = excluded.review_id, rating = excluded.rating, created_at = now()
+	`, pgx.NamedArgs{
+		"profileId": event.ProfileId,
+		"type":      event.Type,
+		"movieId":   event.MovieId,
+		"listId":    event.ListId,
+		"reviewId":  event.ReviewId,
+		"rating":    event.Rating,
+	})
+	if err != nil {
+		logger.Error("Could not record activity", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// DeleteActivity removes the event of an action that was undone.
+func (r *SocialRepository) DeleteActivity(c context.Context, event models.ActivityEvent) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, `
+delete from activity_events
+where profile_id = $1 and type = $2 and movie_id = $3 and coalesce(list_id, 0) = coalesce($4::int, 0)
+	`, event.ProfileId, event.Type, event.MovieId, event.ListId)
+	if err != nil {
+		logger.Error("Could not delete activity", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// FindFeed returns a page of activity of the profiles the viewer follows,
+// newest first. Privacy settings are applied when reading, so changing them
+// also hides or reveals past activity. Movies the viewer may not see, lists
+// that are no longer public and reviews that are not approved are skipped.
+func (r *SocialRepository) FindFeed(c context.Context, viewer models.Viewer, limit int, offset int) ([]models.FeedItem, int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, `
+select e.id, e.type, p.id, p.name, p.avatar_url, e.movie_id, e.rating, e.list_id, coalesce(l.name, ''),
+       e.review_id, coalesce(rv.title, ''), e.created_at, count(*) over ()
+from follows f
+join activity_events e on e.profile_id = f.followee_id
+join profiles p on p.id = e.profile_id
+join movies m on m.id = e.movie_id
+left join lists l on l.id = e.list_id
+left join reviews rv on rv.id = e.review_id
+where f.follower_id = @profileId
+  and m.age_rating <= @maxAgeRating
+  and case e.type
+          when @rated then p.publish_ratings
+          when @watched then p.publish_watched
+          when @watchlisted then p.publish_lists
+          when @listed then p.publish_lists and coalesce(l.is_public, false)
+          when @reviewed then p.publish_reviews and coalesce(rv.status = @approved, false)
+          else false
+      end
+order by e.created_at desc, e.id desc
+limit @limit offset @offset
+	`, pgx.NamedArgs{
+		"profileId":    viewer.ProfileId,
+		"maxAgeRating": viewer.MaxAgeRating,
+		"rated":        models.ActivityRated,
+		"watched":      models.ActivityWatched,
+		"watchlisted":  models.ActivityWatchlisted,
+		"listed":       models.ActivityListed,
+		"reviewed":     models.ActivityReviewed,
+		"approved":     models.ReviewStatusApproved,
+		"limit":        limit,
+		"offset":       offset,
+	})
+	if err != nil {
+		logger.Error("Could not find feed", zap.String("db_msg", err.Error()))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	total := 0
+	items := make([]models.FeedItem, 0)
+	for rows.Next() {
+		var item models.FeedItem
+		err = rows.Scan(&item.Id, &item.Type, &item.Profile.Id, &item.Profile.Name, &item.Profile.AvatarUrl,
+			&item.MovieId, &item.Rating, &item.ListId, &item.ListName, &item.ReviewId, &item.ReviewTitle,
+			&item.CreatedAt, &total)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+)
+
+const topStatsLimit = 5
+
+type StatsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewStatsRepository(conn *pgxpool.Pool) *StatsRepository {
+	return &StatsRepository{db: conn}
+}
+
+// watchEventsFilter restricts watch_events (aliased we) by the named args
+// @profileId and @year, both ignored when 0.
+const watchEventsFilter = `
+(@profileId::int = 0 or we.profile_id = @profileId)
+and (@year::int = 0 or extract(year from we.watched_at)::int = @year)
+`
+
+func (r *StatsRepository) GetStats(c context.Context, filter models.StatsFilter) (models.ViewingStats, error) {
+	logger := logger.GetLogger()
+	params := pgx.NamedArgs{
+		"profileId": filter.ProfileId,
+		"year":      filter.Year,
+		"langs":     models.LanguageFallbacks(filter.Language),
+		"limit":     topStatsLimit,
+	}
+
+	var stats models.ViewingStats
+	err := r.db.QueryRow(c,
+		`
+select count(*), coalesce(sum(m.runtime_minutes), 0)
+from watch_events we
+join movies m on m.id = we.movie_id
+where `+watchEventsFilter,
+		params).Scan(&stats.TotalViews, &stats.TotalMinutes)
+	if err != nil {
+		logger.Error("Could not count views", zap.String("db_msg", err.Error()))
+		return models.ViewingStats{}, err
+	}
+
+	stats.ByYear, err = r.findPeriodStats(c, "YYYY", params)
+	if err != nil {
+		return models.ViewingStats{}, err
+	}
+	stats.ByMonth, err = r.findPeriodStats(c, "YYYY-MM", params)
+	if err != nil {
+		return models.ViewingStats{}, err
+	}
+
+	stats.TopGenres, err = r.findNamedCounts(c,
+		`
+select coalesce(nullif(gt.title, ''), g.title), count(*)
+from watch_events we
+join movies_genres mg on mg.movie_id = we.movie_id
+join genres g on g.id = mg.genre_id
+left join lateral (
+	select t.title from genre_translations t
+	where t.genre_id = g.id and t.language = any(@langs::text[])
+	order by array_position(@langs::text[], t.language)
+	limit 1
+) gt on true
+where `+watchEventsFilter+`
+group by g.id, gt.title, g.title
+order by count(*) desc
+limit @limit
+`,
+		params)
+	if err != nil {
+		return models.ViewingStats{}, err
+	}
+
+	stats.TopDirectors, err = r.findNamedCounts(c,
+		`
+select m.director, count(*)
+from watch_events we
+join movies m on m.id = we.movie_id
+where m.director <> '' and `+watchEventsFilter+`
+group by m.director
+order by count(*) desc
+limit @limit
+`,
+		params)
+	if err != nil {
+		return models.ViewingStats{}, err
+	}
+
+	rows, err := r.db.Query(c,
+		`
+select pm.rating, count(*)
+from profile_movies pm
+where pm.rating is not null
+  and (@profileId::int = 0 or pm.profile_id = @profileId)
+  -- Ratings have no date of their own; a year keeps those of the movies watched in it.
+  and (@year::int = 0 or exists (
+	select 1 from watch_events we
+	where we.profile_id = pm.profile_id and we.movie_id = pm.movie_id and `+watchEventsFilter+`
+  ))
+group by pm.rating
+order by pm.rating
+`,
+		params)
+	if err != nil {
+		logger.Error("Could not find rating distribution", zap.String("db_msg", err.Error()))
+		return models.ViewingStats{}, err
+	}
+	defer rows.Close()
+
+	stats.RatingDistribution = make([]models.RatingCount, 0)
+	ratingsCount, ratingsSum := 0, 0
+	for rows.Next() {
+		var rc models.RatingCount
+		err = rows.Scan(&rc.Rating, &rc.Count)
+		if err != nil {
+			logger.Error(err.Error())
+			return models.ViewingStats{}, err
+		}
+		stats.RatingDistribution = append(stats.RatingDistribution, rc)
+		ratingsCount += rc.Count
+		ratingsSum += rc.Rating * rc.Count
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return models.ViewingStats{}, err
+	}
+	if ratingsCount > 0 {
+		stats.AverageRating = float64(ratingsSum) / float64(ratingsCount)
+	}
+
+	return stats, nil
+}
+
+func (r *StatsRepository) findPeriodStats(c context.Context, format string, params pgx.NamedArgs) ([]models.PeriodStats, error) {
+	logger := logger.GetLogger()
+	args := pgx.NamedArgs{"format": format}
+	for k, v := range params {
+		args[k] = v
+	}
+	rows, err := r.db.Query(c,
+		`
+select to_char(we.watched_at, @format), count(*), coalesce(sum(m.runtime_minutes), 0)
+from watch_events we
+join movies m on m.id = we.movie_id
+where `+watchEventsFilter+`
+group by 1
+order by 1
+`,
+		args)
+	if err != nil {
+		logger.Error("Could not find period stats", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := make([]models.PeriodStats, 0)
+	for rows.Next() {
+		var p models.PeriodStats
+		err = rows.Scan(&p.Period, &p.Views, &p.Minutes)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		periods = append(periods, p)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return periods, nil
+}
+
+func (r *StatsRepository) findNamedCounts(c context.Context, sql string, params pgx.NamedArgs) ([]models.NamedCount, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, sql, params)
+	if err != nil {
+		logger.Error("Could not find top stats", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]models.NamedCount, 0)
+	for rows.Next() {
+		var nc models.NamedCount
+		err = rows.Scan(&nc.Name, &nc.Count)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		counts = append(counts, nc)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+)
+
+type TagsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTagsRepository(conn *pgxpool.Pool) *TagsRepository {
+	return &TagsRepository{db: conn}
+}
+
+func (r *TagsRepository) FindAll(c context.Context, searchTerm string) ([]models.TagUsage, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c,
+		`
+select t.id, t.name, count(mt.movie_id)
+from tags t
+left join movies_tags mt on mt.tag_id = t.id
+where $1 = '' or t.name ilike '%' || $1 || '%'
+group by t.id, t.name
+order by count(mt.movie_id) desc, t.name
+	`,
+		searchTerm)
+	if err != nil {
+		logger.Error("Could not find all tags", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]models.TagUsage, 0)
+	for rows.Next() {
+		var t models.TagUsage
+		err = rows.Scan(&t.Id, &t.Name, &t.UsageCount)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *TagsRepository) FindById(c context.Context, id int) (models.TagUsage, error) {
+	logger := logger.GetLogger()
+	var t models.TagUsage
+	row := r.db.QueryRow(c, "select t.id, t.name, (select count(*) from movies_tags mt where mt.tag_id = t.id) from tags t where t.id = $1", id)
+	err := row.Scan(&t.Id, &t.Name, &t.UsageCount)
+	if err != nil {
+		logger.Error("Could not find tag", zap.String("db_msg", err.Error()))
+		return models.TagUsage{}, err
+	}
+	return t, nil
+}
+
+func (r *TagsRepository) FindAllByIds(c context.Context, ids []int) ([]models.Tag, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, "select id, name from tags where id = any($1)", ids)
+	if err != nil {
+		logger.Error("Could not find all tags by their ids", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]models.Tag, 0)
+	for rows.Next() {
+		var t models.Tag
+		err = rows.Scan(&t.Id, &t.Name)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *TagsRepository) Create(c context.Context, tag models.Tag) (int, error) {
+	logger := logger.GetLogger()
+	var id int
+	err := r.db.QueryRow(c, "insert into tags(name) values($1) returning id", tag.Name).Scan(&id)
+	if err != nil {
+		logger.Error("Could not insert tag", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *TagsRepository) Update(c context.Context, id int, tag models.Tag) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "update tags set name = $1 where id = $2", tag.Name, id)
+	if err != nil {
+		logger.Error("Could not update tag", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (r *TagsRepository) Delete(c context.Context, id int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from tags where id = $1", id)
+	if err != nil {
+		logger.Error("Could not delete tag", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// Merge moves every assignment of the source tags to the target tag and
+// deletes the source tags in a single transaction.
+func (r *TagsRepository) Merge(c context.Context, targetId int, sourceIds []int) error {
+	logger := logger.GetLogger()
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c,
+		`
+insert into movies_tags(movie_id, tag_id)
+select distinct movie_id, $1::int from movies_tags where tag_id = any($2)
+on conflict do nothing
+	`,
+		targetId, sourceIds)
+	if err != nil {
+		logger.Error("Could not reassign merged tags", zap.String("db_msg", err.Error()))
+		return err
+	}
+
+	_, err = tx.Exec(c, "delete from tags where id = any($1)", sourceIds)
+	if err != nil {
+		logger.Error("Could not delete merged tags", zap.String("db_msg", err.Error()))
+		return err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+// findTagsByMovieIds loads tags of the given movies keyed by movie id.
+func findTagsByMovieIds(c context.Context, db *pgxpool.Pool, movieIds []int) (map[int][]models.Tag, error) {
+	logger := logger.GetLogger()
+	rows, err := db.Query(c,
+		`
+select mt.movie_id, t.id, t.name
+from movies_tags mt
+join tags t on t.id = mt.tag_id
+where mt.movie_id = any($1)
+order by t.name
+	`,
+		movieIds)
+	if err != nil {
+		logger.Error("Could not find movie tags", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[int][]models.Tag)
+	for rows.Next() {
+		var movieId int
+		var t models.Tag
+		err = rows.Scan(&movieId, &t.Id, &t.Name)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		tags[movieId] = append(tags[movieId], t)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return tags, nil
+}
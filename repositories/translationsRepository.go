@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+)
+
+type TranslationsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTranslationsRepository(conn *pgxpool.Pool) *TranslationsRepository {
+	return &TranslationsRepository{db: conn}
+}
+
+func (r *TranslationsRepository) FindMovieTranslations(c context.Context, movieId int) ([]models.MovieTranslation, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, "select movie_id, language, title, description from movie_translations where movie_id = $1 order by language", movieId)
+	if err != nil {
+		logger.Error("Could not find movie translations", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make([]models.MovieTranslation, 0)
+	for rows.Next() {
+		var t models.MovieTranslation
+		err = rows.Scan(&t.MovieId, &t.Language, &t.Title, &t.Description)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		translations = append(translations, t)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return translations, nil
+}
+
+func (r *TranslationsRepository) UpsertMovieTranslation(c context.Context, t models.MovieTranslation) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c,
+		`
+insert into movie_translations(movie_id, language, title, description)
+values($1, $2, $3, $4)
+on conflict (movie_id, language) do update
+set title = excluded.title, description = excluded.description
+	`,
+		t.MovieId, t.Language, t.Title, t.Description)
+	if err != nil {
+		logger.Error("Could not save movie translation", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (r *TranslationsRepository) DeleteMovieTranslation(c context.Context, movieId int, lang string) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from movie_translations where movie_id = $1 and language = $2", movieId, lang)
+	if err != nil {
+		logger.Error("Could not delete movie translation", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (r *TranslationsRepository) FindGenreTranslations(c context.Context, genreId int) ([]models.GenreTranslation, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, "select genre_id, language, title from genre_translations where genre_id = $1 order by language", genreId)
+	if err != nil {
+		logger.Error("Could not find genre translations", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make([]models.GenreTranslation, 0)
+	for rows.Next() {
+		var t models.GenreTranslation
+		err = rows.Scan(&t.GenreId, &t.Language, &t.Title)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		translations = append(translations, t)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return translations, nil
+}
+
+func (r *TranslationsRepository) UpsertGenreTranslation(c context.Context, t models.GenreTranslation) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c,
+		`
+insert into genre_translations(genre_id, language, title)
+values($1, $2, $3)
+on conflict (genre_id, language) do update
+set title = excluded.title
+	`,
+		t.GenreId, t.Language, t.Title)
+	if err != nil {
+		logger.Error("Could not save genre translation", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (r *TranslationsRepository) DeleteGenreTranslation(c context.Context, genreId int, lang string) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from genre_translations where genre_id = $1 and language = $2", genreId, lang)
+	if err != nil {
+		logger.Error("Could not delete genre translation", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
+	"goozinshe/events"
 	"goozinshe/logger"
 	"goozinshe/models"
 )
@@ -18,7 +19,7 @@ func NewUsersRepository(conn *pgxpool.Pool) *UsersRepository {
 
 func (r *UsersRepository) FindAll(c context.Context) ([]models.User, error) {
 	logger := logger.GetLogger()
-	rows, err := r.db.Query(c, "select * from users")
+	rows, err := r.db.Query(c, "select id, name, email, password_hash, role from users")
 	if err != nil {
 		logger.Error("Could not find all users", zap.Error(err))
 		return nil, err
@@ -26,7 +27,7 @@ func (r *UsersRepository) FindAll(c context.Context) ([]models.User, error) {
 	users := make([]models.User, 0)
 	for rows.Next() {
 		var user models.User
-		err := rows.Scan(&user.Id, &user.Name, &user.Email, &user.PasswordHash)
+		err := rows.Scan(&user.Id, &user.Name, &user.Email, &user.PasswordHash, &user.Role)
 		if err != nil {
 			logger.Error(err.Error())
 			return nil, err
@@ -41,20 +42,43 @@ func (r *UsersRepository) FindAll(c context.Context) ([]models.User, error) {
 
 func (r *UsersRepository) Create(c context.Context, user models.User) (int, error) {
 	logger := logger.GetLogger()
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(c)
+
 	var id int
-	err := r.db.QueryRow(c, "insert into users(name, email, password_hash) values($1, $2, $3) returning id", user.Name, user.Email, user.PasswordHash).Scan(&id)
+	err = tx.QueryRow(c, "insert into users(name, email, password_hash) values($1, $2, $3) returning id", user.Name, user.Email, user.PasswordHash).Scan(&id)
 	if err != nil {
 		logger.Error("Could not insert user", zap.String("db_msg", err.Error()))
 		return 0, err
 	}
-	return id, err
+
+	_, err = tx.Exec(c, "insert into profiles(user_id, name, is_default) values($1, $2, true)", id, user.Name)
+	if err != nil {
+		logger.Error("Could not create default profile", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+
+	err = addOutboxEvent(c, tx, events.UserCreated, map[string]any{"id": id})
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return 0, err
+	}
+	return id, nil
 }
 
 func (r *UsersRepository) FindById(c context.Context, id int) (models.User, error) {
 	logger := logger.GetLogger()
-	row := r.db.QueryRow(c, "select id, name, email, password_hash from users where id = $1", id)
+	row := r.db.QueryRow(c, "select id, name, email, password_hash, role from users where id = $1", id)
 	var user models.User
-	err := row.Scan(&user.Id, &user.Name, &user.Email, &user.PasswordHash)
+	err := row.Scan(&user.Id, &user.Name, &user.Email, &user.PasswordHash, &user.Role)
 	if err != nil {
 		logger.Error("Could not find user by id", zap.String("db_msg", err.Error()))
 		return models.User{}, err
@@ -64,9 +88,9 @@ func (r *UsersRepository) FindById(c context.Context, id int) (models.User, erro
 
 func (r *UsersRepository) FindByEmail(c context.Context, email string) (models.User, error) {
 	logger := logger.GetLogger()
-	row := r.db.QueryRow(c, "select id, name, email, password_hash from users where email = $1", email)
+	row := r.db.QueryRow(c, "select id, name, email, password_hash, role from users where email = $1", email)
 	var user models.User
-	err := row.Scan(&user.Id, &user.Name, &user.Email, &user.PasswordHash)
+	err := row.Scan(&user.Id, &user.Name, &user.Email, &user.PasswordHash, &user.Role)
 	if err != nil {
 		logger.Error("Could not find user by email", zap.String("db_msg", err.Error()))
 		return models.User{}, err
@@ -76,12 +100,36 @@ func (r *UsersRepository) FindByEmail(c context.Context, email string) (models.U
 
 func (r *UsersRepository) Update(c context.Context, id int, user models.User) error {
 	logger := logger.GetLogger()
-	_, err := r.db.Exec(c, "update users set name = $1, email = $2, password_hash = $3 where id = $4", user.Name, user.Email, user.PasswordHash, id)
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	defer tx.Rollback(c)
+
+	// A new address has to be verified again.
+	_, err = tx.Exec(c, `
+update users
+set name = $1, email = $2, password_hash = $3,
+    email_verified_at = case when email = $2 then email_verified_at end
+where id = $4
+	`, user.Name, user.Email, user.PasswordHash, id)
 	if err != nil {
 		logger.Error("Could not update user", zap.String("db_msg", err.Error()))
 		return err
 	}
-	return err
+
+	err = addOutboxEvent(c, tx, events.UserUpdated, map[string]any{"id": id})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	return nil
 }
 
 func (r *UsersRepository) ChangePassword(c context.Context, id int, passwordHash string) error {
@@ -94,12 +142,72 @@ func (r *UsersRepository) ChangePassword(c context.Context, id int, passwordHash
 	return err
 }
 
+// MarkEmailVerified records that the user confirmed the address. It reports
+// false when the user no longer has it.
+func (r *UsersRepository) MarkEmailVerified(c context.Context, id int, email string) (bool, error) {
+	logger := logger.GetLogger()
+	tag, err := r.db.Exec(c,
+		"update users set email_verified_at = coalesce(email_verified_at, now()) where id = $1 and email = $2",
+		id, email)
+	if err != nil {
+		logger.Error("Could not mark email as verified", zap.String("db_msg", err.Error()))
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *UsersRepository) ChangeRole(c context.Context, id int, role string) error {
+	logger := logger.GetLogger()
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c, "update users set role = $1 where id = $2", role, id)
+	if err != nil {
+		logger.Error("Could not change role", zap.String("db_msg", err.Error()))
+		return err
+	}
+
+	err = addOutboxEvent(c, tx, events.UserUpdated, map[string]any{"id": id})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
 func (r *UsersRepository) Delete(c context.Context, id int) error {
 	logger := logger.GetLogger()
-	_, err := r.db.Exec(c, "delete from users where id = $1", id)
+	tx, err := r.db.Begin(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c, "delete from users where id = $1", id)
 	if err != nil {
 		logger.Error("Could not delete user", zap.String("db_msg", err.Error()))
 		return err
 	}
-	return err
+
+	err = addOutboxEvent(c, tx, events.UserDeleted, map[string]any{"id": id})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	return nil
 }
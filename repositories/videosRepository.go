@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"time"
+)
+
+type VideosRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewVideosRepository(conn *pgxpool.Pool) *VideosRepository {
+	return &VideosRepository{db: conn}
+}
+
+// FindByMovieId returns the movie's video. A video that has been processing
+// for longer than processingTimeout is reported as failed.
+func (r *VideosRepository) FindByMovieId(c context.Context, movieId int, processingTimeout time.Duration) (models.Video, error) {
+	logger := logger.GetLogger()
+	var video models.Video
+	row := r.db.QueryRow(c, `
+select movie_id,
+       case when stale then @failed::text else status end,
+       case when stale then 'Processing timed out' else error end,
+       updated_at,
+       version,
+       published_version
+from (
+	select *, status = @processing and updated_at < now() - @timeout::float8 * interval '1 second' as stale
+	from movie_videos
+	where movie_id = @movieId
+) v
+	`, pgx.NamedArgs{
+		"movieId":    movieId,
+		"processing": models.VideoStatusProcessing,
+		"failed":     models.VideoStatusFailed,
+		"timeout":    processingTimeout.Seconds(),
+	})
+	err := row.Scan(&video.MovieId, &video.Status, &video.Error, &video.UpdatedAt, &video.Version, &video.PublishedVersion)
+	if err != nil {
+		logger.Error("Could not find movie video", zap.String("db_msg", err.Error()))
+		return models.Video{}, err
+	}
+	return video, nil
+}
+
+// ClaimUpload starts processing a new upload of the movie's video and
+// returns its version. It reports false while another upload is being
+// processed, unless that one has been processing for longer than
+// processingTimeout and is considered failed.
+func (r *VideosRepository) ClaimUpload(c context.Context, movieId int, processingTimeout time.Duration) (int, bool, error) {
+	logger := logger.GetLogger()
+	var version int
+	err := r.db.QueryRow(c, `
+insert into movie_videos(movie_id, status, error, version, updated_at)
+values(@movieId, @processing, '', 1, now())
+on conflict (movie_id) do update
+set status = excluded.status, error = '', version = movie_videos.version + 1, updated_at = now()
+where movie_videos.status <> @processing
+   or movie_videos.updated_at < now() - @timeout::float8 * interval '1 second'
+returning version
+	`, pgx.NamedArgs{
+		"movieId":    movieId,
+		"processing": models.VideoStatusProcessing,
+		"timeout":    processingTimeout.Seconds(),
+	}).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		logger.Error("Could not claim movie video upload", zap.String("db_msg", err.Error()))
+		return 0, false, err
+	}
+	return version, true, nil
+}
+
+// Publish makes the version the one being streamed. It reports false when
+// the version is no longer the latest upload.
+func (r *VideosRepository) Publish(c context.Context, movieId int, version int) (bool, error) {
+	logger := logger.GetLogger()
+	tag, err := r.db.Exec(c, `
+update movie_videos
+set status = $1, error = '', published_version = version, updated_at = now()
+where movie_id = $2 and version = $3 and status = $4
+	`, models.VideoStatusReady, movieId, version, models.VideoStatusProcessing)
+	if err != nil {
+		logger.Error("Could not publish movie video", zap.String("db_msg", err.Error()))
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Fail marks the upload as failed, unless a newer one has replaced it.
+func (r *VideosRepository) Fail(c context.Context, movieId int, version int, errorMessage string) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, `
+update movie_videos
+set status = $1, error = $2, updated_at = now()
+where movie_id = $3 and version = $4 and status = $5
+	`, models.VideoStatusFailed, errorMessage, movieId, version, models.VideoStatusProcessing)
+	if err != nil {
+		logger.Error("Could not set movie video status", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
@@ -17,27 +17,54 @@ func NewWatchlistRepository(db *pgxpool.Pool) *WatchlistRepository {
 	return &WatchlistRepository{db: db}
 }
 
-func (r *WatchlistRepository) GetMoviesFromWatchlist(c context.Context) ([]models.Movie, error) {
+// GetMoviesFromWatchlist returns the movies of the profile's default list in
+// list order, or by priority when sort is "priority" or by time of addition
+// when it is "added".
+func (r *WatchlistRepository) GetMoviesFromWatchlist(c context.Context, viewer models.Viewer, sort string) ([]models.Movie, error) {
 	sql := `
 select m.id, 
-       m.title, 
-       m.description, 
+       coalesce(nullif(mt.title, ''), m.title), 
+       coalesce(nullif(mt.description, ''), m.description), 
        m.release_year, 
+       m.age_rating, 
+       m.runtime_minutes, 
        m.director, 
-       m.rating, 
+       coalesce(pm.rating, 0), 
        m.trailer_url, 
        m.poster_url,
        g.id,
-       g.title
-from watchlist wl
-join movies m on wl.movie_id = m.id
+       coalesce(nullif(gt.title, ''), g.title)
+from lists l
+join list_movies lm on lm.list_id = l.id
+join movies m on lm.movie_id = m.id
 join movies_genres mg on m.id = mg.movie_id
 join genres g on mg.genre_id = g.id
-order by wl.added_at
+left join profile_movies pm on pm.movie_id = m.id and pm.profile_id = l.profile_id
+left join lateral (
+	select t.title, t.description from movie_translations t
+	where t.movie_id = m.id and t.language = any($1::text[])
+	order by array_position($1::text[], t.language)
+	limit 1
+) mt on true
+left join lateral (
+	select t.title from genre_translations t
+	where t.genre_id = g.id and t.language = any($1::text[])
+	order by array_position($1::text[], t.language)
+	limit 1
+) gt on true
+where l.profile_id = $3 and l.is_default and m.age_rating <= $2
 `
+	switch sort {
+	case "priority":
+		sql += "order by lm.priority desc, lm.position"
+	case "added":
+		sql += "order by lm.added_at, lm.position"
+	default:
+		sql += "order by lm.position"
+	}
 	logger := logger.GetLogger()
 
-	rows, err := r.db.Query(c, sql)
+	rows, err := r.db.Query(c, sql, models.LanguageFallbacks(viewer.Language), viewer.MaxAgeRating, viewer.ProfileId)
 	if err != nil {
 		logger.Error("Could not get movies from watchlist", zap.String("db_msg", err.Error()))
 		return nil, err
@@ -49,7 +76,7 @@ order by wl.added_at
 	for rows.Next() {
 		var movie models.Movie
 		var genre models.Genre
-		err := rows.Scan(&movie.Id, &movie.Title, &movie.Description, &movie.ReleaseYear, &movie.Director,
+		err := rows.Scan(&movie.Id, &movie.Title, &movie.Description, &movie.ReleaseYear, &movie.AgeRating, &movie.RuntimeMinutes, &movie.Director,
 			&movie.Rating, &movie.TrailerUrl, &movie.PosterUrl, &genre.Id, &genre.Title)
 		if err != nil {
 			logger.Error(err.Error())
@@ -68,8 +95,18 @@ order by wl.added_at
 		return nil, err
 	}
 
+	movieIds := make([]int, 0, len(movies))
+	for _, m := range movies {
+		movieIds = append(movieIds, m.Id)
+	}
+	tags, err := findTagsByMovieIds(c, r.db, movieIds)
+	if err != nil {
+		return nil, err
+	}
+
 	concreteMovies := make([]models.Movie, 0, len(movies))
 	for _, m := range movies {
+		m.Tags = tags[m.Id]
 		concreteMovies = append(concreteMovies, *m)
 	}
 
@@ -77,22 +114,61 @@ order by wl.added_at
 
 }
 
-func (r *WatchlistRepository) AddToWatchlist(c context.Context, movieId int) error {
-	logger := logger.GetLogger()
-	_, err := r.db.Exec(c, "insert into watchlist(movie_id, added_at) values($1, $2)", movieId, time.Now())
+// AddToWatchlist appends the movie to the profile's default list; adding a
+// movie that is already there is a no-op.
+func (r *WatchlistRepository) AddToWatchlist(c context.Context, profileId int, movieId int) error {
+	listId, err := defaultListId(c, r.db, profileId)
 	if err != nil {
-		logger.Error("Could not add to watchlist", zap.String("db_msg", err.Error()))
 		return err
 	}
+	_, err = addListMovie(c, r.db, listId, movieId, "", 0)
 	return err
 }
 
-func (r *WatchlistRepository) RemoveFromWatchlist(c context.Context, movieId int) error {
-	logger := logger.GetLogger()
-	_, err := r.db.Exec(c, "delete from watchlist where movie_id = $1", movieId)
+func (r *WatchlistRepository) RemoveFromWatchlist(c context.Context, profileId int, movieId int) error {
+	listId, err := defaultListId(c, r.db, profileId)
 	if err != nil {
-		logger.Error("Could not remove from watchlist", zap.String("db_msg", err.Error()))
 		return err
 	}
-	return err
+	return removeListMovie(c, r.db, listId, movieId)
+}
+
+// UpdateWatchlistEntry changes the note, priority or reminder of a watchlist
+// movie. It reports false when the movie is not on the watchlist.
+func (r *WatchlistRepository) UpdateWatchlistEntry(c context.Context, profileId int, movieId int, update models.ListEntryUpdate) (bool, error) {
+	listId, err := defaultListId(c, r.db, profileId)
+	if err != nil {
+		return false, err
+	}
+	return updateListEntry(c, r.db, listId, movieId, update)
+}
+
+// MoveInWatchlist moves the movie to position (1-based) on the watchlist. It
+// reports false when the movie is not on the watchlist.
+func (r *WatchlistRepository) MoveInWatchlist(c context.Context, profileId int, movieId int, position int) (bool, error) {
+	listId, err := defaultListId(c, r.db, profileId)
+	if err != nil {
+		return false, err
+	}
+	return moveListMovie(c, r.db, listId, movieId, position)
+}
+
+// FindDueReminders returns watchlist entries whose reminder date is on or
+// before the given date, earliest and most important first.
+func (r *WatchlistRepository) FindDueReminders(c context.Context, profileId int, date time.Time) ([]models.ListEntry, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c,
+		`
+select `+listEntryColumns+`
+from list_movies
+where list_id = (select id from lists where profile_id = $1 and is_default)
+  and remind_at <= $2::date
+order by remind_at, priority desc, position
+	`,
+		profileId, date)
+	if err != nil {
+		logger.Error("Could not find due reminders", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	return scanListEntries(rows)
 }
@@ -0,0 +1,284 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"time"
+)
+
+type WebhooksRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhooksRepository(conn *pgxpool.Pool) *WebhooksRepository {
+	return &WebhooksRepository{db: conn}
+}
+
+const webhookColumns = "id, url, secret, events, description, is_active, created_at"
+
+func scanWebhook(row pgx.Row) (models.Webhook, error) {
+	var webhook models.Webhook
+	err := row.Scan(&webhook.Id, &webhook.Url, &webhook.Secret, &webhook.Events, &webhook.Description,
+		&webhook.IsActive, &webhook.CreatedAt)
+	return webhook, err
+}
+
+func (r *WebhooksRepository) FindAll(c context.Context) ([]models.Webhook, error) {
+	return r.findAll(c, "select "+webhookColumns+" from webhooks order by id")
+}
+
+// FindActive returns the webhooks that currently receive events.
+func (r *WebhooksRepository) FindActive(c context.Context) ([]models.Webhook, error) {
+	return r.findAll(c, "select "+webhookColumns+" from webhooks where is_active order by id")
+}
+
+func (r *WebhooksRepository) findAll(c context.Context, sql string) ([]models.Webhook, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, sql)
+	if err != nil {
+		logger.Error("Could not find webhooks", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]models.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *WebhooksRepository) FindById(c context.Context, id int) (models.Webhook, error) {
+	logger := logger.GetLogger()
+	webhook, err := scanWebhook(r.db.QueryRow(c, "select "+webhookColumns+" from webhooks where id = $1", id))
+	if err != nil {
+		logger.Error("Could not find webhook", zap.String("db_msg", err.Error()))
+		return models.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func (r *WebhooksRepository) Create(c context.Context, webhook models.Webhook) (int, error) {
+	logger := logger.GetLogger()
+	var id int
+	err := r.db.QueryRow(c, `
+insert into webhooks(url, secret, events, description, is_active)
+values(@url, @secret, @events, @description, @isActive)
+returning id
+	`, pgx.NamedArgs{
+		"url":         webhook.Url,
+		"secret":      webhook.Secret,
+		"events":      webhook.Events,
+		"description": webhook.Description,
+		"isActive":    webhook.IsActive,
+	}).Scan(&id)
+	if err != nil {
+		logger.Error("Could not insert webhook", zap.String("db_msg", err.Error()))
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *WebhooksRepository) Update(c context.Context, id int, webhook models.Webhook) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, `
+update webhooks
+set url = @url, secret = @secret, events = @events, description = @description, is_active = @isActive
+where id = @id
+	`, pgx.NamedArgs{
+		"id":          id,
+		"url":         webhook.Url,
+		"secret":      webhook.Secret,
+		"events":      webhook.Events,
+		"description": webhook.Description,
+		"isActive":    webhook.IsActive,
+	})
+	if err != nil {
+		logger.Error("Could not update webhook", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (r *WebhooksRepository) Delete(c context.Context, id int) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, "delete from webhooks where id = $1", id)
+	if err != nil {
+		logger.Error("Could not delete webhook", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// CreateDeliveries queues the payload of one event for each of the webhooks,
+// skipping the webhooks the event is already queued for.
+func (r *WebhooksRepository) CreateDeliveries(c context.Context, webhookIds []int, eventId string, eventType string, payload []byte) error {
+	logger := logger.GetLogger()
+	_, err := r.db.Exec(c, `
+insert into webhook_deliveries(webhook_id, event_id, event_type, payload)
+select unnest($1::int[]), $2::uuid, $3, $4::jsonb
+on conflict (webhook_id, event_id) do nothing
+	`, webhookIds, eventId, eventType, payload)
+	if err != nil {
+		logger.Error("Could not queue webhook deliveries", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+const deliveryColumns = `
+d.id, d.webhook_id, d.event_id::text, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+d.last_status_code, d.last_error, d.created_at, d.delivered_at
+`
+
+func scanDelivery(row pgx.Row, extra ...any) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	dest := []any{&delivery.Id, &delivery.WebhookId, &delivery.EventId, &delivery.EventType, &delivery.Payload,
+		&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError,
+		&delivery.CreatedAt, &delivery.DeliveredAt}
+	err := row.Scan(append(dest, extra...)...)
+	return delivery, err
+}
+
+// ClaimDue picks up to limit pending deliveries that are due and hides them
+// from other workers for the lease duration. Rows locked by another worker
+// are skipped, so several instances can share the queue.
+func (r *WebhooksRepository) ClaimDue(c context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, `
+update webhook_deliveries d
+set next_attempt_at = now() + $3::float8 * interval '1 second'
+from webhooks w
+where w.id = d.webhook_id
+  and d.id in (
+      select due.id
+      from webhook_deliveries due
+      join webhooks dw on dw.id = due.webhook_id and dw.is_active
+      where due.status = $1 and due.next_attempt_at <= now()
+      order by due.next_attempt_at
+      limit $2
+      for update of due skip locked
+  )
+returning `+deliveryColumns+`, w.url, w.secret
+	`, models.DeliveryStatusPending, limit, lease.Seconds())
+	if err != nil {
+		logger.Error("Could not claim webhook deliveries", zap.String("db_msg", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		var url, secret string
+		delivery, err := scanDelivery(rows, &url, &secret)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		delivery.Url = url
+		delivery.Secret = secret
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RecordAttempt stores the outcome of a delivery attempt. A failed attempt
+// is retried at nextAttemptAt or, when it is nil, moved to the dead letters.
+func (r *WebhooksRepository) RecordAttempt(c context.Context, id int64, statusCode *int, attemptErr string, nextAttemptAt *time.Time) error {
+	logger := logger.GetLogger()
+	status := models.DeliveryStatusDelivered
+	switch {
+	case attemptErr != "" && nextAttemptAt != nil:
+		status = models.DeliveryStatusPending
+	case attemptErr != "":
+		status = models.DeliveryStatusDead
+	}
+
+	_, err := r.db.Exec(c, `
+update webhook_deliveries
+set status = @status,
+    attempts = attempts + 1,
+    last_status_code = @statusCode,
+    last_error = @error,
+    next_attempt_at = coalesce(@nextAttemptAt, next_attempt_at),
+    delivered_at = case when @delivered::boolean then now() end
+where id = @id
+	`, pgx.NamedArgs{
+		"id":            id,
+		"status":        status,
+		"statusCode":    statusCode,
+		"error":         attemptErr,
+		"nextAttemptAt": nextAttemptAt,
+		"delivered":     status == models.DeliveryStatusDelivered,
+	})
+	if err != nil {
+		logger.Error("Could not record webhook delivery attempt", zap.String("db_msg", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// FindDeliveries returns a page of deliveries, newest first, optionally
+// narrowed down to a webhook and a status.
+func (r *WebhooksRepository) FindDeliveries(c context.Context, webhookId int, status string, limit int, offset int) ([]models.WebhookDelivery, int, error) {
+	logger := logger.GetLogger()
+	rows, err := r.db.Query(c, `
+select `+deliveryColumns+`, count(*) over ()
+from webhook_deliveries d
+where ($1::int = 0 or d.webhook_id = $1) and ($2::text = '' or d.status = $2)
+order by d.created_at desc, d.id desc
+limit $3 offset $4
+	`, webhookId, status, limit, offset)
+	if err != nil {
+		logger.Error("Could not find webhook deliveries", zap.String("db_msg", err.Error()))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	total := 0
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanDelivery(rows, &total)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// Redeliver puts the delivery back in the queue with a fresh set of
+// attempts. It reports false when there is no such delivery.
+func (r *WebhooksRepository) Redeliver(c context.Context, id int64) (bool, error) {
+	logger := logger.GetLogger()
+	tag, err := r.db.Exec(c,
+		"update webhook_deliveries set status = $1, attempts = 0, next_attempt_at = now(), delivered_at = null where id = $2",
+		models.DeliveryStatusPending, id)
+	if err != nil {
+		logger.Error("Could not redeliver webhook delivery", zap.String("db_msg", err.Error()))
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,36 @@
+package schedule
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"time"
+)
+
+// Every runs task right away and then every interval until ctx is
+// cancelled. Failures are logged and retried on the next tick.
+//
+// Every runs in each instance of the application, which suits polling loops
+// and in-memory housekeeping; work that should happen once per period
+// across instances belongs in a recurring job (see package jobs).
+func Every(ctx context.Context, name string, interval time.Duration, task func(ctx context.Context) error) {
+	logger := logger.GetLogger()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		started := time.Now()
+		err := task(ctx)
+		if err != nil {
+			logger.Error("Scheduled task failed", zap.String("task", name), zap.Error(err))
+		} else {
+			logger.Debug("Scheduled task finished", zap.String("task", name), zap.Duration("took", time.Since(started)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
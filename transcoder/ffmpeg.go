@@ -0,0 +1,130 @@
+package transcoder
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"goozinshe/logger"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const segmentDuration = 6
+
+// FFmpegTranscoder runs a local ffmpeg binary once per rendition. Renditions
+// taller than the source, as reported by ffprobe, are skipped.
+type FFmpegTranscoder struct {
+	path       string
+	probePath  string
+	renditions []Rendition
+}
+
+func NewFFmpegTranscoder(path string, probePath string, renditions []Rendition) *FFmpegTranscoder {
+	if path == "" {
+		path = "ffmpeg"
+	}
+	if probePath == "" {
+		probePath = "ffprobe"
+	}
+	return &FFmpegTranscoder{path: path, probePath: probePath, renditions: renditions}
+}
+
+func (t *FFmpegTranscoder) Transcode(ctx context.Context, source string, outputDir string) error {
+	logger := logger.GetLogger()
+	sourceHeight, err := t.probeHeight(ctx, source)
+	if err != nil {
+		return err
+	}
+	renditions := renditionsFor(t.renditions, sourceHeight)
+
+	for _, rendition := range renditions {
+		renditionDir := filepath.Join(outputDir, rendition.Name)
+		err = os.MkdirAll(renditionDir, 0755)
+		if err != nil {
+			return err
+		}
+
+		cmd := exec.CommandContext(ctx, t.path,
+			"-y",
+			"-i", source,
+			"-vf", fmt.Sprintf("scale=-2:%d", rendition.Height),
+			"-c:v", "libx264",
+			"-b:v", fmt.Sprintf("%dk", rendition.VideoBitrate),
+			"-maxrate", fmt.Sprintf("%dk", rendition.VideoBitrate*107/100),
+			"-bufsize", fmt.Sprintf("%dk", rendition.VideoBitrate*3/2),
+			"-c:a", "aac",
+			"-b:a", fmt.Sprintf("%dk", rendition.AudioBitrate),
+			"-f", "hls",
+			"-hls_time", strconv.Itoa(segmentDuration),
+			"-hls_playlist_type", "vod",
+			"-hls_segment_filename", filepath.Join(renditionDir, "segment_%04d.ts"),
+			filepath.Join(renditionDir, "index.m3u8"),
+		)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			logger.Error("ffmpeg failed", zap.String("rendition", rendition.Name), zap.ByteString("output", tail(output, 2048)))
+			return fmt.Errorf("transcode %s: %w", rendition.Name, err)
+		}
+	}
+
+	return os.WriteFile(filepath.Join(outputDir, "master.m3u8"), []byte(masterPlaylist(renditions)), 0644)
+}
+
+// probeHeight returns the height of the first video stream of the source.
+func (t *FFmpegTranscoder) probeHeight(ctx context.Context, source string) (int, error) {
+	output, err := exec.CommandContext(ctx, t.probePath,
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=height",
+		"-of", "csv=p=0",
+		source,
+	).Output()
+	if err != nil {
+		return 0, fmt.Errorf("probe source: %w", err)
+	}
+	height, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil || height <= 0 {
+		return 0, fmt.Errorf("probe source: no video stream")
+	}
+	return height, nil
+}
+
+// renditionsFor leaves out the renditions taller than the source, so
+// nothing is upscaled. A source shorter than all of them still gets the
+// smallest rendition.
+func renditionsFor(renditions []Rendition, sourceHeight int) []Rendition {
+	var result []Rendition
+	var smallest *Rendition
+	for i, rendition := range renditions {
+		if rendition.Height <= sourceHeight {
+			result = append(result, rendition)
+		}
+		if smallest == nil || rendition.Height < smallest.Height {
+			smallest = &renditions[i]
+		}
+	}
+	if len(result) == 0 && smallest != nil {
+		result = append(result, *smallest)
+	}
+	return result
+}
+
+func masterPlaylist(renditions []Rendition) string {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	for _, rendition := range renditions {
+		bandwidth := (rendition.VideoBitrate + rendition.AudioBitrate) * 1000
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d,NAME=\"%s\"\n%s/index.m3u8\n", bandwidth, rendition.Name, rendition.Name)
+	}
+	return b.String()
+}
+
+func tail(b []byte, n int) []byte {
+	if len(b) > n {
+		return b[len(b)-n:]
+	}
+	return b
+}
@@ -0,0 +1,29 @@
+package transcoder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenditionsFor(t *testing.T) {
+	tests := []struct {
+		sourceHeight int
+		want         []string
+	}{
+		{2160, []string{"360p", "720p", "1080p"}},
+		{1080, []string{"360p", "720p", "1080p"}},
+		{1079, []string{"360p", "720p"}},
+		{720, []string{"360p", "720p"}},
+		{480, []string{"360p"}},
+		{240, []string{"360p"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, rendition := range renditionsFor(DefaultRenditions, tt.sourceHeight) {
+			got = append(got, rendition.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("renditionsFor(%d) = %v, want %v", tt.sourceHeight, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package transcoder
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"goozinshe/config"
+	"goozinshe/jobs"
+	"goozinshe/logger"
+	"goozinshe/repositories"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	VideosQueue    = "videos"
+	transcodeLease = 2 * time.Hour
+	// ProcessingTimeout is how long an upload may stay in processing before
+	// it is considered failed. It is longer than all attempts of
+	// TranscodeJob together, so it only expires when the job is lost.
+	ProcessingTimeout = 8 * time.Hour
+)
+
+type TranscodePayload struct {
+	MovieId int
+	Version int
+	Source  string
+}
+
+// TranscodeJob packages an uploaded video into HLS renditions.
+var TranscodeJob = jobs.Type[TranscodePayload]{Name: "videos.transcode", Queue: VideosQueue, MaxAttempts: 3, Lease: transcodeLease}
+
+// Pipeline transcodes uploaded videos on the job runner. Every upload is
+// packaged into a directory of its own version and published once it is
+// complete; players keep streaming the previous version meanwhile.
+type Pipeline struct {
+	repo       *repositories.VideosRepository
+	transcoder Transcoder
+	runner     *jobs.Runner
+}
+
+func NewPipeline(repo *repositories.VideosRepository, transcoder Transcoder, runner *jobs.Runner) *Pipeline {
+	return &Pipeline{repo: repo, transcoder: transcoder, runner: runner}
+}
+
+// Register makes the runner transcode uploads.
+func (p *Pipeline) Register(runner *jobs.Runner) {
+	jobs.Handle(runner, TranscodeJob, p.transcode)
+}
+
+// Enqueue schedules the upload for transcoding.
+func (p *Pipeline) Enqueue(c context.Context, payload TranscodePayload) error {
+	key := fmt.Sprintf("%s:%d:%d", TranscodeJob.Name, payload.MovieId, payload.Version)
+	_, err := jobs.Enqueue(c, p.runner, TranscodeJob, payload, jobs.UniqueKey(key))
+	return err
+}
+
+func (p *Pipeline) transcode(ctx context.Context, payload TranscodePayload) error {
+	logger := logger.GetLogger()
+	outputDir := VersionDir(payload.MovieId, payload.Version)
+	tmpDir := outputDir + ".tmp"
+
+	err := os.RemoveAll(tmpDir)
+	if err == nil {
+		err = p.transcoder.Transcode(ctx, payload.Source, tmpDir)
+	}
+	if err == nil {
+		// Left over by an attempt that died after renaming.
+		err = os.RemoveAll(outputDir)
+	}
+	if err == nil {
+		err = os.Rename(tmpDir, outputDir)
+	}
+	if err != nil {
+		logger.Error("Could not transcode movie video", zap.Int("movie_id", payload.MovieId), zap.Error(err))
+		if jobs.LastAttempt(ctx) {
+			_ = p.repo.Fail(context.WithoutCancel(ctx), payload.MovieId, payload.Version, err.Error())
+		}
+		return err
+	}
+
+	previous, err := p.repo.FindByMovieId(ctx, payload.MovieId, ProcessingTimeout)
+	if err != nil {
+		return err
+	}
+	published, err := p.repo.Publish(ctx, payload.MovieId, payload.Version)
+	if err != nil {
+		return err
+	}
+	if !published {
+		logger.Info("Movie video has been replaced by a newer upload",
+			zap.Int("movie_id", payload.MovieId), zap.Int("version", payload.Version))
+		return os.RemoveAll(outputDir)
+	}
+
+	removeOldVersions(payload.MovieId, payload.Version, previous.PublishedVersion)
+	logger.Info("Movie video is ready", zap.Int("movie_id", payload.MovieId), zap.Int("version", payload.Version))
+	return nil
+}
+
+// removeOldVersions deletes the renditions older than the previously
+// published version, which players may still be streaming, and the sources
+// of the versions before the current one.
+func removeOldVersions(movieId int, current int, previous int) {
+	logger := logger.GetLogger()
+	movieDir := MovieDir(movieId)
+	entries, err := os.ReadDir(movieDir)
+	if err != nil {
+		logger.Warn("Could not list movie videos", zap.Int("movie_id", movieId), zap.Error(err))
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		remove := false
+		if version, ok := parseSourceName(name); ok {
+			remove = version < current
+		} else if version, ok := parseVersionDir(name); ok {
+			remove = version < previous
+		}
+		if !remove {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(movieDir, name)); err != nil {
+			logger.Warn("Could not remove old movie video", zap.String("path", name), zap.Error(err))
+		}
+	}
+}
+
+func MovieDir(movieId int) string {
+	return filepath.Join(config.Config.VideosDir, strconv.Itoa(movieId))
+}
+
+// VersionDir is where the renditions of the upload are published.
+func VersionDir(movieId int, version int) string {
+	return filepath.Join(MovieDir(movieId), VersionPath(version))
+}
+
+// VersionPath is the directory of the version relative to the movie's one,
+// and the prefix of its stream URLs.
+func VersionPath(version int) string {
+	return "v" + strconv.Itoa(version)
+}
+
+// SourcePath is where the uploaded file of the version is stored.
+func SourcePath(movieId int, version int, ext string) string {
+	return filepath.Join(MovieDir(movieId), sourceName(version)+ext)
+}
+
+func sourceName(version int) string {
+	return "source-v" + strconv.Itoa(version)
+}
+
+// ParseVersionPath is the reverse of VersionPath.
+func ParseVersionPath(path string) (int, bool) {
+	if !strings.HasPrefix(path, "v") {
+		return 0, false
+	}
+	version, err := strconv.Atoi(path[1:])
+	return version, err == nil && version > 0
+}
+
+// parseSourceName returns the version of a stored upload.
+func parseSourceName(name string) (int, bool) {
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	version, ok := strings.CutPrefix(name, "source-")
+	if !ok {
+		return 0, false
+	}
+	return ParseVersionPath(version)
+}
+
+func parseVersionDir(name string) (int, bool) {
+	return ParseVersionPath(strings.TrimSuffix(name, ".tmp"))
+}
@@ -0,0 +1,22 @@
+package transcoder
+
+import "context"
+
+// Transcoder packages a source video into HLS renditions inside outputDir.
+// outputDir must contain a master.m3u8 playlist once Transcode returns nil.
+type Transcoder interface {
+	Transcode(ctx context.Context, source string, outputDir string) error
+}
+
+type Rendition struct {
+	Name         string
+	Height       int
+	VideoBitrate int // kbit/s
+	AudioBitrate int // kbit/s
+}
+
+var DefaultRenditions = []Rendition{
+	{Name: "360p", Height: 360, VideoBitrate: 800, AudioBitrate: 96},
+	{Name: "720p", Height: 720, VideoBitrate: 2800, AudioBitrate: 128},
+	{Name: "1080p", Height: 1080, VideoBitrate: 5000, AudioBitrate: 192},
+}
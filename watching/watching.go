@@ -0,0 +1,92 @@
+package watching
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"goozinshe/events"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"time"
+)
+
+// Service marks movies watched for a profile. Every way of watching a movie,
+// be it the watched toggle, a finished playback or a logged rewatch, goes
+// through it, so that all of them update the history, the watchlist and the
+// followers' feeds alike.
+type Service struct {
+	moviesRepo  *repositories.MoviesRepository
+	historyRepo *repositories.HistoryRepository
+	socialRepo  *repositories.SocialRepository
+	broker      *events.Broker
+}
+
+func NewService(
+	moviesRepo *repositories.MoviesRepository,
+	historyRepo *repositories.HistoryRepository,
+	socialRepo *repositories.SocialRepository,
+	broker *events.Broker) *Service {
+	return &Service{
+		moviesRepo:  moviesRepo,
+		historyRepo: historyRepo,
+		socialRepo:  socialRepo,
+		broker:      broker,
+	}
+}
+
+// SetWatched marks the movie (un)watched for the profile.
+func (s *Service) SetWatched(c context.Context, profileId int, movieId int, isWatched bool) error {
+	err := s.moviesRepo.SetWatched(c, profileId, movieId, isWatched)
+	if err != nil {
+		return err
+	}
+
+	if !isWatched {
+		err = s.socialRepo.DeleteActivity(c, activity(profileId, movieId))
+		if err != nil {
+			logger := logger.GetLogger()
+			logger.Warn("Activity has not been retracted", zap.String("type", models.ActivityWatched), zap.Error(err))
+		}
+		return nil
+	}
+
+	s.watched(c, profileId, movieId)
+	return nil
+}
+
+// LogWatch adds a (re)watch at watchedAt to the profile's history and marks
+// the movie watched. It returns the id of the history entry.
+func (s *Service) LogWatch(c context.Context, profileId int, movieId int, watchedAt time.Time) (int, error) {
+	id, err := s.historyRepo.Add(c, profileId, movieId, watchedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	s.watched(c, profileId, movieId)
+	return id, nil
+}
+
+// watched shows the movie in the followers' feeds and tells the profile's
+// clients to reload the watchlist, which the movie may have left. Failures
+// are logged and do not fail the action.
+func (s *Service) watched(c context.Context, profileId int, movieId int) {
+	err := s.socialRepo.RecordActivity(c, activity(profileId, movieId))
+	if err != nil {
+		logger := logger.GetLogger()
+		logger.Warn("Activity has not been recorded", zap.String("type", models.ActivityWatched), zap.Error(err))
+	}
+
+	s.broker.Publish(events.Event{
+		Type:      events.WatchlistChanged,
+		Data:      map[string]any{"movieId": movieId},
+		ProfileId: profileId,
+	})
+}
+
+func activity(profileId int, movieId int) models.ActivityEvent {
+	return models.ActivityEvent{
+		ProfileId: profileId,
+		Type:      models.ActivityWatched,
+		MovieId:   movieId,
+	}
+}
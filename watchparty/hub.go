@@ -0,0 +1,93 @@
+package watchparty
+
+import (
+	"context"
+	"crypto/rand"
+	"goozinshe/schedule"
+	"sync"
+	"time"
+)
+
+const (
+	defaultIdleTimeout = 10 * time.Minute
+	cleanupInterval    = time.Minute
+	codeLength         = 8
+	// codeAlphabet leaves out characters that are easy to mistype when an
+	// invite code is read out loud: 0/O and 1/I/L.
+	codeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+)
+
+// Hub keeps the watch party rooms in memory. Rooms do not survive a restart.
+type Hub struct {
+	mu          sync.Mutex
+	rooms       map[string]*Room
+	idleTimeout time.Duration
+}
+
+func NewHub(idleTimeout time.Duration) *Hub {
+	if idleTimeout <= 0 {
+		idleTimeout = defaultIdleTimeout
+	}
+	return &Hub{
+		rooms:       make(map[string]*Room),
+		idleTimeout: idleTimeout,
+	}
+}
+
+// Create opens a room for the movie under a new invite code.
+func (h *Hub) Create(movieId int, hostProfileId int) (*Room, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for {
+		code, err := newInviteCode()
+		if err != nil {
+			return nil, err
+		}
+		if _, taken := h.rooms[code]; taken {
+			continue
+		}
+		room := newRoom(code, movieId, hostProfileId)
+		h.rooms[code] = room
+		return room, nil
+	}
+}
+
+func (h *Hub) Find(code string) (*Room, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	room, ok := h.rooms[code]
+	return room, ok
+}
+
+// RemoveIdle closes the rooms that have had nobody in them for longer than
+// the idle timeout.
+func (h *Hub) RemoveIdle(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for code, room := range h.rooms {
+		if room.idleFor(now) > h.idleTimeout {
+			room.close()
+			delete(h.rooms, code)
+		}
+	}
+	return nil
+}
+
+// Run removes idle rooms every minute until ctx is cancelled.
+func (h *Hub) Run(ctx context.Context) {
+	schedule.Every(ctx, "watch parties", cleanupInterval, h.RemoveIdle)
+}
+
+func newInviteCode() (string, error) {
+	b := make([]byte, codeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = codeAlphabet[int(b[i])%len(codeAlphabet)]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,374 @@
+package watchparty
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	maxParticipants = 20
+	maxChatLength   = 500
+	maxMessageSize  = 4096
+	sendBuffer      = 32
+	writeTimeout    = 10 * time.Second
+	pongTimeout     = 60 * time.Second
+	pingInterval    = 30 * time.Second
+)
+
+var (
+	ErrRoomFull   = errors.New("watch party is full")
+	ErrRoomClosed = errors.New("watch party is over")
+)
+
+// Message types sent by clients.
+const (
+	MessagePlay  = "play"
+	MessagePause = "pause"
+	MessageSeek  = "seek"
+	MessageChat  = "chat"
+	MessagePing  = "ping"
+)
+
+// Message types sent by the server.
+const (
+	MessageWelcome  = "welcome"
+	MessagePlayback = "playback"
+	MessagePresence = "presence"
+	MessagePong     = "pong"
+	MessageError    = "error"
+)
+
+type Participant struct {
+	ProfileId int
+	Name      string
+}
+
+// Playback is the shared player state. Position is the playback position in
+// seconds at UpdatedAt; while playing it keeps advancing with the server
+// clock.
+type Playback struct {
+	Playing   bool
+	Position  float64
+	UpdatedAt time.Time
+}
+
+func (p Playback) positionAt(t time.Time) float64 {
+	if !p.Playing {
+		return p.Position
+	}
+	return p.Position + t.Sub(p.UpdatedAt).Seconds()
+}
+
+// PlaybackState is Playback as of the server time it is sent with.
+type PlaybackState struct {
+	Playing  bool
+	Position float64
+}
+
+type RoomInfo struct {
+	Code          string
+	MovieId       int
+	HostProfileId int
+	CreatedAt     time.Time
+	Playback      PlaybackState
+	Participants  []Participant
+	// ServerTime is the server clock in Unix milliseconds when the info was
+	// taken; clients use it to extrapolate the playback position.
+	ServerTime int64
+}
+
+type clientMessage struct {
+	Type       string   `json:"type"`
+	Position   *float64 `json:"position"`
+	Text       string   `json:"text"`
+	ClientTime int64    `json:"clientTime"`
+}
+
+// serverMessage uses the same PascalCase field names as the REST responses.
+type serverMessage struct {
+	Type         string
+	ServerTime   int64
+	ClientTime   int64          `json:",omitempty"`
+	Room         *RoomInfo      `json:",omitempty"`
+	Playback     *PlaybackState `json:",omitempty"`
+	From         *Participant   `json:",omitempty"`
+	Text         string         `json:",omitempty"`
+	Participants []Participant  `json:",omitempty"`
+}
+
+type member struct {
+	participant Participant
+	conn        *websocket.Conn
+	send        chan []byte
+}
+
+// Room is a watch party for one movie. Every participant may play, pause and
+// seek; the room keeps the authoritative playback state and broadcasts each
+// change together with the server time.
+type Room struct {
+	Code          string
+	MovieId       int
+	HostProfileId int
+	CreatedAt     time.Time
+
+	mu        sync.Mutex
+	members   map[*member]struct{}
+	playback  Playback
+	idleSince time.Time
+	closed    bool
+}
+
+func newRoom(code string, movieId int, hostProfileId int) *Room {
+	now := time.Now()
+	return &Room{
+		Code:          code,
+		MovieId:       movieId,
+		HostProfileId: hostProfileId,
+		CreatedAt:     now,
+		members:       make(map[*member]struct{}),
+		playback:      Playback{UpdatedAt: now},
+		idleSince:     now,
+	}
+}
+
+func (r *Room) Info() RoomInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.info(time.Now())
+}
+
+func (r *Room) info(now time.Time) RoomInfo {
+	return RoomInfo{
+		Code:          r.Code,
+		MovieId:       r.MovieId,
+		HostProfileId: r.HostProfileId,
+		CreatedAt:     r.CreatedAt,
+		Playback:      r.playbackState(now),
+		Participants:  r.participants(),
+		ServerTime:    now.UnixMilli(),
+	}
+}
+
+func (r *Room) playbackState(now time.Time) PlaybackState {
+	return PlaybackState{Playing: r.playback.Playing, Position: r.playback.positionAt(now)}
+}
+
+// participants lists everyone in the room once, even when they are
+// connected from several devices.
+func (r *Room) participants() []Participant {
+	seen := make(map[int]bool)
+	participants := make([]Participant, 0, len(r.members))
+	for m := range r.members {
+		if seen[m.participant.ProfileId] {
+			continue
+		}
+		seen[m.participant.ProfileId] = true
+		participants = append(participants, m.participant)
+	}
+	return participants
+}
+
+// Serve runs the participant's connection until it is closed. It returns
+// ErrRoomFull or ErrRoomClosed right away when the participant can not join;
+// the connection is closed either way.
+func (r *Room) Serve(conn *websocket.Conn, participant Participant) error {
+	m := &member{participant: participant, conn: conn, send: make(chan []byte, sendBuffer)}
+	if err := r.join(m); err != nil {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()),
+			time.Now().Add(writeTimeout))
+		conn.Close()
+		return err
+	}
+
+	go m.writePump()
+	r.readPump(m)
+	r.leave(m)
+	return nil
+}
+
+func (r *Room) join(m *member) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrRoomClosed
+	}
+	if len(r.members) >= maxParticipants {
+		return ErrRoomFull
+	}
+	r.members[m] = struct{}{}
+
+	now := time.Now()
+	info := r.info(now)
+	r.sendTo(m, serverMessage{Type: MessageWelcome, ServerTime: now.UnixMilli(), Room: &info})
+	r.broadcast(serverMessage{Type: MessagePresence, ServerTime: now.UnixMilli(), Participants: info.Participants})
+	return nil
+}
+
+func (r *Room) leave(m *member) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.members[m]; !ok {
+		return
+	}
+	r.drop(m)
+	r.broadcast(serverMessage{Type: MessagePresence, ServerTime: time.Now().UnixMilli(), Participants: r.participants()})
+}
+
+// drop removes the member and makes its write pump close the connection.
+// The caller holds r.mu.
+func (r *Room) drop(m *member) {
+	delete(r.members, m)
+	close(m.send)
+	if len(r.members) == 0 {
+		r.idleSince = time.Now()
+	}
+}
+
+// idleFor reports how long the room has had no participants.
+func (r *Room) idleFor(now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.members) > 0 {
+		return 0
+	}
+	return now.Sub(r.idleSince)
+}
+
+func (r *Room) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	for m := range r.members {
+		r.drop(m)
+	}
+}
+
+func (r *Room) readPump(m *member) {
+	m.conn.SetReadLimit(maxMessageSize)
+	m.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	m.conn.SetPongHandler(func(string) error {
+		return m.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	})
+
+	for {
+		_, data, err := m.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var message clientMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			r.reply(m, serverMessage{Type: MessageError, Text: "Invalid message"})
+			continue
+		}
+		r.handle(m, message)
+	}
+}
+
+func (r *Room) handle(m *member, message clientMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	switch message.Type {
+	case MessagePlay, MessagePause, MessageSeek:
+		position := r.playback.positionAt(now)
+		if message.Position != nil {
+			position = *message.Position
+		} else if message.Type == MessageSeek {
+			r.sendTo(m, serverMessage{Type: MessageError, ServerTime: now.UnixMilli(), Text: "Seek requires a position"})
+			return
+		}
+		if position < 0 {
+			position = 0
+		}
+		playing := r.playback.Playing
+		switch message.Type {
+		case MessagePlay:
+			playing = true
+		case MessagePause:
+			playing = false
+		}
+		r.playback = Playback{Playing: playing, Position: position, UpdatedAt: now}
+
+		state := r.playbackState(now)
+		r.broadcast(serverMessage{Type: MessagePlayback, ServerTime: now.UnixMilli(), Playback: &state, From: &m.participant})
+	case MessageChat:
+		text := strings.TrimSpace(message.Text)
+		if text == "" || utf8.RuneCountInString(text) > maxChatLength {
+			r.sendTo(m, serverMessage{Type: MessageError, ServerTime: now.UnixMilli(), Text: "Invalid chat message"})
+			return
+		}
+		r.broadcast(serverMessage{Type: MessageChat, ServerTime: now.UnixMilli(), From: &m.participant, Text: text})
+	case MessagePing:
+		r.sendTo(m, serverMessage{Type: MessagePong, ServerTime: now.UnixMilli(), ClientTime: message.ClientTime})
+	default:
+		r.sendTo(m, serverMessage{Type: MessageError, ServerTime: now.UnixMilli(), Text: "Unknown message type"})
+	}
+}
+
+func (r *Room) reply(m *member, message serverMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	message.ServerTime = time.Now().UnixMilli()
+	r.sendTo(m, message)
+}
+
+// broadcast sends the message to every member. The caller holds r.mu.
+func (r *Room) broadcast(message serverMessage) {
+	for m := range r.members {
+		r.sendTo(m, message)
+	}
+}
+
+// sendTo queues the message without blocking; a member that can not keep up
+// is disconnected. The caller holds r.mu.
+func (r *Room) sendTo(m *member, message serverMessage) {
+	if _, ok := r.members[m]; !ok {
+		return
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	select {
+	case m.send <- data:
+	default:
+		r.drop(m)
+	}
+}
+
+func (m *member) writePump() {
+	ticker := time.NewTicker(pingInterval)
+	defer func() {
+		ticker.Stop()
+		m.conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-m.send:
+			m.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if !ok {
+				m.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			if err := m.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			m.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := m.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
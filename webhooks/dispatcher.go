@@ -0,0 +1,222 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"goozinshe/config"
+	"goozinshe/events"
+	"goozinshe/logger"
+	"goozinshe/models"
+	"goozinshe/repositories"
+	"goozinshe/schedule"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxAttempts  = 8
+	defaultPollInterval = 5 * time.Second
+	defaultTimeout      = 10 * time.Second
+	batchSize           = 50
+	firstRetryDelay     = 30 * time.Second
+	maxRetryDelay       = 6 * time.Hour
+	maxErrorLength      = 500
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Ozinshe-Event"
+	HeaderDelivery  = "X-Ozinshe-Delivery"
+	HeaderTimestamp = "X-Ozinshe-Timestamp"
+	HeaderSignature = "X-Ozinshe-Signature"
+)
+
+// EventTypes are the events webhooks can subscribe to.
+var EventTypes = []string{
+	events.MovieCreated,
+	events.MovieUpdated,
+	events.MovieDeleted,
+	events.GenreCreated,
+	events.GenreUpdated,
+	events.GenreDeleted,
+}
+
+func IsEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Envelope is the body POSTed to subscribers.
+type Envelope struct {
+	Id         string
+	Type       string
+	OccurredAt time.Time
+	Data       any
+}
+
+// Dispatcher queues events for the subscribed webhooks and delivers them in
+// the background. Deliveries live in the database, so they survive restarts
+// and failed ones are retried with exponential backoff.
+type Dispatcher struct {
+	repo   *repositories.WebhooksRepository
+	client *http.Client
+}
+
+func NewDispatcher(repo *repositories.WebhooksRepository) *Dispatcher {
+	timeout := config.Config.WebhookTimeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Dispatcher{repo: repo, client: &http.Client{Timeout: timeout}}
+}
+
+// Enqueue queues a delivery of the event for every active webhook that
+// subscribes to it. Enqueueing the same event again is a no-op, so it is
+// safe to call from an at-least-once publisher.
+func (d *Dispatcher) Enqueue(c context.Context, envelope Envelope) error {
+	if !IsEventType(envelope.Type) {
+		return nil
+	}
+	webhooks, err := d.repo.FindActive(c)
+	if err != nil {
+		return err
+	}
+	webhookIds := make([]int, 0)
+	for _, webhook := range webhooks {
+		if webhook.Subscribes(envelope.Type) {
+			webhookIds = append(webhookIds, webhook.Id)
+		}
+	}
+	if len(webhookIds) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	return d.repo.CreateDeliveries(c, webhookIds, envelope.Id, envelope.Type, payload)
+}
+
+// DeliverDue sends the deliveries that are due, in parallel.
+func (d *Dispatcher) DeliverDue(ctx context.Context) error {
+	deliveries, err := d.repo.ClaimDue(ctx, batchSize, d.client.Timeout*2)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery models.WebhookDelivery) {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery models.WebhookDelivery) {
+	logger := logger.GetLogger()
+
+	statusCode, err := d.send(ctx, delivery)
+	if err == nil {
+		d.repo.RecordAttempt(ctx, delivery.Id, statusCode, "", nil)
+		return
+	}
+
+	attemptErr := err.Error()
+	if len(attemptErr) > maxErrorLength {
+		attemptErr = attemptErr[:maxErrorLength]
+	}
+	maxAttempts := config.Config.WebhookMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	var nextAttemptAt *time.Time
+	if delivery.Attempts+1 < maxAttempts {
+		next := time.Now().Add(retryDelay(delivery.Attempts))
+		nextAttemptAt = &next
+	} else {
+		logger.Warn("Webhook delivery moved to dead letters",
+			zap.Int64("delivery_id", delivery.Id), zap.Int("webhook_id", delivery.WebhookId), zap.String("error", attemptErr))
+	}
+	d.repo.RecordAttempt(ctx, delivery.Id, statusCode, attemptErr, nextAttemptAt)
+}
+
+// send POSTs the payload and returns the response status code, if any. Any
+// status outside 2xx counts as a failure.
+func (d *Dispatcher) send(ctx context.Context, delivery models.WebhookDelivery) (*int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "Ozinshe-Webhooks/1.0")
+	request.Header.Set(HeaderEvent, delivery.EventType)
+	request.Header.Set(HeaderDelivery, delivery.EventId)
+	request.Header.Set(HeaderTimestamp, timestamp)
+	request.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	statusCode := response.StatusCode
+	if statusCode < 200 || statusCode > 299 {
+		return &statusCode, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return &statusCode, nil
+}
+
+// Sign returns the X-Ozinshe-Signature value: the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the webhook secret. Including the timestamp
+// lets subscribers reject replayed requests.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryDelay doubles the delay with every failed attempt: 30s, 1m, 2m, ...
+// up to 6h.
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 0; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// Run delivers due webhooks every WEBHOOK_POLL_INTERVAL until ctx is
+// cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	interval := config.Config.WebhookPollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	schedule.Every(ctx, "webhooks", interval, d.DeliverDue)
+}
@@ -0,0 +1,78 @@
+package webhooks
+
+import (
+	"context"
+	"goozinshe/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"movie.created","data":{"id":7}}`)
+	want := "sha256=73b1c55f1e520761c3dab702ca66eb267d985306bcdcb8bb60b4151d76ff1fca"
+	if got := Sign("whsec_test", "1700000000", body); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+	if Sign("other", "1700000000", body) == want {
+		t.Error("Sign() does not depend on the secret")
+	}
+	if Sign("whsec_test", "1700000001", body) == want {
+		t.Error("Sign() does not depend on the timestamp")
+	}
+}
+
+func TestSendSignsRequest(t *testing.T) {
+	payload := []byte(`{"id":1}`)
+	var header http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	d := &Dispatcher{client: server.Client()}
+	statusCode, err := d.send(context.Background(), models.WebhookDelivery{
+		EventId:   "e1",
+		EventType: "movie.created",
+		Payload:   payload,
+		Url:       server.URL,
+		Secret:    "whsec_test",
+	})
+	if err != nil || statusCode == nil || *statusCode != http.StatusOK {
+		t.Fatalf("send() = %v, %v", statusCode, err)
+	}
+	if string(body) != string(payload) {
+		t.Errorf("body = %s, want %s", body, payload)
+	}
+	want := Sign("whsec_test", header.Get(HeaderTimestamp), payload)
+	if got := header.Get(HeaderSignature); got != want {
+		t.Errorf("%s = %s, want %s", HeaderSignature, got, want)
+	}
+	if header.Get(HeaderEvent) != "movie.created" || header.Get(HeaderDelivery) != "e1" {
+		t.Errorf("event headers = %s, %s", header.Get(HeaderEvent), header.Get(HeaderDelivery))
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{5, 16 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}